@@ -6,8 +6,15 @@ import (
 
 // ArraySchema -
 type ArraySchema struct {
-	Type  Type   `json:"type"`
-	Items Schema `json:"items"`
+	Type       Type       `json:"type"`
+	Items      Schema     `json:"items"`
+	Properties Properties `json:"-"`
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the array
+func (t *ArraySchema) MarshalJSON() ([]byte, error) {
+	type arraySchema ArraySchema
+	return marshalWithProperties((*arraySchema)(t), t.Properties, arrayAttributes)
 }
 
 // TypeName -
@@ -15,17 +22,18 @@ func (t *ArraySchema) TypeName() Type {
 	return TypeArray
 }
 
-func translateValue2ArraySchema(value *fastjson.Value, additionalTypes ...Type) (Schema, error) {
+func (p *schemaParser) translateValue2ArraySchema(value *fastjson.Value, path, namespace string) (Schema, error) {
 	if !value.Exists("items") {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(path, nil, ErrInvalidSchema, "missing items")
 	}
 	itemsVal := value.Get("items")
-	itemSchema, err := translateValue2AnySchema(itemsVal, additionalTypes...)
+	itemSchema, err := p.translateValue2AnySchema(itemsVal, joinPath(path, "items"), namespace)
 	if err != nil {
 		return nil, err
 	}
 	return &ArraySchema{
-		Type:  TypeArray,
-		Items: itemSchema,
+		Type:       TypeArray,
+		Items:      itemSchema,
+		Properties: translateValueToProperties(value, arrayAttributes),
 	}, nil
 }
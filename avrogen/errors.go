@@ -0,0 +1,8 @@
+package avrogen
+
+import "errors"
+
+var (
+	// ErrUnsupportedSchema - the schema cannot be represented by a Go type
+	ErrUnsupportedSchema = errors.New("ErrUnsupportedSchema")
+)
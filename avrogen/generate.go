@@ -0,0 +1,323 @@
+package avrogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/khezen/avro"
+)
+
+// Generate - renders Go source code declaring types for the given schemas:
+// structs for records, typed string constants for enums, byte arrays for fixed
+// and tagged wrapper structs for unions which are not simply nullable.
+// For each top level named schema, its JSON is embedded along with a constructor.
+func Generate(packageName string, schemas ...avro.Schema) ([]byte, error) {
+	g := generator{
+		names:   make(map[avro.Schema]string),
+		taken:   make(map[string]struct{}),
+		imports: make(map[string]struct{}),
+		decls:   new(bytes.Buffer),
+	}
+	for _, schema := range schemas {
+		_, err := g.goType(schema, "", "")
+		if err != nil {
+			return nil, err
+		}
+		err = g.writeSchemaConstructor(schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString("// Code generated by avrogen. DO NOT EDIT.\n\n")
+	buf.WriteString("package ")
+	buf.WriteString(packageName)
+	buf.WriteString("\n\n")
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		iStd, jStd := !strings.Contains(imports[i], "."), !strings.Contains(imports[j], ".")
+		if iStd != jStd {
+			return iStd
+		}
+		return imports[i] < imports[j]
+	})
+	if len(imports) > 0 {
+		buf.WriteString("import (\n")
+		for i, path := range imports {
+			// standard library first
+			if i > 0 && strings.Contains(path, ".") && !strings.Contains(imports[i-1], ".") {
+				buf.WriteRune('\n')
+			}
+			buf.WriteString(strconv.Quote(path))
+			buf.WriteRune('\n')
+		}
+		buf.WriteString(")\n")
+	}
+	buf.Write(g.decls.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+type generator struct {
+	// names - Go type names of the named schemas and unions already declared
+	names   map[avro.Schema]string
+	taken   map[string]struct{}
+	imports map[string]struct{}
+	decls   *bytes.Buffer
+}
+
+// goType returns the Go type representing the schema, declaring it if needed.
+// namespace is the enclosing namespace and context the name of the enclosing declaration,
+// used to name unions.
+func (g *generator) goType(schema avro.Schema, namespace, context string) (string, error) {
+	switch t := schema.(type) {
+	case avro.Type:
+		return primitiveGoType(t)
+	case *avro.DerivedPrimitiveSchema:
+		switch t.LogicalType {
+		case avro.LogicalTypeDate, avro.LogicalTypeTimestamp,
+			avro.LogicalTypeTimestampMillis, avro.LogicalTypeTimestampMicros,
+			avro.LogicalTypeLocalTimestampMillis, avro.LogicalTypeLocalTimestampMicros:
+			return "time.Time", nil
+		case avro.LogicalTypeTimeMillis, avro.LogicalTypeTimeMicros:
+			return "time.Duration", nil
+		case avro.LogicalTypeDecimal:
+			return "big.Rat", nil
+		default:
+			return primitiveGoType(t.Type)
+		}
+	case *avro.ReferenceSchema:
+		return g.goType(t.Schema, namespace, context)
+	case *avro.ArraySchema:
+		items, err := g.goType(t.Items, namespace, context+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	case *avro.MapSchema:
+		values, err := g.goType(t.Value, namespace, context+"Value")
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + values, nil
+	case avro.UnionSchema:
+		return g.union(t, namespace, context)
+	case *avro.FixedSchema:
+		if t.LogicalType == avro.LogicalTypeDecimal {
+			return "big.Rat", nil
+		}
+		if name, ok := g.names[t]; ok {
+			return name, nil
+		}
+		name := g.declare(t, t.Name)
+		fmt.Fprintf(g.decls, "\n// %s - fixed %s\n", name, t.FullName(namespace))
+		writeDoc(g.decls, t.Documentation)
+		fmt.Fprintf(g.decls, "type %s [%d]byte\n", name, t.Size)
+		return name, nil
+	case *avro.EnumSchema:
+		if name, ok := g.names[t]; ok {
+			return name, nil
+		}
+		name := g.declare(t, t.Name)
+		fmt.Fprintf(g.decls, "\n// %s - enum %s\n", name, t.FullName(namespace))
+		writeDoc(g.decls, t.Documentation)
+		fmt.Fprintf(g.decls, "type %s string\n\nconst (\n", name)
+		for _, symbol := range t.Symbols {
+			fmt.Fprintf(g.decls, "// %s%s -\n%s%s %s = %s\n", name, symbolIdentifier(symbol), name, symbolIdentifier(symbol), name, strconv.Quote(symbol))
+		}
+		g.decls.WriteString(")\n")
+		return name, nil
+	case *avro.RecordSchema:
+		if name, ok := g.names[t]; ok {
+			return name, nil
+		}
+		return g.record(t, namespace)
+	default:
+		return "", ErrUnsupportedSchema
+	}
+}
+
+func (g *generator) record(schema *avro.RecordSchema, namespace string) (string, error) {
+	name := g.declare(schema, schema.Name)
+	fullname := schema.FullName(namespace)
+	namespace = namespaceOf(fullname)
+	// fields are rendered first since they may declare types themselves
+	fields := new(bytes.Buffer)
+	for _, field := range schema.Fields {
+		fieldName := identifier(field.Name)
+		fieldType, err := g.goType(field.Type, namespace, name+fieldName)
+		if err != nil {
+			return "", err
+		}
+		g.use(fieldType)
+		writeDoc(fields, field.Documentation)
+		fmt.Fprintf(fields, "%s %s `avro:%s json:%s`\n", fieldName, fieldType, strconv.Quote(field.Name), strconv.Quote(field.Name))
+	}
+	fmt.Fprintf(g.decls, "\n// %s - record %s\n", name, fullname)
+	writeDoc(g.decls, schema.Documentation)
+	fmt.Fprintf(g.decls, "type %s struct {\n", name)
+	g.decls.Write(fields.Bytes())
+	g.decls.WriteString("}\n")
+	return name, nil
+}
+
+// union - nullable unions of a single type are rendered as pointers,
+// other unions as a struct holding the name of the selected branch and a field per branch.
+func (g *generator) union(union avro.UnionSchema, namespace, context string) (string, error) {
+	branches := make([]avro.Schema, 0, len(union))
+	for _, branch := range union {
+		if branch.TypeName() != avro.TypeNull {
+			branches = append(branches, branch)
+		}
+	}
+	if len(branches) == 1 && len(union) == 2 {
+		goType, err := g.goType(branches[0], namespace, context)
+		if err != nil {
+			return "", err
+		}
+		return "*" + goType, nil
+	}
+	name := g.declare(&union, context+"Union")
+	fields := new(bytes.Buffer)
+	branchNames := make([]string, 0, len(union))
+	for _, branch := range union {
+		branchNames = append(branchNames, branchTypeName(branch, namespace))
+		if branch.TypeName() == avro.TypeNull {
+			continue
+		}
+		goType, err := g.goType(branch, namespace, context)
+		if err != nil {
+			return "", err
+		}
+		g.use(goType)
+		fmt.Fprintf(fields, "%s %s\n", identifier(branchTypeName(branch, namespace)), goType)
+	}
+	fmt.Fprintf(g.decls, "\n// %s - union of %s\n", name, strings.Join(branchNames, ", "))
+	fmt.Fprintf(g.decls, "type %s struct {\n", name)
+	g.decls.WriteString("// Type - name of the branch holding the value\n")
+	g.decls.WriteString("Type string\n")
+	g.decls.Write(fields.Bytes())
+	g.decls.WriteString("}\n")
+	return name, nil
+}
+
+// writeSchemaConstructor embeds the JSON of top level named schemas along with a constructor
+func (g *generator) writeSchemaConstructor(schema avro.Schema) error {
+	var goSchemaType string
+	switch schema.(type) {
+	case *avro.RecordSchema:
+		goSchemaType = "*avro.RecordSchema"
+	case *avro.EnumSchema:
+		goSchemaType = "*avro.EnumSchema"
+	case *avro.FixedSchema:
+		goSchemaType = "*avro.FixedSchema"
+	default:
+		return nil
+	}
+	schemaBytes, err := avro.MarshalSchema(schema)
+	if err != nil {
+		return err
+	}
+	name := g.names[schema]
+	fmt.Fprintf(g.decls, "\n// %sSchemaJSON - AVRO schema of %s\n", name, name)
+	fmt.Fprintf(g.decls, "const %sSchemaJSON = %s\n", name, quote(string(schemaBytes)))
+	fmt.Fprintf(g.decls, "\n// New%sSchema - unmarshals %sSchemaJSON\n", name, name)
+	fmt.Fprintf(g.decls, "func New%sSchema() (%s, error) {\n", name, goSchemaType)
+	g.decls.WriteString("var anySchema avro.AnySchema\n")
+	fmt.Fprintf(g.decls, "err := json.Unmarshal([]byte(%sSchemaJSON), &anySchema)\n", name)
+	g.decls.WriteString("if err != nil {\nreturn nil, err\n}\n")
+	fmt.Fprintf(g.decls, "return anySchema.Schema().(%s), nil\n}\n", goSchemaType)
+	g.imports["encoding/json"] = struct{}{}
+	g.imports["github.com/khezen/avro"] = struct{}{}
+	return nil
+}
+
+// qualifiers - import paths of the packages qualifying the Go types of logical types
+var qualifiers = map[string]string{
+	"time": "time",
+	"big":  "math/big",
+}
+
+// use imports the package qualifying the Go type written in a declaration, if any.
+// Types of schemas which aren't declared, such as top level arrays, import nothing.
+func (g *generator) use(goType string) {
+	// the element type follows the pointer, slice and map prefixes
+	elem := goType[strings.LastIndexAny(goType, "*]")+1:]
+	if i := strings.IndexByte(elem, '.'); i >= 0 {
+		g.imports[qualifiers[elem[:i]]] = struct{}{}
+	}
+}
+
+// declare reserves a unique Go type name for the given schema
+func (g *generator) declare(schema avro.Schema, name string) string {
+	name = identifier(name[strings.LastIndex(name, ".")+1:])
+	unique := name
+	for i := 2; ; i++ {
+		if _, ok := g.taken[unique]; !ok {
+			break
+		}
+		unique = name + strconv.Itoa(i)
+	}
+	g.taken[unique] = struct{}{}
+	g.names[schema] = unique
+	return unique
+}
+
+func primitiveGoType(t avro.Type) (string, error) {
+	switch t {
+	case avro.TypeBoolean:
+		return "bool", nil
+	case avro.TypeInt32:
+		return "int32", nil
+	case avro.TypeInt64:
+		return "int64", nil
+	case avro.TypeFloat32:
+		return "float32", nil
+	case avro.TypeFloat64:
+		return "float64", nil
+	case avro.TypeBytes:
+		return "[]byte", nil
+	case avro.TypeString:
+		return "string", nil
+	default:
+		return "", ErrUnsupportedSchema
+	}
+}
+
+// branchTypeName returns the name identifying the branch of a union:
+// the fullname of named types, the type or logical type name otherwise
+func branchTypeName(schema avro.Schema, namespace string) string {
+	switch t := schema.(type) {
+	case *avro.ReferenceSchema:
+		return branchTypeName(t.Schema, namespace)
+	case avro.NamedSchema:
+		return t.FullName(namespace)
+	default:
+		return string(t.TypeName())
+	}
+}
+
+// quote renders a raw string literal when possible
+func quote(str string) string {
+	if strconv.CanBackquote(str) {
+		return "`" + str + "`"
+	}
+	return strconv.Quote(str)
+}
+
+func writeDoc(buf *bytes.Buffer, doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		buf.WriteString("// ")
+		buf.WriteString(line)
+		buf.WriteRune('\n')
+	}
+}
@@ -0,0 +1,114 @@
+package avrogen
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/khezen/avro"
+)
+
+func TestGenerate(t *testing.T) {
+	schemaBytes := []byte(`{"type":"record","namespace":"blog","name":"post","doc":"blog post","fields":[{"name":"ID","type":"long"},{"name":"title","doc":"title of the post","type":"string"},{"name":"body","type":["null","bytes"]},{"name":"status","type":{"type":"enum","name":"status","symbols":["DRAFT","PUBLISHED","NOT_SET"]}},{"name":"hash","type":{"type":"fixed","name":"md5","size":16}},{"name":"previous_hash","type":["null","md5"]},{"name":"content","type":["null","string",{"type":"long","logicalType":"timestamp"},"md5"]},{"name":"counters","type":{"type":"map","values":["long","double"]}},{"name":"price","type":{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}},{"name":"related","type":{"type":"array","items":"post"}}]}`)
+	var anySchema avro.AnySchema
+	err := json.Unmarshal(schemaBytes, &anySchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := Generate("blog", anySchema.Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "// Code generated by avrogen. DO NOT EDIT.\n\npackage blog\n\nimport (\n\t\"encoding/json\"\n\t\"math/big\"\n\t\"time\"\n\n\t\"github.com/khezen/avro\"\n)\n\n// Status - enum blog.status\ntype Status string\n\nconst (\n\t// StatusDraft -\n\tStatusDraft Status = \"DRAFT\"\n\t// StatusPublished -\n\tStatusPublished Status = \"PUBLISHED\"\n\t// StatusNotSet -\n\tStatusNotSet Status = \"NOT_SET\"\n)\n\n// Md5 - fixed blog.md5\ntype Md5 [16]byte\n\n// PostContentUnion - union of null, string, timestamp, blog.md5\ntype PostContentUnion struct {\n\t// Type - name of the branch holding the value\n\tType      string\n\tString    string\n\tTimestamp time.Time\n\tMd5       Md5\n}\n\n// PostCountersValueUnion - union of long, double\ntype PostCountersValueUnion struct {\n\t// Type - name of the branch holding the value\n\tType   string\n\tLong   int64\n\tDouble float64\n}\n\n// Post - record blog.post\n// blog post\ntype Post struct {\n\tID int64 `avro:\"ID\" json:\"ID\"`\n\t// title of the post\n\tTitle        string                            `avro:\"title\" json:\"title\"`\n\tBody         *[]byte                           `avro:\"body\" json:\"body\"`\n\tStatus       Status                            `avro:\"status\" json:\"status\"`\n\tHash         Md5                               `avro:\"hash\" json:\"hash\"`\n\tPreviousHash *Md5                              `avro:\"previous_hash\" json:\"previous_hash\"`\n\tContent      PostContentUnion                  `avro:\"content\" json:\"content\"`\n\tCounters     map[string]PostCountersValueUnion `avro:\"counters\" json:\"counters\"`\n\tPrice        big.Rat                           `avro:\"price\" json:\"price\"`\n\tRelated      []Post                            `avro:\"related\" json:\"related\"`\n}\n\n// PostSchemaJSON - AVRO schema of Post\nconst PostSchemaJSON = `" + string(schemaBytes) + "`\n\n// NewPostSchema - unmarshals PostSchemaJSON\nfunc NewPostSchema() (*avro.RecordSchema, error) {\n\tvar anySchema avro.AnySchema\n\terr := json.Unmarshal([]byte(PostSchemaJSON), &anySchema)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn anySchema.Schema().(*avro.RecordSchema), nil\n}\n"
+	if string(source) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s\n", expected, source)
+	}
+}
+
+func TestGenerateSharedNamedTypes(t *testing.T) {
+	hash := &avro.FixedSchema{Type: avro.TypeFixed, Name: "MD5", Size: 16}
+	schema := &avro.RecordSchema{
+		Type: avro.TypeRecord,
+		Name: "file",
+		Fields: []avro.RecordFieldSchema{
+			{Name: "content", Type: hash},
+			{Name: "previous", Type: avro.UnionSchema{avro.TypeNull, hash}},
+		},
+	}
+	source, err := Generate("files", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "const FileSchemaJSON = `{\"type\":\"record\",\"name\":\"file\",\"fields\":[{\"name\":\"content\",\"type\":{\"type\":\"fixed\",\"name\":\"MD5\",\"size\":16}},{\"name\":\"previous\",\"type\":[\"null\",\"MD5\"]}]}`\n"
+	if !strings.Contains(string(source), expected) {
+		t.Errorf("expected:\n%s\nin:\n%s\n", expected, source)
+	}
+}
+
+func TestGenerateCompiles(t *testing.T) {
+	cases := []string{
+		`"string"`,
+		`{"type":"array","items":{"type":"record","name":"event","fields":[{"name":"at","type":{"type":"long","logicalType":"timestamp-millis"}}]}}`,
+		`{"type":"map","values":{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}}`,
+		`["null","string",{"type":"enum","name":"status","symbols":["DRAFT"]}]`,
+	}
+	for i, c := range cases {
+		var anySchema avro.AnySchema
+		err := json.Unmarshal([]byte(c), &anySchema)
+		if err != nil {
+			t.Fatalf("case %d - %v", i, err)
+		}
+		source, err := Generate("generated", anySchema.Schema())
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "generated.go", source, 0)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		_, err = config.Check("generated", fset, []*ast.File{file}, nil)
+		if err != nil {
+			t.Errorf("case %d - %v in:\n%s", i, err, source)
+		}
+	}
+}
+
+func TestGenerateNames(t *testing.T) {
+	cases := []struct {
+		name, expectedIdentifier string
+	}{
+		{"friends_record", "FriendsRecord"},
+		{"test.LongList", "LongList"},
+		{"isActive", "IsActive"},
+		{"_hidden", "Hidden"},
+		{"2fa", "X2fa"},
+	}
+	for _, c := range cases {
+		if identifier(c.name) != c.expectedIdentifier {
+			t.Errorf("expected %s, got %s", c.expectedIdentifier, identifier(c.name))
+		}
+	}
+	enum := &avro.EnumSchema{Type: avro.TypeEnum, Name: "status", Symbols: []string{"A"}}
+	other := &avro.EnumSchema{Type: avro.TypeEnum, Namespace: "other", Name: "status", Symbols: []string{"B"}}
+	source, err := Generate("blog", enum, other, avro.UnionSchema{avro.TypeString, avro.TypeInt32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "// Code generated by avrogen. DO NOT EDIT.\n\npackage blog\n\nimport (\n\t\"encoding/json\"\n\n\t\"github.com/khezen/avro\"\n)\n\n// Status - enum status\ntype Status string\n\nconst (\n\t// StatusA -\n\tStatusA Status = \"A\"\n)\n\n// StatusSchemaJSON - AVRO schema of Status\nconst StatusSchemaJSON = `{\"type\":\"enum\",\"name\":\"status\",\"symbols\":[\"A\"]}`\n\n// NewStatusSchema - unmarshals StatusSchemaJSON\nfunc NewStatusSchema() (*avro.EnumSchema, error) {\n\tvar anySchema avro.AnySchema\n\terr := json.Unmarshal([]byte(StatusSchemaJSON), &anySchema)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn anySchema.Schema().(*avro.EnumSchema), nil\n}\n\n// Status2 - enum other.status\ntype Status2 string\n\nconst (\n\t// Status2B -\n\tStatus2B Status2 = \"B\"\n)\n\n// Status2SchemaJSON - AVRO schema of Status2\nconst Status2SchemaJSON = `{\"type\":\"enum\",\"name\":\"status\",\"namespace\":\"other\",\"symbols\":[\"B\"]}`\n\n// NewStatus2Schema - unmarshals Status2SchemaJSON\nfunc NewStatus2Schema() (*avro.EnumSchema, error) {\n\tvar anySchema avro.AnySchema\n\terr := json.Unmarshal([]byte(Status2SchemaJSON), &anySchema)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn anySchema.Schema().(*avro.EnumSchema), nil\n}\n\n// Union - union of string, int\ntype Union struct {\n\t// Type - name of the branch holding the value\n\tType   string\n\tString string\n\tInt    int32\n}\n"
+	if string(source) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s\n", expected, source)
+	}
+	_, err = Generate("blog", avro.Type("something"))
+	if err != ErrUnsupportedSchema {
+		t.Errorf("expected %v, got %v", ErrUnsupportedSchema, err)
+	}
+}
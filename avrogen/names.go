@@ -0,0 +1,54 @@
+package avrogen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// identifier turns an AVRO name into an exported Go identifier,
+// e.g. "friends_record" becomes "FriendsRecord"
+func identifier(name string) string {
+	name = name[strings.LastIndex(name, ".")+1:]
+	buf := new(strings.Builder)
+	for _, part := range strings.FieldsFunc(name, isSeparator) {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+	return exported(buf.String())
+}
+
+// symbolIdentifier turns an enum symbol into a Go identifier suffix,
+// e.g. "NOT_SET" becomes "NotSet"
+func symbolIdentifier(symbol string) string {
+	buf := new(strings.Builder)
+	for _, part := range strings.FieldsFunc(symbol, isSeparator) {
+		if strings.ToUpper(part) == part {
+			part = strings.ToLower(part)
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+	return buf.String()
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// exported ensures the identifier starts with an upper case letter
+func exported(name string) string {
+	if name == "" || !unicode.IsUpper([]rune(name)[0]) {
+		return "X" + name
+	}
+	return name
+}
+
+func namespaceOf(fullname string) string {
+	i := strings.LastIndex(fullname, ".")
+	if i < 0 {
+		return ""
+	}
+	return fullname[:i]
+}
@@ -0,0 +1,212 @@
+package avro
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+func (d *binaryDecoder) decode(schema Schema, namespace string) (interface{}, error) {
+	switch t := schema.(type) {
+	case Type:
+		return d.decodePrimitive(t)
+	case *DerivedPrimitiveSchema:
+		return d.decodeDerivedPrimitive(t)
+	case *ReferenceSchema:
+		return d.decode(t.Schema, namespaceOf(fullName(t.Name, "", namespace)))
+	case UnionSchema:
+		return d.decodeUnion(t, namespace)
+	case *ArraySchema:
+		return d.decodeArray(t, namespace)
+	case *MapSchema:
+		return d.decodeMap(t, namespace)
+	case *RecordSchema:
+		return d.decodeRecord(t, namespace)
+	case *EnumSchema:
+		index, err := d.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 || index >= int64(len(t.Symbols)) {
+			return nil, ErrInvalidEncoding
+		}
+		return t.Symbols[index], nil
+	case *FixedSchema:
+		return d.decodeFixed(t)
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func (d *binaryDecoder) decodePrimitive(typeName Type) (interface{}, error) {
+	switch typeName {
+	case TypeNull:
+		return nil, nil
+	case TypeBoolean:
+		if len(d.buf) < 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		b := d.buf[0]
+		d.buf = d.buf[1:]
+		if b > 1 {
+			return nil, ErrInvalidEncoding
+		}
+		return b == 1, nil
+	case TypeInt32:
+		return d.readInt()
+	case TypeInt64:
+		return d.readLong()
+	case TypeFloat32:
+		if len(d.buf) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		f := math.Float32frombits(binary.LittleEndian.Uint32(d.buf))
+		d.buf = d.buf[4:]
+		return f, nil
+	case TypeFloat64:
+		if len(d.buf) < 8 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+		d.buf = d.buf[8:]
+		return f, nil
+	case TypeBytes:
+		return d.readBytes()
+	case TypeString:
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func (d *binaryDecoder) decodeDerivedPrimitive(schema *DerivedPrimitiveSchema) (interface{}, error) {
+	datum, err := d.decodePrimitive(schema.Type)
+	if err != nil {
+		return nil, err
+	}
+	return nativeDatum(schema, datum), nil
+}
+
+func (d *binaryDecoder) decodeFixed(schema *FixedSchema) (interface{}, error) {
+	b, err := d.readFixed(schema.Size)
+	if err != nil {
+		return nil, err
+	}
+	return nativeFixedDatum(schema, b), nil
+}
+
+func (d *binaryDecoder) decodeUnion(union UnionSchema, namespace string) (interface{}, error) {
+	index, err := d.readLong()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= int64(len(union)) {
+		return nil, ErrInvalidEncoding
+	}
+	branch := union[index]
+	datum, err := d.decode(branch, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if branch.TypeName() == TypeNull {
+		return nil, nil
+	}
+	return map[string]interface{}{branchName(branch, namespace): datum}, nil
+}
+
+func (d *binaryDecoder) decodeArray(schema *ArraySchema, namespace string) (interface{}, error) {
+	items := make([]interface{}, 0)
+	zeroSize := isZeroSize(schema.Items, make(map[*RecordSchema]bool))
+	for {
+		count, err := d.readBlockCount()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return items, nil
+		}
+		// the count is not trusted: items take at least a byte, unless they are zero-size
+		if zeroSize && int64(len(items))+count > int64(MaxZeroSizeItems) || !zeroSize && count > int64(len(d.buf)) {
+			return nil, ErrInvalidEncoding
+		}
+		for ; count > 0; count-- {
+			item, err := d.decode(schema.Items, namespace)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+}
+
+func (d *binaryDecoder) decodeMap(schema *MapSchema, namespace string) (interface{}, error) {
+	values := make(map[string]interface{})
+	for {
+		count, err := d.readBlockCount()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return values, nil
+		}
+		// the count is not trusted: keys take at least a byte
+		if count > int64(len(d.buf)) {
+			return nil, ErrInvalidEncoding
+		}
+		for ; count > 0; count-- {
+			key, err := d.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.decode(schema.Value, namespace)
+			if err != nil {
+				return nil, err
+			}
+			values[string(key)] = value
+		}
+	}
+}
+
+func (d *binaryDecoder) decodeRecord(schema *RecordSchema, namespace string) (interface{}, error) {
+	namespace = namespaceOf(schema.FullName(namespace))
+	record := make(map[string]interface{}, len(schema.Fields))
+	for _, field := range schema.Fields {
+		value, err := d.decode(field.Type, namespace)
+		if err != nil {
+			return nil, err
+		}
+		record[field.Name] = value
+	}
+	return record, nil
+}
+
+// isZeroSize reports whether the binary encoding of the schema takes no byte, such as null or records of nulls
+func isZeroSize(schema Schema, visited map[*RecordSchema]bool) bool {
+	switch t := schema.(type) {
+	case Type:
+		return t == TypeNull
+	case *DerivedPrimitiveSchema:
+		return t.Type == TypeNull
+	case *ReferenceSchema:
+		return isZeroSize(t.Schema, visited)
+	case *FixedSchema:
+		return t.Size == 0
+	case *RecordSchema:
+		if visited[t] {
+			return false
+		}
+		visited[t] = true
+		for _, field := range t.Fields {
+			if !isZeroSize(field.Type, visited) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,178 @@
+package avro
+
+import (
+	"math"
+)
+
+func encodeBinary(buf []byte, schema Schema, datum interface{}, namespace string) ([]byte, error) {
+	switch t := schema.(type) {
+	case Type:
+		return encodePrimitiveBinary(buf, t, datum)
+	case *DerivedPrimitiveSchema:
+		return encodeDerivedPrimitiveBinary(buf, t, datum)
+	case *ReferenceSchema:
+		return encodeBinary(buf, t.Schema, datum, namespaceOf(fullName(t.Name, "", namespace)))
+	case UnionSchema:
+		return encodeUnionBinary(buf, t, datum, namespace)
+	case *ArraySchema:
+		return encodeArrayBinary(buf, t, datum, namespace)
+	case *MapSchema:
+		return encodeMapBinary(buf, t, datum, namespace)
+	case *RecordSchema:
+		return encodeRecordBinary(buf, t, datum, namespace)
+	case *EnumSchema:
+		symbol, ok := stringDatum(datum)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		for i, s := range t.Symbols {
+			if s == symbol {
+				return appendLong(buf, int64(i)), nil
+			}
+		}
+		return nil, ErrInvalidDatum
+	case *FixedSchema:
+		return encodeFixedBinary(buf, t, datum)
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func encodePrimitiveBinary(buf []byte, typeName Type, datum interface{}) ([]byte, error) {
+	switch typeName {
+	case TypeNull:
+		if datum != nil {
+			return nil, ErrInvalidDatum
+		}
+		return buf, nil
+	case TypeBoolean:
+		b, ok := datum.(bool)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case TypeInt32:
+		n, ok := int64Datum(datum)
+		if !ok || n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, ErrInvalidDatum
+		}
+		return appendLong(buf, n), nil
+	case TypeInt64:
+		n, ok := int64Datum(datum)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		return appendLong(buf, n), nil
+	case TypeFloat32:
+		f, ok := float64Datum(datum)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		return appendFloat(buf, float32(f)), nil
+	case TypeFloat64:
+		f, ok := float64Datum(datum)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		return appendDouble(buf, f), nil
+	case TypeBytes:
+		b, ok := bytesDatum(datum)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		return appendBytes(buf, b), nil
+	case TypeString:
+		s, ok := stringDatum(datum)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		return appendBytes(buf, []byte(s)), nil
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func encodeDerivedPrimitiveBinary(buf []byte, schema *DerivedPrimitiveSchema, datum interface{}) ([]byte, error) {
+	return encodePrimitiveBinary(buf, schema.Type, primitiveDatum(schema, datum))
+}
+
+func encodeFixedBinary(buf []byte, schema *FixedSchema, datum interface{}) ([]byte, error) {
+	b, ok := fixedDatum(schema, datum)
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	return append(buf, b...), nil
+}
+
+func encodeUnionBinary(buf []byte, union UnionSchema, datum interface{}, namespace string) ([]byte, error) {
+	if i, value, ok := unionBranch(union, datum, namespace); ok {
+		return encodeBinary(appendLong(buf, int64(i)), union[i], value, namespace)
+	}
+	// the datum isn't wrapped: the first branch able to encode it is selected
+	for i, branch := range union {
+		encoded, err := encodeBinary(appendLong(buf, int64(i)), branch, datum, namespace)
+		if err == nil {
+			return encoded, nil
+		}
+	}
+	return nil, ErrInvalidDatum
+}
+
+func encodeArrayBinary(buf []byte, schema *ArraySchema, datum interface{}, namespace string) ([]byte, error) {
+	items, ok := arrayDatum(datum)
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	var err error
+	if len(items) > 0 {
+		buf = appendLong(buf, int64(len(items)))
+		for _, item := range items {
+			buf, err = encodeBinary(buf, schema.Items, item, namespace)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return appendLong(buf, 0), nil
+}
+
+func encodeMapBinary(buf []byte, schema *MapSchema, datum interface{}, namespace string) ([]byte, error) {
+	values, ok := mapDatum(datum)
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	var err error
+	if len(values) > 0 {
+		buf = appendLong(buf, int64(len(values)))
+		for key, value := range values {
+			buf = appendBytes(buf, []byte(key))
+			buf, err = encodeBinary(buf, schema.Value, value, namespace)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return appendLong(buf, 0), nil
+}
+
+func encodeRecordBinary(buf []byte, schema *RecordSchema, datum interface{}, namespace string) ([]byte, error) {
+	record, ok := datum.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	namespace = namespaceOf(schema.FullName(namespace))
+	for _, field := range schema.Fields {
+		value, err := fieldDatum(field, record, namespace)
+		if err != nil {
+			return nil, err
+		}
+		buf, err = encodeBinary(buf, field.Type, value, namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
@@ -0,0 +1,123 @@
+package avro
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// MaxZeroSizeItems - maximum number of items decoded for an array of zero-size items, such as nulls or empty records.
+// Other arrays and maps cannot hold more items than the bytes left to decode, ErrInvalidEncoding is returned otherwise.
+var MaxZeroSizeItems = 1 << 16
+
+// EncodeBinary - appends the binary encoding of the datum, according to the schema, to buf.
+func EncodeBinary(buf []byte, schema Schema, datum interface{}) ([]byte, error) {
+	return encodeBinary(buf, schema, datum, "")
+}
+
+// DecodeBinary - decodes a datum, according to the schema, from buf and returns the remaining bytes.
+func DecodeBinary(buf []byte, schema Schema) (datum interface{}, rest []byte, err error) {
+	d := binaryDecoder{buf: buf}
+	datum, err = d.decode(schema, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return datum, d.buf, nil
+}
+
+func appendLong(buf []byte, n int64) []byte {
+	u := uint64((n << 1) ^ (n >> 63))
+	for u >= 0x80 {
+		buf = append(buf, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(buf, byte(u))
+}
+
+func appendFloat(buf []byte, f float32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], math.Float32bits(f))
+	return append(buf, b[:]...)
+}
+
+func appendDouble(buf []byte, f float64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	return append(buf, b[:]...)
+}
+
+func appendBytes(buf []byte, b []byte) []byte {
+	buf = appendLong(buf, int64(len(b)))
+	return append(buf, b...)
+}
+
+type binaryDecoder struct {
+	buf []byte
+}
+
+func (d *binaryDecoder) readLong() (int64, error) {
+	u, n := binary.Uvarint(d.buf)
+	if n == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if n < 0 || n > 10 {
+		return 0, ErrInvalidEncoding
+	}
+	d.buf = d.buf[n:]
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+func (d *binaryDecoder) readInt() (int32, error) {
+	n, err := d.readLong()
+	if err != nil {
+		return 0, err
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return 0, ErrInvalidEncoding
+	}
+	return int32(n), nil
+}
+
+func (d *binaryDecoder) readFixed(size int) ([]byte, error) {
+	if size < 0 {
+		return nil, ErrInvalidEncoding
+	}
+	if len(d.buf) < size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, size)
+	copy(b, d.buf)
+	d.buf = d.buf[size:]
+	return b, nil
+}
+
+func (d *binaryDecoder) readBytes() ([]byte, error) {
+	size, err := d.readLong()
+	if err != nil {
+		return nil, err
+	}
+	if size > int64(len(d.buf)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return d.readFixed(int(size))
+}
+
+// readBlockCount reads the item count of the next block of an array or map
+func (d *binaryDecoder) readBlockCount() (int64, error) {
+	count, err := d.readLong()
+	if err != nil {
+		return 0, err
+	}
+	if count < 0 {
+		// negative count is followed by the block size in bytes
+		count = -count
+		if count < 0 {
+			return 0, ErrInvalidEncoding
+		}
+		_, err = d.readLong()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,172 @@
+package avro
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	cases := []struct {
+		schema   string
+		datum    interface{}
+		expected interface{}
+	}{
+		{`"null"`, nil, nil},
+		{`"boolean"`, true, true},
+		{`"int"`, int32(-64), int32(-64)},
+		{`"int"`, 150, int32(150)},
+		{`"long"`, int64(1) << 40, int64(1) << 40},
+		{`"float"`, float32(3.5), float32(3.5)},
+		{`"double"`, -2.25, -2.25},
+		{`"bytes"`, []byte{0, 1, 2}, []byte{0, 1, 2}},
+		{`"string"`, "foo", "foo"},
+		{`{"type":"array","items":"long"}`, []interface{}{int64(1), int64(2)}, []interface{}{int64(1), int64(2)}},
+		{`{"type":"array","items":"long"}`, []int64{3}, []interface{}{int64(3)}},
+		{`{"type":"map","values":"string"}`, map[string]string{"a": "b"}, map[string]interface{}{"a": "b"}},
+		{`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]}`, "HEARTS", "HEARTS"},
+		{`{"type":"fixed","name":"two","size":2}`, [2]byte{7, 8}, []byte{7, 8}},
+		{`["null","string"]`, nil, nil},
+		{`["null","string"]`, "foo", map[string]interface{}{"string": "foo"}},
+		{`["null","string"]`, map[string]interface{}{"string": "foo"}, map[string]interface{}{"string": "foo"}},
+		{`["null",{"type":"record","namespace":"ns","name":"A","fields":[{"name":"f","type":"int"}]}]`, map[string]interface{}{"A": map[string]interface{}{"f": 1}}, map[string]interface{}{"ns.A": map[string]interface{}{"f": int32(1)}}},
+		{`["null",{"type":"int","logicalType":"date"}]`, map[string]interface{}{"int": 1}, map[string]interface{}{"int.date": epoch.Add(24 * time.Hour)}},
+		{`{"type":"int","logicalType":"date"}`, time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC), time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{`{"type":"int","logicalType":"date"}`, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{`{"type":"int","logicalType":"date"}`, time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{`{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}`, big.NewRat(-12345, 100), big.NewRat(-12345, 100)},
+		{`{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}`, big.NewRat(128, 100), big.NewRat(128, 100)},
+		{`{"type":"long","logicalType":"timestamp-millis"}`, time.Date(1969, 12, 31, 23, 59, 59, 999000000, time.UTC), time.Date(1969, 12, 31, 23, 59, 59, 999000000, time.UTC)},
+		{`{"type":"long","logicalType":"timestamp-micros"}`, time.Date(2020, 2, 29, 1, 2, 3, 4000, time.UTC), time.Date(2020, 2, 29, 1, 2, 3, 4000, time.UTC)},
+		{`{"type":"long","logicalType":"local-timestamp-millis"}`, time.Date(2020, 2, 29, 1, 2, 3, 0, time.FixedZone("CET", 3600)), time.Date(2020, 2, 29, 1, 2, 3, 0, time.UTC)},
+		{`{"type":"int","logicalType":"time-millis"}`, 90 * time.Minute, 90 * time.Minute},
+		{`{"type":"long","logicalType":"time-micros"}`, time.Hour + time.Microsecond, time.Hour + time.Microsecond},
+		{`{"type":"long","logicalType":"timestamp"}`, time.Date(2020, 2, 29, 1, 2, 3, 0, time.UTC), time.Date(2020, 2, 29, 1, 2, 3, 0, time.UTC)},
+		{`{"type":"int","logicalType":"timestamp"}`, time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC), time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{`{"type":"string","logicalType":"uuid"}`, "5e3a4f38-6f5a-4a9e-9a3b-5c1f2d9b7a10", "5e3a4f38-6f5a-4a9e-9a3b-5c1f2d9b7a10"},
+		{`["null",{"type":"string","logicalType":"something"}]`, "foo", map[string]interface{}{"string": "foo"}},
+		{`{"type":"int","connect.type":"int16"}`, 5, int32(5)},
+		{`["null",{"type":"string","sqlType":"VARCHAR"}]`, "foo", map[string]interface{}{"string": "foo"}},
+		{`{"type":"fixed","name":"money","logicalType":"decimal","size":4,"precision":9,"scale":2}`, big.NewRat(-12345, 100), big.NewRat(-12345, 100)},
+		{
+			`{"type":"record","name":"LongList","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"]}]}`,
+			map[string]interface{}{"value": 1, "next": map[string]interface{}{"LongList": map[string]interface{}{"value": 2, "next": nil}}},
+			map[string]interface{}{"value": int64(1), "next": map[string]interface{}{"LongList": map[string]interface{}{"value": int64(2), "next": nil}}},
+		},
+		{
+			`{"type":"record","name":"R","fields":[{"name":"a","type":"string","default":"foo"},{"name":"b","type":["null","int"],"default":null},{"name":"c","type":"bytes","default":"ÿ"}]}`,
+			map[string]interface{}{},
+			map[string]interface{}{"a": "foo", "b": nil, "c": []byte{0xff}},
+		},
+	}
+	for i, c := range cases {
+		schema := mustParseSchema(t, c.schema)
+		buf, err := EncodeBinary(nil, schema, c.datum)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		codec, err := goavro.NewCodec(c.schema)
+		if err != nil {
+			t.Fatalf("case %d - %v", i, err)
+		}
+		_, _, err = codec.NativeFromBinary(buf)
+		if err != nil {
+			t.Errorf("case %d - goavro failed to decode: %v", i, err)
+		}
+		datum, rest, err := DecodeBinary(append(buf, 42), schema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if !bytes.Equal(rest, []byte{42}) {
+			t.Errorf("case %d - expected rest [42], got %v", i, rest)
+		}
+		if r, ok := c.expected.(*big.Rat); ok {
+			if datum.(*big.Rat).Cmp(r) != 0 {
+				t.Errorf("case %d - expected %v, got %v", i, r, datum)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(datum, c.expected) {
+			t.Errorf("case %d - expected %#v, got %#v", i, c.expected, datum)
+		}
+	}
+}
+
+func TestEncodeBinary(t *testing.T) {
+	cases := []struct {
+		schema      string
+		datum       interface{}
+		expected    []byte
+		expectedErr error
+	}{
+		{`"long"`, 0, []byte{0}, nil},
+		{`"long"`, -1, []byte{1}, nil},
+		{`"long"`, 64, []byte{0x80, 0x01}, nil},
+		{`"string"`, "foo", []byte{6, 'f', 'o', 'o'}, nil},
+		{`["null","string"]`, "a", []byte{2, 2, 'a'}, nil},
+		{`{"type":"array","items":"int"}`, []int{}, []byte{0}, nil},
+		{`{"type":"bytes","logicalType":"decimal","precision":3,"scale":0}`, big.NewRat(-1, 1), []byte{2, 0xff}, nil},
+		{`{"type":"long","logicalType":"timestamp-millis"}`, time.Unix(1, 0), []byte{0xd0, 0x0f}, nil},
+		{`{"type":"fixed","name":"money","logicalType":"decimal","size":2,"precision":4}`, big.NewRat(-1, 1), []byte{0xff, 0xff}, nil},
+		{`{"type":"fixed","name":"money","logicalType":"decimal","size":2,"precision":4}`, big.NewRat(1<<16, 1), nil, ErrInvalidDatum},
+		{`"int"`, int64(1) << 40, nil, ErrInvalidDatum},
+		{`"string"`, 1, nil, ErrInvalidDatum},
+		{`"null"`, 1, nil, ErrInvalidDatum},
+		{`["null","string"]`, 1, nil, ErrInvalidDatum},
+		{`{"type":"enum","name":"Suit","symbols":["SPADES"]}`, "HEARTS", nil, ErrInvalidDatum},
+		{`{"type":"fixed","name":"two","size":2}`, []byte{1}, nil, ErrInvalidDatum},
+		{`{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`, map[string]interface{}{}, nil, ErrInvalidDatum},
+	}
+	for i, c := range cases {
+		buf, err := EncodeBinary(nil, mustParseSchema(t, c.schema), c.datum)
+		if err != c.expectedErr {
+			t.Errorf("case %d - expected error %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if !bytes.Equal(buf, c.expected) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expected, buf)
+		}
+	}
+}
+
+func TestDecodeBinary(t *testing.T) {
+	cases := []struct {
+		schema      string
+		buf         []byte
+		expected    interface{}
+		expectedErr error
+	}{
+		{`{"type":"array","items":"int"}`, []byte{3, 4, 2, 4, 0}, []interface{}{int32(1), int32(2)}, nil},
+		{`{"type":"map","values":"int"}`, []byte{1, 6, 2, 'a', 2, 0}, map[string]interface{}{"a": int32(1)}, nil},
+		{`"string"`, []byte{6, 'f'}, nil, io.ErrUnexpectedEOF},
+		{`"long"`, []byte{0x80}, nil, io.ErrUnexpectedEOF},
+		{`"double"`, []byte{0, 0}, nil, io.ErrUnexpectedEOF},
+		{`"boolean"`, []byte{2}, nil, ErrInvalidEncoding},
+		{`"int"`, []byte{0x80, 0x80, 0x80, 0x80, 0x20}, nil, ErrInvalidEncoding},
+		{`["null","int"]`, []byte{4}, nil, ErrInvalidEncoding},
+		{`{"type":"enum","name":"Suit","symbols":["SPADES"]}`, []byte{2}, nil, ErrInvalidEncoding},
+		{`{"type":"array","items":"long"}`, []byte{20, 2, 4}, nil, ErrInvalidEncoding},
+		{`{"type":"array","items":"null"}`, []byte{6, 0}, []interface{}{nil, nil, nil}, nil},
+		{`{"type":"array","items":{"type":"record","name":"Empty","fields":[]}}`, []byte{4, 0}, []interface{}{map[string]interface{}{}, map[string]interface{}{}}, nil},
+		{`{"type":"array","items":"null"}`, appendLong(nil, 1<<32), nil, ErrInvalidEncoding},
+		{`{"type":"array","items":{"type":"record","name":"Empty","fields":[]}}`, append(appendLong(appendLong(nil, int64(MaxZeroSizeItems)), 2), 0), nil, ErrInvalidEncoding},
+		{`{"type":"map","values":"null"}`, []byte{10, 2, 'a'}, nil, ErrInvalidEncoding},
+	}
+	for i, c := range cases {
+		datum, _, err := DecodeBinary(c.buf, mustParseSchema(t, c.schema))
+		if err != c.expectedErr {
+			t.Errorf("case %d - expected error %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if !reflect.DeepEqual(datum, c.expected) {
+			t.Errorf("case %d - expected %#v, got %#v", i, c.expected, datum)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// CanonicalForm - renders the given schema into its Parsing Canonical Form.
+// Attributes irrelevant to parsing (doc, aliases, logical types, defaults...) are stripped,
+// names are replaced by fullnames and named types are only defined once.
+func CanonicalForm(schema Schema) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := writeCanonicalForm(buf, schema, "", make(map[Schema]string))
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCanonicalForm - namespace is the enclosing namespace
+// and defined maps the named schemas already written to their fullname
+func writeCanonicalForm(buf *bytes.Buffer, schema Schema, namespace string, defined map[Schema]string) error {
+	switch t := schema.(type) {
+	case Type:
+		if isPrimitive(t) {
+			writeCanonicalString(buf, string(t))
+		} else {
+			writeCanonicalString(buf, fullName(string(t), "", namespace))
+		}
+		return nil
+	case *DerivedPrimitiveSchema:
+		writeCanonicalString(buf, string(t.Type))
+		return nil
+	case *ReferenceSchema:
+		if fullname, ok := defined[t.Schema]; ok {
+			writeCanonicalString(buf, fullname)
+			return nil
+		}
+		writeCanonicalString(buf, fullName(t.Name, "", namespace))
+		return nil
+	case UnionSchema:
+		buf.WriteRune('[')
+		for i, subSchema := range t {
+			if i > 0 {
+				buf.WriteRune(',')
+			}
+			err := writeCanonicalForm(buf, subSchema, namespace, defined)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteRune(']')
+		return nil
+	case *ArraySchema:
+		buf.WriteString(`{"type":"array","items":`)
+		err := writeCanonicalForm(buf, t.Items, namespace, defined)
+		if err != nil {
+			return err
+		}
+		buf.WriteRune('}')
+		return nil
+	case *MapSchema:
+		buf.WriteString(`{"type":"map","values":`)
+		err := writeCanonicalForm(buf, t.Value, namespace, defined)
+		if err != nil {
+			return err
+		}
+		buf.WriteRune('}')
+		return nil
+	case *RecordSchema, *EnumSchema, *FixedSchema:
+		return writeCanonicalNamedSchema(buf, t.(NamedSchema), namespace, defined)
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+func writeCanonicalNamedSchema(buf *bytes.Buffer, schema NamedSchema, namespace string, defined map[Schema]string) error {
+	if fullname, ok := defined[schema]; ok {
+		writeCanonicalString(buf, fullname)
+		return nil
+	}
+	fullname := schema.FullName(namespace)
+	defined[schema] = fullname
+	buf.WriteString(`{"name":`)
+	writeCanonicalString(buf, fullname)
+	buf.WriteString(`,"type":`)
+	switch t := schema.(type) {
+	case *RecordSchema:
+		writeCanonicalString(buf, string(TypeRecord))
+		buf.WriteString(`,"fields":[`)
+		for i, field := range t.Fields {
+			if i > 0 {
+				buf.WriteRune(',')
+			}
+			buf.WriteString(`{"name":`)
+			writeCanonicalString(buf, field.Name)
+			buf.WriteString(`,"type":`)
+			err := writeCanonicalForm(buf, field.Type, namespaceOf(fullname), defined)
+			if err != nil {
+				return err
+			}
+			buf.WriteRune('}')
+		}
+		buf.WriteRune(']')
+	case *EnumSchema:
+		writeCanonicalString(buf, string(TypeEnum))
+		buf.WriteString(`,"symbols":[`)
+		for i, symbol := range t.Symbols {
+			if i > 0 {
+				buf.WriteRune(',')
+			}
+			writeCanonicalString(buf, symbol)
+		}
+		buf.WriteRune(']')
+	case *FixedSchema:
+		writeCanonicalString(buf, string(TypeFixed))
+		buf.WriteString(`,"size":`)
+		buf.WriteString(strconv.Itoa(t.Size))
+	}
+	buf.WriteRune('}')
+	return nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, str string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	// the encoder terminates each value with a newline
+	_ = encoder.Encode(str)
+	buf.Truncate(buf.Len() - 1)
+}
@@ -0,0 +1,134 @@
+package avro
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func TestCanonicalForm(t *testing.T) {
+	cases := []struct {
+		schemaBytes       []byte
+		expectedCanonical string
+		// goavro doesn't strip logical types nor qualify references as the spec requires
+		sameAsGoavro bool
+	}{
+		{
+			[]byte(`"int"`),
+			`"int"`,
+			true,
+		},
+		{
+			[]byte(`{"type":"long","logicalType":"timestamp","doc":"datetime"}`),
+			`"long"`,
+			false,
+		},
+		{
+			[]byte(`{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}`),
+			`"bytes"`,
+			false,
+		},
+		{
+			[]byte(`["null",{"type":"array","items":"string"},{"type":"map","values":"double"}]`),
+			`["null",{"type":"array","items":"string"},{"type":"map","values":"double"}]`,
+			true,
+		},
+		{
+			[]byte(`{"type":"fixed","namespace":"crypto","name":"md5","aliases":["hash"],"size":16}`),
+			`{"name":"crypto.md5","type":"fixed","size":16}`,
+			true,
+		},
+		{
+			[]byte(`{"type":"fixed","logicalType":"duration","name":"duration","size":12}`),
+			`{"name":"duration","type":"fixed","size":12}`,
+			true,
+		},
+		{
+			[]byte(`{"type":"enum","name":"Suit","doc":"card suits","symbols":["SPADES","HEARTS","DIAMONDS","CLUBS"]}`),
+			`{"name":"Suit","type":"enum","symbols":["SPADES","HEARTS","DIAMONDS","CLUBS"]}`,
+			true,
+		},
+		{
+			[]byte(`{"type":"record","namespace":"test","name":"LongList","aliases":["LinkedLongs"],"doc":"list of 64 bits integers","fields":[{"name":"value","type":"long","default":0,"order":"ignore"},{"name":"next","type":["null","LongList"]}]}`),
+			`{"name":"test.LongList","type":"record","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","test.LongList"]}]}`,
+			false,
+		},
+		{
+			[]byte(`{"type":"record","namespace":"game","name":"card","fields":[{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES"]}},{"name":"trump","type":"Suit"},{"name":"deck","type":{"type":"record","namespace":"other","name":"deck","fields":[{"name":"suit","type":"game.Suit"},{"name":"hash","type":{"type":"fixed","name":"md5","size":16}},{"name":"previousHash","type":"md5"}]}}]}`),
+			`{"name":"game.card","type":"record","fields":[{"name":"suit","type":{"name":"game.Suit","type":"enum","symbols":["SPADES"]}},{"name":"trump","type":"game.Suit"},{"name":"deck","type":{"name":"other.deck","type":"record","fields":[{"name":"suit","type":"game.Suit"},{"name":"hash","type":{"name":"other.md5","type":"fixed","size":16}},{"name":"previousHash","type":"other.md5"}]}}]}`,
+			false,
+		},
+	}
+	var anySchema AnySchema
+	for i, c := range cases {
+		err := json.Unmarshal(c.schemaBytes, &anySchema)
+		if err != nil {
+			t.Fatalf("case %d - %v", i, err)
+		}
+		schema := anySchema.Schema()
+		canonical, err := CanonicalForm(schema)
+		if err != nil {
+			t.Fatalf("case %d - %v", i, err)
+		}
+		if string(canonical) != c.expectedCanonical {
+			t.Errorf("case %d -\nexpected:\n%s\ngot:\n%s\n", i, c.expectedCanonical, canonical)
+		}
+		md5Sum, err := FingerprintMD5(schema)
+		if err != nil {
+			t.Fatalf("case %d - %v", i, err)
+		}
+		if md5Sum != md5.Sum(canonical) {
+			t.Errorf("case %d - md5 mismatch", i)
+		}
+		sha256Sum, err := FingerprintSHA256(schema)
+		if err != nil {
+			t.Fatalf("case %d - %v", i, err)
+		}
+		if sha256Sum != sha256.Sum256(canonical) {
+			t.Errorf("case %d - sha256 mismatch", i)
+		}
+		if !c.sameAsGoavro {
+			continue
+		}
+		codec, err := goavro.NewCodec(string(c.schemaBytes))
+		if err != nil {
+			t.Fatalf("case %d - %v", i, err)
+		}
+		if codec.CanonicalSchema() != string(canonical) {
+			t.Errorf("case %d - goavro canonical form mismatch:\n%s\n%s\n", i, codec.CanonicalSchema(), canonical)
+		}
+		rabin, err := FingerprintRabin(schema)
+		if err != nil {
+			t.Fatalf("case %d - %v", i, err)
+		}
+		if rabin != codec.Rabin {
+			t.Errorf("case %d - expected rabin %x, got %x", i, codec.Rabin, rabin)
+		}
+	}
+}
+
+func TestCanonicalFormNamedTypeDefinedOnce(t *testing.T) {
+	md5Schema := &FixedSchema{Type: TypeFixed, Namespace: "crypto", Name: "md5", Size: 16}
+	schema := &RecordSchema{
+		Type: TypeRecord,
+		Name: "file",
+		Fields: []RecordFieldSchema{
+			{Name: "hash", Type: md5Schema},
+			{Name: "previousHash", Type: UnionSchema{TypeNull, md5Schema}},
+		},
+	}
+	canonical, err := CanonicalForm(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"name":"file","type":"record","fields":[{"name":"hash","type":{"name":"crypto.md5","type":"fixed","size":16}},{"name":"previousHash","type":["null","crypto.md5"]}]}`
+	if string(canonical) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s\n", expected, canonical)
+	}
+	if Rabin([]byte(`"int"`)) != 0x7275d51a3f395c8f {
+		t.Errorf("unexpected rabin fingerprint for \"int\": %x", Rabin([]byte(`"int"`)))
+	}
+}
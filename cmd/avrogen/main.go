@@ -0,0 +1,75 @@
+// avrogen generates Go types from AVRO schema files.
+//
+// Usage:
+//
+//	avrogen -package models -o models/schemas.go post.avsc author.avsc
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/khezen/avro"
+	"github.com/khezen/avro/avrogen"
+)
+
+func main() {
+	os.Exit(cli(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// cli runs avrogen with the given arguments and returns the exit code
+func cli(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("avrogen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var (
+		packageName = flags.String("package", "main", "name of the generated package")
+		output      = flags.String("o", "", "output file, standard output if empty")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: avrogen [-package name] [-o output.go] schema.avsc...\n")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	err = run(*packageName, *output, flags.Args(), stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "avrogen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func run(packageName, output string, schemaPaths []string, stdout io.Writer) error {
+	schemas := make([]avro.Schema, 0, len(schemaPaths))
+	for _, schemaPath := range schemaPaths {
+		schemaBytes, err := ioutil.ReadFile(schemaPath)
+		if err != nil {
+			return err
+		}
+		var anySchema avro.AnySchema
+		err = json.Unmarshal(schemaBytes, &anySchema)
+		if err != nil {
+			return fmt.Errorf("%s: %v", schemaPath, err)
+		}
+		schemas = append(schemas, anySchema.Schema())
+	}
+	source, err := avrogen.Generate(packageName, schemas...)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = stdout.Write(source)
+		return err
+	}
+	return ioutil.WriteFile(output, source, 0644)
+}
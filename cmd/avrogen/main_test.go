@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCLI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "avrogen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	schemaPath := filepath.Join(dir, "suit.avsc")
+	err = ioutil.WriteFile(schemaPath, []byte(`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalidSchemaPath := filepath.Join(dir, "invalid.avsc")
+	err = ioutil.WriteFile(invalidSchemaPath, []byte(`{"type":"something"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "suit.go")
+	cases := []struct {
+		args           []string
+		expectedCode   int
+		expectedStdout string
+	}{
+		{[]string{"-package", "cards", "-o", output, schemaPath}, 0, ""},
+		{[]string{"-package", "cards", schemaPath}, 0, `SuitSpades Suit = "SPADES"`},
+		{[]string{"-package", "cards"}, 2, ""},
+		{[]string{"-something"}, 2, ""},
+		{[]string{filepath.Join(dir, "missing.avsc")}, 1, ""},
+		{[]string{invalidSchemaPath}, 1, ""},
+	}
+	for i, c := range cases {
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		code := cli(c.args, stdout, stderr)
+		if code != c.expectedCode {
+			t.Errorf("case %d - expected exit code %d, got %d: %s", i, c.expectedCode, code, stderr)
+		}
+		if !strings.Contains(stdout.String(), c.expectedStdout) {
+			t.Errorf("case %d - unexpected output:\n%s", i, stdout)
+		}
+	}
+	source, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(source), "package cards") {
+		t.Errorf("unexpected output:\n%s", source)
+	}
+}
@@ -0,0 +1,68 @@
+// registryavro runs a schema registry compatible with the Confluent API, persisted in a directory or a file.
+//
+// Usage:
+//
+//	registryavro -addr :8081 -dir /var/lib/registry
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/khezen/avro/registryavro"
+)
+
+func main() {
+	os.Exit(cli(os.Args[1:], os.Stderr))
+}
+
+// listenAndServe - serves the registry, replaced in tests
+var listenAndServe = http.ListenAndServe
+
+// cli runs registryavro with the given arguments and returns the exit code
+func cli(args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("registryavro", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var (
+		addr = flags.String("addr", ":8081", "address to listen on")
+		dir  = flags.String("dir", "", "directory persisting the registry, one file per schema and per subject")
+		file = flags.String("file", "", "file persisting the registry")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: registryavro [-addr :8081] [-dir registry | -file registry.json]\n")
+		fmt.Fprintf(stderr, "the registry is kept in memory if neither -dir nor -file is set\n")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 || (*dir != "" && *file != "") {
+		flags.Usage()
+		return 2
+	}
+	err = run(*addr, *dir, *file)
+	if err != nil {
+		fmt.Fprintf(stderr, "registryavro: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func run(addr, dir, file string) error {
+	var storage registryavro.Storage
+	switch {
+	case dir != "":
+		storage = registryavro.NewDirStorage(dir)
+	case file != "":
+		storage = registryavro.NewFileStorage(file)
+	}
+	server, err := registryavro.NewServer(storage)
+	if err != nil {
+		return err
+	}
+	return listenAndServe(addr, server)
+}
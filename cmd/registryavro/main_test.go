@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCLI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registryavro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	invalidFile := filepath.Join(dir, "invalid.json")
+	err = ioutil.WriteFile(invalidFile, []byte(`{`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errClosed := errors.New("errClosed")
+	var served string
+	listenAndServe = func(addr string, handler http.Handler) error {
+		served = addr
+		return errClosed
+	}
+	defer func() {
+		listenAndServe = http.ListenAndServe
+	}()
+	cases := []struct {
+		args         []string
+		expectedCode int
+		expectedAddr string
+	}{
+		{[]string{}, 1, ":8081"},
+		{[]string{"-addr", "localhost:9000", "-dir", filepath.Join(dir, "registry")}, 1, "localhost:9000"},
+		{[]string{"-file", filepath.Join(dir, "registry.json")}, 1, ":8081"},
+		{[]string{"-file", invalidFile}, 1, ""},
+		{[]string{"-dir", dir, "-file", invalidFile}, 2, ""},
+		{[]string{"registry"}, 2, ""},
+		{[]string{"-something"}, 2, ""},
+	}
+	for i, c := range cases {
+		served = ""
+		var stderr bytes.Buffer
+		code := cli(c.args, &stderr)
+		if code != c.expectedCode || served != c.expectedAddr {
+			t.Errorf("case %d - expected %d and %q, got %d and %q: %s", i, c.expectedCode, c.expectedAddr, code, served, stderr.String())
+		}
+	}
+}
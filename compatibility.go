@@ -0,0 +1,377 @@
+package avro
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompatibilityLevel - defines which schemas of the history a new schema must be compatible with.
+// Values match the ones of the Confluent schema registry.
+type CompatibilityLevel string
+
+const (
+	// CompatibilityNone - no check
+	CompatibilityNone CompatibilityLevel = "NONE"
+	// CompatibilityBackward - data written with the latest schema can be read with the new one
+	CompatibilityBackward CompatibilityLevel = "BACKWARD"
+	// CompatibilityBackwardTransitive - data written with any schema of the history can be read with the new one
+	CompatibilityBackwardTransitive CompatibilityLevel = "BACKWARD_TRANSITIVE"
+	// CompatibilityForward - data written with the new schema can be read with the latest one
+	CompatibilityForward CompatibilityLevel = "FORWARD"
+	// CompatibilityForwardTransitive - data written with the new schema can be read with any schema of the history
+	CompatibilityForwardTransitive CompatibilityLevel = "FORWARD_TRANSITIVE"
+	// CompatibilityFull - both backward and forward
+	CompatibilityFull CompatibilityLevel = "FULL"
+	// CompatibilityFullTransitive - both backward and forward transitive
+	CompatibilityFullTransitive CompatibilityLevel = "FULL_TRANSITIVE"
+)
+
+// IncompatibilityType - kind of schema resolution failure
+type IncompatibilityType string
+
+const (
+	// IncompatibilityTypeMismatch - reader and writer types cannot be resolved
+	IncompatibilityTypeMismatch IncompatibilityType = "TYPE_MISMATCH"
+	// IncompatibilityNameMismatch - reader and writer named types have different names and no matching alias
+	IncompatibilityNameMismatch IncompatibilityType = "NAME_MISMATCH"
+	// IncompatibilityFixedSizeMismatch - reader and writer fixed types have different sizes
+	IncompatibilityFixedSizeMismatch IncompatibilityType = "FIXED_SIZE_MISMATCH"
+	// IncompatibilityMissingEnumSymbols - writer enum has symbols unknown to the reader, which has no default
+	IncompatibilityMissingEnumSymbols IncompatibilityType = "MISSING_ENUM_SYMBOLS"
+	// IncompatibilityReaderFieldMissingDefaultValue - reader field is absent from the writer and has no default
+	IncompatibilityReaderFieldMissingDefaultValue IncompatibilityType = "READER_FIELD_MISSING_DEFAULT_VALUE"
+	// IncompatibilityMissingUnionBranch - writer type matches no branch of the reader union
+	IncompatibilityMissingUnionBranch IncompatibilityType = "MISSING_UNION_BRANCH"
+	// IncompatibilityDecimalMismatch - reader and writer decimals have different precisions or scales
+	IncompatibilityDecimalMismatch IncompatibilityType = "DECIMAL_MISMATCH"
+)
+
+// Incompatibility - reason why data written with the writer schema cannot be read with the reader schema
+type Incompatibility struct {
+	Type IncompatibilityType
+	// Path - location of the incompatibility, e.g. "fields[3].type[1].items".
+	// Fields are indexed as in the reader schema and union branches as in the writer schema.
+	Path    string
+	Reader  Schema
+	Writer  Schema
+	Message string
+}
+
+func (i Incompatibility) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%s: %s", i.Type, i.Message)
+	}
+	return fmt.Sprintf("%s: %s at %s", i.Type, i.Message, i.Path)
+}
+
+// CheckCompatibility - returns the reasons why data written with the writer schema cannot be read with the reader schema.
+// Schemas are compatible if no incompatibility is returned.
+func CheckCompatibility(reader, writer Schema) []Incompatibility {
+	cc := compatibilityChecker{
+		visited: make(map[[2]NamedSchema]struct{}),
+	}
+	cc.check(reader, writer, "")
+	return cc.incompatibilities
+}
+
+// CheckCompatibilityLevel - checks the given schema against the history, ordered from the oldest to the latest schema.
+func CheckCompatibilityLevel(level CompatibilityLevel, schema Schema, history []Schema) ([]Incompatibility, error) {
+	var (
+		backward, forward bool
+		transitive        bool
+	)
+	switch level {
+	case CompatibilityNone, "":
+		return nil, nil
+	case CompatibilityBackward:
+		backward = true
+	case CompatibilityBackwardTransitive:
+		backward, transitive = true, true
+	case CompatibilityForward:
+		forward = true
+	case CompatibilityForwardTransitive:
+		forward, transitive = true, true
+	case CompatibilityFull:
+		backward, forward = true, true
+	case CompatibilityFullTransitive:
+		backward, forward, transitive = true, true, true
+	default:
+		return nil, ErrUnsupportedCompatibilityLevel
+	}
+	if len(history) == 0 {
+		return nil, nil
+	}
+	if !transitive {
+		history = history[len(history)-1:]
+	}
+	incompatibilities := make([]Incompatibility, 0)
+	for i := len(history) - 1; i >= 0; i-- {
+		if backward {
+			incompatibilities = append(incompatibilities, CheckCompatibility(schema, history[i])...)
+		}
+		if forward {
+			incompatibilities = append(incompatibilities, CheckCompatibility(history[i], schema)...)
+		}
+	}
+	return incompatibilities, nil
+}
+
+type compatibilityChecker struct {
+	incompatibilities []Incompatibility
+	// visited - pairs of named schemas already being checked, so recursive types terminate
+	visited map[[2]NamedSchema]struct{}
+}
+
+func (cc *compatibilityChecker) report(incompatibilityType IncompatibilityType, path string, reader, writer Schema, message string) {
+	cc.incompatibilities = append(cc.incompatibilities, Incompatibility{
+		Type:    incompatibilityType,
+		Path:    path,
+		Reader:  reader,
+		Writer:  writer,
+		Message: message,
+	})
+}
+
+func (cc *compatibilityChecker) check(reader, writer Schema, path string) {
+	// the schemas with their logical types, to compare decimals
+	readerLogical, writerLogical := reader, writer
+	reader, writer = underlyingSchema(reader), underlyingSchema(writer)
+	if writerUnion, ok := writer.(UnionSchema); ok {
+		for i, branch := range writerUnion {
+			cc.check(readerLogical, branch, indexPath(path, i))
+		}
+		return
+	}
+	if readerUnion, ok := reader.(UnionSchema); ok {
+		branch := matchUnionBranch(readerUnion, writer)
+		if branch == nil {
+			cc.report(IncompatibilityMissingUnionBranch, path, reader, writer, fmt.Sprintf("reader union lacks type %s", writer.TypeName()))
+			return
+		}
+		cc.check(branch, writerLogical, path)
+		return
+	}
+	if !matchSchemas(reader, writer) {
+		if readerNamed, ok := reader.(NamedSchema); ok && sameKind(reader, writer) {
+			cc.report(IncompatibilityNameMismatch, path, reader, writer, fmt.Sprintf("expected %s, found %s", schemaName(readerNamed), schemaName(writer.(NamedSchema))))
+			return
+		}
+		cc.report(IncompatibilityTypeMismatch, path, reader, writer, fmt.Sprintf("reader type %s is not compatible with writer type %s", kindOf(reader), kindOf(writer)))
+		return
+	}
+	if !matchDecimals(readerLogical, writerLogical) {
+		readerPrecision, readerScale, _ := decimalParameters(readerLogical)
+		writerPrecision, writerScale, _ := decimalParameters(writerLogical)
+		cc.report(IncompatibilityDecimalMismatch, path, readerLogical, writerLogical, fmt.Sprintf("expected decimal(%d, %d), found decimal(%d, %d)", readerPrecision, readerScale, writerPrecision, writerScale))
+	}
+	switch r := reader.(type) {
+	case *ArraySchema:
+		cc.check(r.Items, writer.(*ArraySchema).Items, joinPath(path, "items"))
+	case *MapSchema:
+		cc.check(r.Value, writer.(*MapSchema).Value, joinPath(path, "values"))
+	case *FixedSchema:
+		w := writer.(*FixedSchema)
+		if r.Size != w.Size {
+			cc.report(IncompatibilityFixedSizeMismatch, joinPath(path, "size"), reader, writer, fmt.Sprintf("expected %d, found %d", r.Size, w.Size))
+		}
+	case *EnumSchema:
+		cc.checkEnum(r, writer.(*EnumSchema), path)
+	case *RecordSchema:
+		cc.checkRecord(r, writer.(*RecordSchema), path)
+	}
+}
+
+func (cc *compatibilityChecker) checkEnum(reader, writer *EnumSchema, path string) {
+	symbols := make(map[string]struct{}, len(reader.Symbols))
+	for _, symbol := range reader.Symbols {
+		symbols[symbol] = struct{}{}
+	}
+	missing := make([]string, 0)
+	for _, symbol := range writer.Symbols {
+		if _, ok := symbols[symbol]; !ok {
+			missing = append(missing, symbol)
+		}
+	}
+	// unknown symbols are read as the reader default, if any
+	if len(missing) > 0 && reader.Default == "" {
+		cc.report(IncompatibilityMissingEnumSymbols, joinPath(path, "symbols"), reader, writer, fmt.Sprintf("reader lacks symbols [%s]", strings.Join(missing, ", ")))
+	}
+}
+
+func (cc *compatibilityChecker) checkRecord(reader, writer *RecordSchema, path string) {
+	key := [2]NamedSchema{reader, writer}
+	if _, ok := cc.visited[key]; ok {
+		return
+	}
+	cc.visited[key] = struct{}{}
+	for i := range reader.Fields {
+		readerField := &reader.Fields[i]
+		fieldPath := indexPath(joinPath(path, "fields"), i)
+		writerField := lookupWriterField(readerField, writer)
+		if writerField == nil {
+			if readerField.Default == nil {
+				cc.report(IncompatibilityReaderFieldMissingDefaultValue, fieldPath, reader, writer, fmt.Sprintf("field %s has no default value", readerField.Name))
+			}
+			continue
+		}
+		cc.check(readerField.Type, writerField.Type, joinPath(fieldPath, "type"))
+	}
+}
+
+// lookupWriterField finds the writer field matching the reader field by name or alias
+func lookupWriterField(readerField *RecordFieldSchema, writer *RecordSchema) *RecordFieldSchema {
+	for i := range writer.Fields {
+		if writer.Fields[i].Name == readerField.Name {
+			return &writer.Fields[i]
+		}
+	}
+	for _, alias := range readerField.Aliases {
+		for i := range writer.Fields {
+			if writer.Fields[i].Name == alias {
+				return &writer.Fields[i]
+			}
+		}
+	}
+	return nil
+}
+
+// matchUnionBranch returns the first reader branch matching the writer schema,
+// preferring identical types over promotions. The branch keeps its logical type.
+func matchUnionBranch(union UnionSchema, writer Schema) Schema {
+	for _, branch := range union {
+		if sameKind(branch, writer) && matchSchemas(underlyingSchema(branch), writer) {
+			return branch
+		}
+	}
+	for _, branch := range union {
+		if matchSchemas(underlyingSchema(branch), writer) {
+			return branch
+		}
+	}
+	return nil
+}
+
+// matchSchemas reports whether the reader schema matches the writer schema,
+// without checking their children
+func matchSchemas(reader, writer Schema) bool {
+	switch r := reader.(type) {
+	case Type:
+		w, ok := writer.(Type)
+		if !ok {
+			return false
+		}
+		return r == w || isPromotable(w, r)
+	case *ArraySchema, *MapSchema:
+		return sameKind(reader, writer)
+	case *FixedSchema, *EnumSchema, *RecordSchema:
+		if !sameKind(reader, writer) {
+			return false
+		}
+		return matchNames(r.(NamedSchema), writer.(NamedSchema))
+	default:
+		return false
+	}
+}
+
+// matchNames - unqualified names match, or one of the reader aliases matches the writer name
+func matchNames(reader, writer NamedSchema) bool {
+	writerName := unqualifiedName(schemaName(writer))
+	if unqualifiedName(schemaName(reader)) == writerName {
+		return true
+	}
+	for _, alias := range schemaAliases(reader) {
+		if unqualifiedName(alias) == writerName {
+			return true
+		}
+	}
+	return false
+}
+
+func isPromotable(writer, reader Type) bool {
+	switch writer {
+	case TypeInt32:
+		return reader == TypeInt64 || reader == TypeFloat32 || reader == TypeFloat64
+	case TypeInt64:
+		return reader == TypeFloat32 || reader == TypeFloat64
+	case TypeFloat32:
+		return reader == TypeFloat64
+	case TypeString:
+		return reader == TypeBytes
+	case TypeBytes:
+		return reader == TypeString
+	default:
+		return false
+	}
+}
+
+// underlyingSchema resolves references and strips logical types, which don't take part in schema resolution
+func underlyingSchema(schema Schema) Schema {
+	switch t := schema.(type) {
+	case *ReferenceSchema:
+		return t.Schema
+	case *DerivedPrimitiveSchema:
+		return t.Type
+	default:
+		return schema
+	}
+}
+
+func sameKind(a, b Schema) bool {
+	return kindOf(a) == kindOf(b)
+}
+
+// kindOf returns the type of the schema, ignoring logical types
+func kindOf(schema Schema) Type {
+	switch t := underlyingSchema(schema).(type) {
+	case Type:
+		return t
+	case *FixedSchema:
+		return TypeFixed
+	case nil:
+		return ""
+	default:
+		return t.TypeName()
+	}
+}
+
+func schemaName(schema NamedSchema) string {
+	switch t := schema.(type) {
+	case *RecordSchema:
+		return t.Name
+	case *EnumSchema:
+		return t.Name
+	case *FixedSchema:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+func schemaAliases(schema NamedSchema) []string {
+	switch t := schema.(type) {
+	case *RecordSchema:
+		return t.Aliases
+	case *EnumSchema:
+		return t.Aliases
+	case *FixedSchema:
+		return t.Aliases
+	default:
+		return nil
+	}
+}
+
+func unqualifiedName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
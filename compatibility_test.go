@@ -0,0 +1,135 @@
+package avro
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustParseSchema(t *testing.T, schemaBytes string) Schema {
+	var anySchema AnySchema
+	err := json.Unmarshal([]byte(schemaBytes), &anySchema)
+	if err != nil {
+		t.Fatalf("%s: %v", schemaBytes, err)
+	}
+	return anySchema.Schema()
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	cases := []struct {
+		reader, writer string
+		expected       []IncompatibilityType
+		expectedPaths  []string
+	}{
+		{`"long"`, `"int"`, nil, nil},
+		{`"double"`, `"float"`, nil, nil},
+		{`"bytes"`, `"string"`, nil, nil},
+		{`"string"`, `"bytes"`, nil, nil},
+		{`"int"`, `"long"`, []IncompatibilityType{IncompatibilityTypeMismatch}, []string{""}},
+		{`{"type":"long","logicalType":"timestamp"}`, `"int"`, nil, nil},
+		{`["null","long"]`, `"int"`, nil, nil},
+		{`["null","long"]`, `["null","int"]`, nil, nil},
+		{`"long"`, `["null","int"]`, []IncompatibilityType{IncompatibilityTypeMismatch}, []string{"[0]"}},
+		{`["null","string"]`, `"int"`, []IncompatibilityType{IncompatibilityMissingUnionBranch}, []string{""}},
+		{`{"type":"array","items":"long"}`, `{"type":"array","items":"int"}`, nil, nil},
+		{`{"type":"map","values":"int"}`, `{"type":"map","values":"long"}`, []IncompatibilityType{IncompatibilityTypeMismatch}, []string{"values"}},
+		{`{"type":"fixed","name":"md5","size":16}`, `{"type":"fixed","name":"md5","size":12}`, []IncompatibilityType{IncompatibilityFixedSizeMismatch}, []string{"size"}},
+		{`{"type":"fixed","name":"hash","size":16}`, `{"type":"fixed","name":"md5","size":16}`, []IncompatibilityType{IncompatibilityNameMismatch}, []string{""}},
+		{`{"type":"fixed","name":"hash","aliases":["crypto.md5"],"size":16}`, `{"type":"fixed","namespace":"crypto","name":"md5","size":16}`, nil, nil},
+		{`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS","DIAMONDS","CLUBS"]}`, `{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]}`, nil, nil},
+		{`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]}`, `{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS","CLUBS"]}`, []IncompatibilityType{IncompatibilityMissingEnumSymbols}, []string{"symbols"}},
+		{`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"],"default":"SPADES"}`, `{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS","CLUBS"]}`, nil, nil},
+		{`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`, `{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`, nil, nil},
+		{`{"type":"bytes","logicalType":"decimal","precision":5,"scale":3}`, `{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`, []IncompatibilityType{IncompatibilityDecimalMismatch}, []string{""}},
+		{`{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}`, `{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`, []IncompatibilityType{IncompatibilityDecimalMismatch}, []string{""}},
+		{`"bytes"`, `{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`, nil, nil},
+		{`{"type":"bytes","logicalType":"decimal","precision":5,"scale":3}`, `["null",{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}]`, []IncompatibilityType{IncompatibilityTypeMismatch, IncompatibilityDecimalMismatch}, []string{"[0]", "[1]"}},
+		{`["null",{"type":"bytes","logicalType":"decimal","precision":5,"scale":3}]`, `["null",{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}]`, []IncompatibilityType{IncompatibilityDecimalMismatch}, []string{"[1]"}},
+		{
+			`{"type":"fixed","name":"amount","size":4,"logicalType":"decimal","precision":5,"scale":3}`,
+			`{"type":"fixed","name":"amount","size":4,"logicalType":"decimal","precision":4,"scale":2}`,
+			[]IncompatibilityType{IncompatibilityDecimalMismatch},
+			[]string{""},
+		},
+		{
+			`{"type":"record","name":"post","fields":[{"name":"id","type":"long"},{"name":"title","type":"string"},{"name":"author","type":["null","string"],"default":null}]}`,
+			`{"type":"record","name":"post","fields":[{"name":"id","type":"int"},{"name":"title","type":"string"},{"name":"body","type":"bytes"}]}`,
+			nil,
+			nil,
+		},
+		{
+			`{"type":"record","name":"post","fields":[{"name":"id","type":"long"},{"name":"author","type":"string"}]}`,
+			`{"type":"record","name":"post","fields":[{"name":"id","type":"int"}]}`,
+			[]IncompatibilityType{IncompatibilityReaderFieldMissingDefaultValue},
+			[]string{"fields[1]"},
+		},
+		{
+			`{"type":"record","name":"article","aliases":["post"],"fields":[{"name":"headline","aliases":["title"],"type":"string"}]}`,
+			`{"type":"record","name":"post","fields":[{"name":"title","type":"string"}]}`,
+			nil,
+			nil,
+		},
+		{
+			`{"type":"record","name":"LongList","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"]}]}`,
+			`{"type":"record","name":"LongList","fields":[{"name":"value","type":"int"},{"name":"next","type":["null","LongList"]}]}`,
+			nil,
+			nil,
+		},
+		{
+			`{"type":"record","name":"LongList","fields":[{"name":"value","type":"int"},{"name":"next","type":["null","LongList"]}]}`,
+			`{"type":"record","name":"LongList","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"]}]}`,
+			[]IncompatibilityType{IncompatibilityTypeMismatch},
+			[]string{"fields[0].type"},
+		},
+		{
+			`{"type":"record","name":"post","fields":[{"name":"tags","type":{"type":"array","items":["null",{"type":"record","name":"tag","fields":[{"name":"label","type":"string"},{"name":"weight","type":"int"}]}]}}]}`,
+			`{"type":"record","name":"post","fields":[{"name":"tags","type":{"type":"array","items":["null",{"type":"record","name":"tag","fields":[{"name":"label","type":"string"}]}]}}]}`,
+			[]IncompatibilityType{IncompatibilityReaderFieldMissingDefaultValue},
+			[]string{"fields[0].type.items[1].fields[1]"},
+		},
+	}
+	for i, c := range cases {
+		incompatibilities := CheckCompatibility(mustParseSchema(t, c.reader), mustParseSchema(t, c.writer))
+		if len(incompatibilities) != len(c.expected) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expected, incompatibilities)
+			continue
+		}
+		for j := range incompatibilities {
+			if incompatibilities[j].Type != c.expected[j] || incompatibilities[j].Path != c.expectedPaths[j] {
+				t.Errorf("case %d - expected %s at %q, got %s", i, c.expected[j], c.expectedPaths[j], incompatibilities[j])
+			}
+		}
+	}
+}
+
+func TestCheckCompatibilityLevel(t *testing.T) {
+	history := []Schema{
+		mustParseSchema(t, `{"type":"record","name":"post","fields":[{"name":"id","type":"int"}]}`),
+		mustParseSchema(t, `{"type":"record","name":"post","fields":[{"name":"id","type":"int"},{"name":"title","type":"string","default":""}]}`),
+	}
+	schema := mustParseSchema(t, `{"type":"record","name":"post","fields":[{"name":"id","type":"long"},{"name":"title","type":"string"}]}`)
+	cases := []struct {
+		level    CompatibilityLevel
+		expected int
+	}{
+		{CompatibilityNone, 0},
+		{CompatibilityBackward, 0},
+		{CompatibilityBackwardTransitive, 1},
+		{CompatibilityForward, 1},
+		{CompatibilityForwardTransitive, 2},
+		{CompatibilityFull, 1},
+		{CompatibilityFullTransitive, 3},
+	}
+	for _, c := range cases {
+		incompatibilities, err := CheckCompatibilityLevel(c.level, schema, history)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(incompatibilities) != c.expected {
+			t.Errorf("%s - expected %d incompatibilities, got %v", c.level, c.expected, incompatibilities)
+		}
+	}
+	_, err := CheckCompatibilityLevel(CompatibilityLevel("something"), schema, history)
+	if err != ErrUnsupportedCompatibilityLevel {
+		t.Errorf("expected %v, got %v", ErrUnsupportedCompatibilityLevel, err)
+	}
+}
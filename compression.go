@@ -1,5 +1,21 @@
 package avro
 
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	dsnetbzip2 "github.com/dsnet/compress/bzip2"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
 const (
 	// CompressionNull - The "null" codec simply passes through data uncompressed.
 	CompressionNull = "null"
@@ -10,4 +26,161 @@ const (
 	// CompressionSnappy - The "snappy" codec uses Google's Snappy compression library.
 	// Each compressed block is followed by the 4-byte, big-endian CRC32 checksum of the uncompressed data in the block.
 	CompressionSnappy = "snappy"
+	// CompressionZstandard - The "zstandard" codec uses Facebook's Zstandard compression library.
+	CompressionZstandard = "zstandard"
+	// CompressionBzip2 - The "bzip2" codec uses the bzip2 compression library.
+	CompressionBzip2 = "bzip2"
+	// CompressionXZ - The "xz" codec uses the xz compression library.
+	CompressionXZ = "xz"
 )
+
+// Codec - compresses and decompresses the blocks of object container files
+type Codec interface {
+	Compress(block []byte) ([]byte, error)
+	Decompress(block []byte) ([]byte, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		CompressionNull:      nullCodec{},
+		CompressionDeflate:   deflateCodec{},
+		CompressionSnappy:    snappyCodec{},
+		CompressionZstandard: zstandardCodec{},
+		CompressionBzip2:     bzip2Codec{},
+		CompressionXZ:        xzCodec{},
+	}
+)
+
+// RegisterCodec - makes the codec available under the given compression name,
+// replacing the codec previously registered under this name, if any.
+func RegisterCodec(name string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[name] = codec
+}
+
+// LookupCodec - returns the codec registered under the given compression name, "null" if empty.
+func LookupCodec(name string) (Codec, error) {
+	if name == "" {
+		name = CompressionNull
+	}
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, ErrUnsupportedCompression
+	}
+	return codec, nil
+}
+
+type nullCodec struct{}
+
+func (nullCodec) Compress(block []byte) ([]byte, error) {
+	return block, nil
+}
+
+func (nullCodec) Decompress(block []byte) ([]byte, error) {
+	return block, nil
+}
+
+type deflateCodec struct{}
+
+func (deflateCodec) Compress(block []byte) ([]byte, error) {
+	return compress(block, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, flate.DefaultCompression)
+	})
+}
+
+func (deflateCodec) Decompress(block []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(block))
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+type snappyCodec struct{}
+
+func (snappyCodec) Compress(block []byte) ([]byte, error) {
+	compressed := snappy.Encode(nil, block)
+	var checksum [4]byte
+	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
+	return append(compressed, checksum[:]...), nil
+}
+
+func (snappyCodec) Decompress(block []byte) ([]byte, error) {
+	if len(block) < 4 {
+		return nil, ErrInvalidEncoding
+	}
+	compressed, checksum := block[:len(block)-4], block[len(block)-4:]
+	decompressed, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(decompressed) != binary.BigEndian.Uint32(checksum) {
+		return nil, ErrInvalidEncoding
+	}
+	return decompressed, nil
+}
+
+type zstandardCodec struct{}
+
+func (zstandardCodec) Compress(block []byte) ([]byte, error) {
+	return compress(block, func(w io.Writer) (io.WriteCloser, error) {
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	})
+}
+
+func (zstandardCodec) Decompress(block []byte) ([]byte, error) {
+	r, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return r.DecodeAll(block, nil)
+}
+
+type bzip2Codec struct{}
+
+func (bzip2Codec) Compress(block []byte) ([]byte, error) {
+	return compress(block, func(w io.Writer) (io.WriteCloser, error) {
+		return dsnetbzip2.NewWriter(w, nil)
+	})
+}
+
+func (bzip2Codec) Decompress(block []byte) ([]byte, error) {
+	return ioutil.ReadAll(bzip2.NewReader(bytes.NewReader(block)))
+}
+
+type xzCodec struct{}
+
+func (xzCodec) Compress(block []byte) ([]byte, error) {
+	return compress(block, func(w io.Writer) (io.WriteCloser, error) {
+		return xz.NewWriter(w)
+	})
+}
+
+func (xzCodec) Decompress(block []byte) ([]byte, error) {
+	r, err := xz.NewReader(bytes.NewReader(block))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// compress writes the block through the compressing writer returned by newWriter
+func compress(block []byte, newWriter func(io.Writer) (io.WriteCloser, error)) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w, err := newWriter(buf)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(block)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
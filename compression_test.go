@@ -0,0 +1,118 @@
+package avro
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestCodecs(t *testing.T) {
+	block := bytes.Repeat([]byte("avro object container file block "), 64)
+	cases := []string{
+		"",
+		CompressionNull,
+		CompressionDeflate,
+		CompressionSnappy,
+		CompressionZstandard,
+		CompressionBzip2,
+		CompressionXZ,
+	}
+	for i, name := range cases {
+		codec, err := LookupCodec(name)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		compressed, err := codec.Compress(block)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		decompressed, err := codec.Decompress(compressed)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if !bytes.Equal(decompressed, block) {
+			t.Errorf("case %d - block altered by %s codec", i, name)
+		}
+	}
+	_, err := LookupCodec("lz4")
+	if err != ErrUnsupportedCompression {
+		t.Errorf("expected %v, got %v", ErrUnsupportedCompression, err)
+	}
+}
+
+func TestSnappyChecksum(t *testing.T) {
+	codec, err := LookupCodec(CompressionSnappy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := codec.Compress([]byte("snappy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed[len(compressed)-1] ^= 0xff
+	_, err = codec.Decompress(compressed)
+	if err == nil {
+		t.Errorf("expected checksum mismatch to fail")
+	}
+}
+
+type reverseCodec struct{}
+
+func (reverseCodec) reverse(block []byte) []byte {
+	reversed := make([]byte, len(block))
+	for i, b := range block {
+		reversed[len(block)-1-i] = b
+	}
+	return reversed
+}
+
+func (c reverseCodec) Compress(block []byte) ([]byte, error) {
+	return c.reverse(block), nil
+}
+
+func (c reverseCodec) Decompress(block []byte) ([]byte, error) {
+	return c.reverse(block), nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("reverse", reverseCodec{})
+	buf := new(bytes.Buffer)
+	ocfw, err := NewOCFWriter(buf, OCFWriterConfig{
+		Schema:      mustParseSchema(t, ocfTestSchema),
+		Compression: "reverse",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := ocfTestRecords(3)
+	err = ocfw.Append(records...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ocfw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ocfr, err := NewOCFReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ocfr.Compression() != "reverse" {
+		t.Errorf("expected compression reverse, got %s", ocfr.Compression())
+	}
+	block, err := ocfr.NextBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(block, records) {
+		t.Errorf("expected %v, got %v", records, block)
+	}
+	_, err = ocfr.NextBlock()
+	if err != io.EOF {
+		t.Errorf("expected %v, got %v", io.EOF, err)
+	}
+}
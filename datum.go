@@ -0,0 +1,314 @@
+package avro
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// Go native representation of AVRO data, as used by the binary and JSON encodings:
+//
+//	null                    nil
+//	boolean                 bool
+//	int                     int32
+//	long                    int64
+//	float                   float32
+//	double                  float64
+//	bytes, fixed            []byte
+//	string, enum            string
+//	array                   []interface{}
+//	map, record             map[string]interface{}
+//	union                   nil, or map[string]interface{} with a single key, the branch name, mapped to the value
+//	date                    time.Time
+//	[local-]timestamp-*     time.Time
+//	timestamp               time.Time
+//	time-*                  time.Duration
+//	decimal                 *big.Rat
+//
+// Branch names are type names for unnamed types, fullnames for named types
+// and "<type>.<logicalType>" for logical types, e.g. "bytes.decimal".
+// Unknown logical types are represented as their underlying type.
+
+var epoch = time.Unix(0, 0).UTC()
+
+const secondsPerDay = 24 * 3600
+
+// primitiveDatum translates the native representation of a logical type to the one of its underlying type.
+// The datum is returned as is if it is already represented by the underlying type.
+func primitiveDatum(schema *DerivedPrimitiveSchema, datum interface{}) interface{} {
+	switch schema.LogicalType {
+	case LogicalTypeDate:
+		if t, ok := datum.(time.Time); ok {
+			// seconds rather than durations, which overflow about 292 years from the epoch
+			seconds := t.Unix()
+			days := seconds / secondsPerDay
+			if seconds%secondsPerDay < 0 {
+				days--
+			}
+			datum = days
+		}
+	case LogicalTypeTimestamp:
+		if t, ok := datum.(time.Time); ok {
+			datum = t.Unix()
+		}
+	case LogicalTypeTimestampMillis, LogicalTypeLocalTimestampMillis:
+		if t, ok := datum.(time.Time); ok {
+			datum = timestampDatum(t, schema.LogicalType == LogicalTypeLocalTimestampMillis, time.Millisecond)
+		}
+	case LogicalTypeTimestampMicros, LogicalTypeLocalTimestampMicros:
+		if t, ok := datum.(time.Time); ok {
+			datum = timestampDatum(t, schema.LogicalType == LogicalTypeLocalTimestampMicros, time.Microsecond)
+		}
+	case LogicalTypeTimeMillis:
+		if d, ok := datum.(time.Duration); ok {
+			datum = int64(d / time.Millisecond)
+		}
+	case LogicalTypeTimeMicros:
+		if d, ok := datum.(time.Duration); ok {
+			datum = int64(d / time.Microsecond)
+		}
+	case LogicalTypeDecimal:
+		if r, ok := ratDatum(datum); ok {
+			datum = decimalBytes(r, scaleOf(schema.Scale))
+		}
+	}
+	return datum
+}
+
+// timestampDatum returns the number of units from the unix epoch.
+// local timestamps are computed from the wall clock of t, regardless of its location.
+func timestampDatum(t time.Time, local bool, unit time.Duration) int64 {
+	if local {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	// avoids the overflow of UnixNano for dates far from the epoch
+	return t.Unix()*int64(time.Second/unit) + int64(t.Nanosecond())/int64(unit)
+}
+
+// fixedDatum returns the bytes of the datum, decimals being sign extended to the size of the fixed
+func fixedDatum(schema *FixedSchema, datum interface{}) ([]byte, bool) {
+	if schema.LogicalType == LogicalTypeDecimal {
+		if r, ok := ratDatum(datum); ok {
+			b := decimalBytes(r, scaleOf(schema.Scale))
+			if len(b) > schema.Size {
+				return nil, false
+			}
+			// sign extension
+			padding := byte(0)
+			if b[0]&0x80 != 0 {
+				padding = 0xff
+			}
+			for len(b) < schema.Size {
+				b = append([]byte{padding}, b...)
+			}
+			datum = b
+		}
+	}
+	b, ok := bytesDatum(datum)
+	if !ok || len(b) != schema.Size {
+		return nil, false
+	}
+	return b, true
+}
+
+// nativeDatum translates the datum of the underlying type to the native representation of the logical type
+func nativeDatum(schema *DerivedPrimitiveSchema, datum interface{}) interface{} {
+	switch schema.LogicalType {
+	case LogicalTypeDate:
+		days, _ := int64Datum(datum)
+		return time.Unix(days*secondsPerDay, 0).UTC()
+	case LogicalTypeTimestamp:
+		seconds, _ := int64Datum(datum)
+		return time.Unix(seconds, 0).UTC()
+	case LogicalTypeTimestampMillis, LogicalTypeLocalTimestampMillis:
+		return timestampTime(datum.(int64), time.Millisecond)
+	case LogicalTypeTimestampMicros, LogicalTypeLocalTimestampMicros:
+		return timestampTime(datum.(int64), time.Microsecond)
+	case LogicalTypeTimeMillis:
+		return time.Duration(datum.(int32)) * time.Millisecond
+	case LogicalTypeTimeMicros:
+		return time.Duration(datum.(int64)) * time.Microsecond
+	case LogicalTypeDecimal:
+		return decimalRat(datum.([]byte), scaleOf(schema.Scale))
+	default:
+		return datum
+	}
+}
+
+// timestampTime returns the UTC time n units after the unix epoch
+func timestampTime(n int64, unit time.Duration) time.Time {
+	perSecond := int64(time.Second / unit)
+	sec, frac := n/perSecond, n%perSecond
+	if frac < 0 {
+		sec--
+		frac += perSecond
+	}
+	return time.Unix(sec, frac*int64(unit)).UTC()
+}
+
+// nativeFixedDatum translates the bytes of a fixed to their native representation
+func nativeFixedDatum(schema *FixedSchema, b []byte) interface{} {
+	if schema.LogicalType == LogicalTypeDecimal {
+		return decimalRat(b, scaleOf(schema.Scale))
+	}
+	return b
+}
+
+func scaleOf(scale *int) int {
+	if scale == nil {
+		return 0
+	}
+	return *scale
+}
+
+// decimalBytes returns the two's complement big-endian representation of the unscaled value of r
+func decimalBytes(r *big.Rat, scale int) []byte {
+	unscaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	n := new(big.Int).Quo(unscaled.Num(), unscaled.Denom())
+	var b []byte
+	if n.Sign() >= 0 {
+		// leave room for the sign bit
+		length := n.BitLen()/8 + 1
+		b = n.Bytes()
+		for len(b) < length {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	length := new(big.Int).Not(n).BitLen()/8 + 1
+	b = new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), uint(8*length)), n).Bytes()
+	for len(b) < length {
+		b = append([]byte{0xff}, b...)
+	}
+	return b
+}
+
+// decimalRat reads a two's complement big-endian unscaled value
+func decimalRat(b []byte, scale int) *big.Rat {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return new(big.Rat).SetFrac(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+}
+
+// unionBranch returns the index of the branch and the value of a datum wrapped as {"<branch name>": value}
+func unionBranch(union UnionSchema, datum interface{}, namespace string) (int, interface{}, bool) {
+	wrapper, ok := datum.(map[string]interface{})
+	if !ok || len(wrapper) != 1 {
+		return -1, nil, false
+	}
+	for name, value := range wrapper {
+		if i := lookupBranch(union, name, namespace); i >= 0 {
+			return i, value, true
+		}
+	}
+	return -1, nil, false
+}
+
+// fieldDatum returns the value of the field in the record, or its default value if absent
+func fieldDatum(field RecordFieldSchema, record map[string]interface{}, namespace string) (interface{}, error) {
+	value, ok := record[field.Name]
+	if ok {
+		return value, nil
+	}
+	if field.Default == nil {
+		return nil, ErrInvalidDatum
+	}
+	return defaultDatum(field.Type, *field.Default, namespace)
+}
+
+func arrayDatum(datum interface{}) ([]interface{}, bool) {
+	items, ok := datum.([]interface{})
+	if ok {
+		return items, true
+	}
+	value := reflect.ValueOf(datum)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, false
+	}
+	items = make([]interface{}, value.Len())
+	for i := range items {
+		items[i] = value.Index(i).Interface()
+	}
+	return items, true
+}
+
+func mapDatum(datum interface{}) (map[string]interface{}, bool) {
+	values, ok := datum.(map[string]interface{})
+	if ok {
+		return values, true
+	}
+	value := reflect.ValueOf(datum)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	values = make(map[string]interface{}, value.Len())
+	iter := value.MapRange()
+	for iter.Next() {
+		values[iter.Key().String()] = iter.Value().Interface()
+	}
+	return values, true
+}
+
+func int64Datum(datum interface{}) (int64, bool) {
+	value := reflect.ValueOf(datum)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(value.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+func float64Datum(datum interface{}) (float64, bool) {
+	value := reflect.ValueOf(datum)
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		n, ok := int64Datum(datum)
+		return float64(n), ok
+	}
+}
+
+func ratDatum(datum interface{}) (*big.Rat, bool) {
+	switch r := datum.(type) {
+	case *big.Rat:
+		return r, true
+	case big.Rat:
+		return &r, true
+	default:
+		return nil, false
+	}
+}
+
+func stringDatum(datum interface{}) (string, bool) {
+	if s, ok := datum.(string); ok {
+		return s, true
+	}
+	value := reflect.ValueOf(datum)
+	if value.Kind() == reflect.String {
+		return value.String(), true
+	}
+	return "", false
+}
+
+func bytesDatum(datum interface{}) ([]byte, bool) {
+	if b, ok := datum.([]byte); ok {
+		return b, true
+	}
+	value := reflect.ValueOf(datum)
+	if value.Kind() == reflect.Array && value.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(b), value)
+		return b, true
+	}
+	return nil, false
+}
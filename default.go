@@ -0,0 +1,24 @@
+package avro
+
+import (
+	"encoding/json"
+)
+
+// ValidateDefault - checks the JSON default value of a field against the schema of the field.
+// As stated by the spec, the default value of a union must match its first branch,
+// bytes and fixed defaults are strings whose code points are the byte values
+// and records, maps and arrays are checked recursively. ErrInvalidDatum is returned if the default doesn't match.
+func ValidateDefault(schema Schema, rawDefault json.RawMessage) error {
+	_, err := defaultDatum(schema, rawDefault, "")
+	return err
+}
+
+// defaultDatum translates the JSON default value of a field into its native representation.
+// As stated by the spec, the default value of a union is the one of its first branch.
+func defaultDatum(schema Schema, rawDefault json.RawMessage, namespace string) (interface{}, error) {
+	value, err := unmarshalJSONValue(rawDefault)
+	if err != nil {
+		return nil, ErrInvalidDatum
+	}
+	return jsonReader{defaults: true, err: ErrInvalidDatum}.decode(schema, value, namespace)
+}
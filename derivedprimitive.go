@@ -1,72 +1,103 @@
 package avro
 
-import "github.com/valyala/fastjson"
+import (
+	"math"
+	"strconv"
 
-// DerivedPrimitiveSchema -
+	"github.com/valyala/fastjson"
+)
+
+// DerivedPrimitiveSchema - primitive type written as an object, annotated by a logical type, a doc or custom properties
 type DerivedPrimitiveSchema struct {
 	Type          Type        `json:"type"`
 	Documentation string      `json:"doc,omitempty"`
-	LogicalType   LogicalType `json:"logicalType"`
+	LogicalType   LogicalType `json:"logicalType,omitempty"`
 	Precision     *int        `json:"precision,omitempty"`
 	Scale         *int        `json:"scale,omitempty"`
+	Properties    Properties  `json:"-"`
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the type
+func (t *DerivedPrimitiveSchema) MarshalJSON() ([]byte, error) {
+	type derivedPrimitiveSchema DerivedPrimitiveSchema
+	return marshalWithProperties((*derivedPrimitiveSchema)(t), t.Properties, logicalTypeAttributes(derivedAttributes, t.LogicalType))
 }
 
-// TypeName -
+// TypeName - the logical type, or the underlying type if the logical type is unknown
 func (t *DerivedPrimitiveSchema) TypeName() Type {
+	if !t.LogicalType.isKnown() {
+		return t.Type
+	}
 	return Type(t.LogicalType)
 }
 
-func translateValue2DerivedPrimitiveSchema(typeName Type, value *fastjson.Value) (Schema, error) {
-	if !value.Exists("logicalType") {
-		return nil, ErrInvalidSchema
+// translateValue2DerivedPrimitiveSchema - the object form of a primitive type is the type itself,
+// unless it has a logical type, a doc or custom properties, such as the connect.* properties of Kafka Connect
+func translateValue2DerivedPrimitiveSchema(typeName Type, value *fastjson.Value, path string) (Schema, error) {
+	logicalType, err := translateValueToLogicalType(value, path)
+	if err != nil {
+		return nil, err
 	}
-	doc, err := translateValueToDocumentation(value)
+	doc, err := translateValueToDocumentation(value, path)
 	if err != nil {
 		return nil, err
 	}
-	logicalType := LogicalType(value.GetStringBytes("logicalType"))
-	switch logicalType {
-	case LogicalTypeDate, LogicalTypeTime, LogicalTypeTimestamp:
-		switch typeName {
-		case TypeInt32, TypeInt64:
-			return &DerivedPrimitiveSchema{
-				Type:          typeName,
-				Documentation: doc,
-				LogicalType:   logicalType,
-			}, nil
-		default:
-			return nil, ErrInvalidSchema
-		}
-	case LogicalTypeDecimal:
-		if !value.Exists("precision") {
-			return nil, ErrInvalidSchema
-		}
-		precision, err := value.Get("precision").Int()
+	schema := &DerivedPrimitiveSchema{
+		Type:          typeName,
+		Documentation: doc,
+		LogicalType:   logicalType,
+		Properties:    translateValueToProperties(value, logicalTypeAttributes(derivedAttributes, logicalType)),
+	}
+	if logicalType == "" && doc == "" && len(schema.Properties) == 0 {
+		return typeName, nil
+	}
+	if !logicalType.isKnown() {
+		return schema, nil
+	}
+	if !logicalType.annotates(typeName) {
+		return nil, schemaError(joinPath(path, "logicalType"), value.Get("logicalType"), ErrInvalidSchema, "logical type doesn't annotate "+string(typeName))
+	}
+	if logicalType == LogicalTypeDecimal {
+		schema.Precision, schema.Scale, err = translateValueToDecimalParameters(value, path, math.MaxInt32)
 		if err != nil {
-			return nil, ErrInvalidSchema
+			return nil, err
 		}
-		if precision < 0 {
-			return nil, ErrInvalidSchema
-		}
-		var scale *int
-		if value.Exists("scale") {
-			scaleInt, err := value.Get("scale").Int()
-			if err != nil {
-				return nil, ErrInvalidSchema
-			}
-			if scaleInt < 0 {
-				return nil, ErrInvalidSchema
-			}
-			scale = &scaleInt
+	}
+	return schema, nil
+}
+
+func translateValueToLogicalType(value *fastjson.Value, path string) (LogicalType, error) {
+	if !value.Exists("logicalType") {
+		return "", nil
+	}
+	logicalType, err := value.Get("logicalType").StringBytes()
+	if err != nil || len(logicalType) == 0 {
+		return "", schemaError(joinPath(path, "logicalType"), value.Get("logicalType"), ErrInvalidSchema, "logicalType must be a non empty string")
+	}
+	return LogicalType(logicalType), nil
+}
+
+// translateValueToDecimalParameters - scale is optional, precision must be positive
+// and within maxPrecision, scale within precision
+func translateValueToDecimalParameters(value *fastjson.Value, path string, maxPrecision int) (precision, scale *int, err error) {
+	if !value.Exists("precision") {
+		return nil, nil, schemaError(path, nil, ErrInvalidSchema, "missing precision")
+	}
+	precisionInt, err := value.Get("precision").Int()
+	if err != nil || precisionInt <= 0 || precisionInt > maxPrecision {
+		return nil, nil, schemaError(joinPath(path, "precision"), value.Get("precision"), ErrInvalidSchema, "precision must be a positive integer within "+strconv.Itoa(maxPrecision))
+	}
+	if value.Exists("scale") {
+		scaleInt, err := value.Get("scale").Int()
+		if err != nil || scaleInt < 0 || scaleInt > precisionInt {
+			return nil, nil, schemaError(joinPath(path, "scale"), value.Get("scale"), ErrInvalidSchema, "scale must be a non negative integer within the precision")
 		}
-		return &DerivedPrimitiveSchema{
-			Type:          typeName,
-			Documentation: doc,
-			LogicalType:   logicalType,
-			Precision:     &precision,
-			Scale:         scale,
-		}, nil
-	default:
-		return nil, ErrInvalidSchema
+		scale = &scaleInt
 	}
+	return &precisionInt, scale, nil
+}
+
+// maxFixedDecimalPrecision returns the number of base 10 digits a fixed of the given size can hold
+func maxFixedDecimalPrecision(size int) int {
+	return int(math.Floor(math.Log10(2) * float64(8*size-1)))
 }
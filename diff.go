@@ -0,0 +1,394 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// ChangeType - kind of difference between two versions of a schema
+type ChangeType string
+
+const (
+	// ChangeFieldAdded - the field is absent from the old record
+	ChangeFieldAdded ChangeType = "FIELD_ADDED"
+	// ChangeFieldRemoved - the field is absent from the new record
+	ChangeFieldRemoved ChangeType = "FIELD_REMOVED"
+	// ChangeFieldRenamed - the new field is aliased by the name of the old field
+	ChangeFieldRenamed ChangeType = "FIELD_RENAMED"
+	// ChangeTypeChanged - the type changed, e.g. int to long, record to enum or fixed size
+	ChangeTypeChanged ChangeType = "TYPE_CHANGED"
+	// ChangeNullableAdded - the type became a union with null
+	ChangeNullableAdded ChangeType = "NULLABLE_ADDED"
+	// ChangeNullableRemoved - null was removed from the union
+	ChangeNullableRemoved ChangeType = "NULLABLE_REMOVED"
+	// ChangeDefaultChanged - the default of the field or enum changed
+	ChangeDefaultChanged ChangeType = "DEFAULT_CHANGED"
+	// ChangeDocChanged - the documentation changed
+	ChangeDocChanged ChangeType = "DOC_CHANGED"
+	// ChangeEnumSymbolsAdded - the enum has new symbols
+	ChangeEnumSymbolsAdded ChangeType = "ENUM_SYMBOLS_ADDED"
+	// ChangeEnumSymbolsRemoved - symbols were removed from the enum
+	ChangeEnumSymbolsRemoved ChangeType = "ENUM_SYMBOLS_REMOVED"
+	// ChangePrecisionChanged - the precision of the decimal changed
+	ChangePrecisionChanged ChangeType = "PRECISION_CHANGED"
+	// ChangeScaleChanged - the scale of the decimal changed
+	ChangeScaleChanged ChangeType = "SCALE_CHANGED"
+)
+
+// Change - difference between the old and the new version of a schema
+type Change struct {
+	Type ChangeType `json:"type"`
+	// Path - location of the change, e.g. "fields[3].type[1].items".
+	// Fields are indexed as in the new schema, except removed fields which are indexed as in the old schema.
+	Path string `json:"path"`
+	// Old - value in the old schema: schema, field name, default, documentation, symbols or precision
+	Old interface{} `json:"old,omitempty"`
+	// New - value in the new schema
+	New     interface{} `json:"new,omitempty"`
+	Message string      `json:"message"`
+}
+
+func (c Change) String() string {
+	if c.Path == "" {
+		return fmt.Sprintf("%s: %s", c.Type, c.Message)
+	}
+	return fmt.Sprintf("%s: %s at %s", c.Type, c.Message, c.Path)
+}
+
+// Diff - returns the changes between the old and the new version of a schema.
+// Fields are matched by name, then by the aliases of the new fields.
+// Schemas are identical if no change is returned.
+func Diff(oldSchema, newSchema Schema) []Change {
+	d := differ{
+		visited: make(map[[2]NamedSchema]struct{}),
+	}
+	d.diff(oldSchema, newSchema, "")
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+	// visited - pairs of named schemas already being compared, so recursive types terminate
+	visited map[[2]NamedSchema]struct{}
+}
+
+func (d *differ) report(changeType ChangeType, path string, oldValue, newValue interface{}, message string) {
+	d.changes = append(d.changes, Change{
+		Type:    changeType,
+		Path:    path,
+		Old:     oldValue,
+		New:     newValue,
+		Message: message,
+	})
+}
+
+func (d *differ) diff(oldSchema, newSchema Schema, path string) {
+	oldSchema, newSchema = referencedSchema(oldSchema), referencedSchema(newSchema)
+	_, oldIsUnion := oldSchema.(UnionSchema)
+	newUnion, newIsUnion := newSchema.(UnionSchema)
+	if oldIsUnion || newIsUnion {
+		oldBranches, oldNullable := nonNullBranches(oldSchema)
+		newBranches, newNullable := nonNullBranches(newSchema)
+		switch {
+		case !oldNullable && newNullable:
+			d.report(ChangeNullableAdded, path, oldSchema, newSchema, "type became nullable")
+		case oldNullable && !newNullable:
+			d.report(ChangeNullableRemoved, path, oldSchema, newSchema, "type is not nullable anymore")
+		}
+		if len(oldBranches) != len(newBranches) {
+			d.report(ChangeTypeChanged, path, oldSchema, newSchema, fmt.Sprintf("union of %d types became union of %d types", len(oldBranches), len(newBranches)))
+			return
+		}
+		oldBranches = matchBranches(oldBranches, newBranches)
+		for i := range newBranches {
+			branchPath := path
+			if newIsUnion {
+				branchPath = indexPath(path, unionIndex(newUnion, newBranches[i]))
+			}
+			d.diff(oldBranches[i], newBranches[i], branchPath)
+		}
+		return
+	}
+	if !sameType(oldSchema, newSchema) {
+		d.report(ChangeTypeChanged, path, oldSchema, newSchema, fmt.Sprintf("type %s became %s", describeType(oldSchema), describeType(newSchema)))
+		return
+	}
+	switch n := newSchema.(type) {
+	case *DerivedPrimitiveSchema:
+		o, ok := oldSchema.(*DerivedPrimitiveSchema)
+		if !ok {
+			// the old schema is the primitive type itself
+			o = &DerivedPrimitiveSchema{}
+		}
+		d.diffDoc(o.Documentation, n.Documentation, path)
+		d.diffDecimal(o.Precision, o.Scale, n.Precision, n.Scale, path)
+	case *ArraySchema:
+		d.diff(oldSchema.(*ArraySchema).Items, n.Items, joinPath(path, "items"))
+	case *MapSchema:
+		d.diff(oldSchema.(*MapSchema).Value, n.Value, joinPath(path, "values"))
+	case *FixedSchema:
+		o := oldSchema.(*FixedSchema)
+		d.diffDoc(o.Documentation, n.Documentation, path)
+		d.diffDecimal(o.Precision, o.Scale, n.Precision, n.Scale, path)
+	case *EnumSchema:
+		d.diffEnum(oldSchema.(*EnumSchema), n, path)
+	case *RecordSchema:
+		d.diffRecord(oldSchema.(*RecordSchema), n, path)
+	}
+}
+
+func (d *differ) diffDoc(oldDoc, newDoc, path string) {
+	if oldDoc != newDoc {
+		d.report(ChangeDocChanged, joinPath(path, "doc"), oldDoc, newDoc, "documentation changed")
+	}
+}
+
+func (d *differ) diffDecimal(oldPrecision, oldScale, newPrecision, newScale *int, path string) {
+	if intOrZero(oldPrecision) != intOrZero(newPrecision) {
+		d.report(ChangePrecisionChanged, joinPath(path, "precision"), intOrZero(oldPrecision), intOrZero(newPrecision), fmt.Sprintf("precision %d became %d", intOrZero(oldPrecision), intOrZero(newPrecision)))
+	}
+	if intOrZero(oldScale) != intOrZero(newScale) {
+		d.report(ChangeScaleChanged, joinPath(path, "scale"), intOrZero(oldScale), intOrZero(newScale), fmt.Sprintf("scale %d became %d", intOrZero(oldScale), intOrZero(newScale)))
+	}
+}
+
+func (d *differ) diffEnum(oldEnum, newEnum *EnumSchema, path string) {
+	d.diffDoc(oldEnum.Documentation, newEnum.Documentation, path)
+	added := missingSymbols(newEnum.Symbols, oldEnum.Symbols)
+	if len(added) > 0 {
+		d.report(ChangeEnumSymbolsAdded, joinPath(path, "symbols"), nil, added, fmt.Sprintf("symbols %v added", added))
+	}
+	removed := missingSymbols(oldEnum.Symbols, newEnum.Symbols)
+	if len(removed) > 0 {
+		d.report(ChangeEnumSymbolsRemoved, joinPath(path, "symbols"), removed, nil, fmt.Sprintf("symbols %v removed", removed))
+	}
+	if oldEnum.Default != newEnum.Default {
+		d.report(ChangeDefaultChanged, joinPath(path, "default"), oldEnum.Default, newEnum.Default, fmt.Sprintf("default %q became %q", oldEnum.Default, newEnum.Default))
+	}
+}
+
+func (d *differ) diffRecord(oldRecord, newRecord *RecordSchema, path string) {
+	key := [2]NamedSchema{oldRecord, newRecord}
+	if _, ok := d.visited[key]; ok {
+		return
+	}
+	d.visited[key] = struct{}{}
+	d.diffDoc(oldRecord.Documentation, newRecord.Documentation, path)
+	matched := make(map[*RecordFieldSchema]struct{}, len(oldRecord.Fields))
+	for i := range newRecord.Fields {
+		newField := &newRecord.Fields[i]
+		fieldPath := indexPath(joinPath(path, "fields"), i)
+		oldField := lookupWriterField(newField, oldRecord)
+		if oldField == nil {
+			d.report(ChangeFieldAdded, fieldPath, nil, newField.Name, fmt.Sprintf("field %s added", newField.Name))
+			continue
+		}
+		matched[oldField] = struct{}{}
+		if oldField.Name != newField.Name {
+			d.report(ChangeFieldRenamed, fieldPath, oldField.Name, newField.Name, fmt.Sprintf("field %s renamed %s", oldField.Name, newField.Name))
+		}
+		d.diffDoc(oldField.Documentation, newField.Documentation, fieldPath)
+		if !equalDefaults(oldField.Default, newField.Default) {
+			d.report(ChangeDefaultChanged, joinPath(fieldPath, "default"), oldField.Default, newField.Default, fmt.Sprintf("default of field %s changed", newField.Name))
+		}
+		d.diff(oldField.Type, newField.Type, joinPath(fieldPath, "type"))
+	}
+	for i := range oldRecord.Fields {
+		oldField := &oldRecord.Fields[i]
+		if _, ok := matched[oldField]; !ok {
+			d.report(ChangeFieldRemoved, indexPath(joinPath(path, "fields"), i), oldField.Name, nil, fmt.Sprintf("field %s removed", oldField.Name))
+		}
+	}
+}
+
+// referencedSchema resolves references, keeping logical types
+func referencedSchema(schema Schema) Schema {
+	if reference, ok := schema.(*ReferenceSchema); ok {
+		return reference.Schema
+	}
+	return schema
+}
+
+// nonNullBranches returns the branches of the union other than null, and whether null is one of them.
+// A schema which isn't a union is its own single branch.
+func nonNullBranches(schema Schema) (branches []Schema, nullable bool) {
+	union, ok := schema.(UnionSchema)
+	if !ok {
+		return []Schema{schema}, schema == TypeNull
+	}
+	branches = make([]Schema, 0, len(union))
+	for _, branch := range union {
+		if branch == TypeNull {
+			nullable = true
+			continue
+		}
+		branches = append(branches, referencedSchema(branch))
+	}
+	return branches, nullable
+}
+
+// matchBranches reorders the old branches to match the new ones of the same type, logical type and name,
+// so that reordering a union isn't a change. The branches left unmatched are paired in order.
+func matchBranches(oldBranches, newBranches []Schema) []Schema {
+	matched := make([]Schema, len(newBranches))
+	used := make([]bool, len(oldBranches))
+	for i, newBranch := range newBranches {
+		for j, oldBranch := range oldBranches {
+			if !used[j] && sameType(oldBranch, newBranch) {
+				matched[i], used[j] = oldBranch, true
+				break
+			}
+		}
+	}
+	j := 0
+	for i := range matched {
+		if matched[i] != nil {
+			continue
+		}
+		for used[j] {
+			j++
+		}
+		matched[i], used[j] = oldBranches[j], true
+	}
+	return matched
+}
+
+// unionIndex returns the index of the branch in the union, references being resolved
+func unionIndex(union UnionSchema, branch Schema) int {
+	for i := range union {
+		if referencedSchema(union[i]) == branch {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameType reports whether both schemas have the same type, logical type, name and size,
+// without comparing their children
+func sameType(oldSchema, newSchema Schema) bool {
+	if o, ok := plainPrimitive(oldSchema); ok {
+		n, ok := plainPrimitive(newSchema)
+		return ok && o == n
+	}
+	switch n := newSchema.(type) {
+	case Type:
+		return oldSchema == n
+	case *DerivedPrimitiveSchema:
+		o, ok := oldSchema.(*DerivedPrimitiveSchema)
+		return ok && o.Type == n.Type && o.LogicalType == n.LogicalType
+	case *ArraySchema, *MapSchema:
+		return sameKind(oldSchema, newSchema)
+	case *FixedSchema:
+		o, ok := oldSchema.(*FixedSchema)
+		return ok && o.Size == n.Size && o.LogicalType == n.LogicalType && matchNames(n, o)
+	case *EnumSchema, *RecordSchema:
+		return sameKind(oldSchema, newSchema) && matchNames(n.(NamedSchema), oldSchema.(NamedSchema))
+	default:
+		return false
+	}
+}
+
+// plainPrimitive returns the primitive type of primitives without logical type, written as a type name or an object
+func plainPrimitive(schema Schema) (Type, bool) {
+	switch t := schema.(type) {
+	case Type:
+		return t, isPrimitive(t)
+	case *DerivedPrimitiveSchema:
+		return t.Type, t.LogicalType == ""
+	default:
+		return "", false
+	}
+}
+
+// describeType - type name, qualified by the logical type or the name of named types
+func describeType(schema Schema) string {
+	switch t := schema.(type) {
+	case *DerivedPrimitiveSchema:
+		if t.LogicalType == "" {
+			return string(t.Type)
+		}
+		return string(t.Type) + "." + string(t.LogicalType)
+	case *FixedSchema:
+		if t.LogicalType != "" {
+			return fmt.Sprintf("%s(%s, %d).%s", TypeFixed, t.Name, t.Size, t.LogicalType)
+		}
+		return fmt.Sprintf("%s(%s, %d)", TypeFixed, t.Name, t.Size)
+	case *EnumSchema, *RecordSchema:
+		return fmt.Sprintf("%s(%s)", t.TypeName(), schemaName(t.(NamedSchema)))
+	default:
+		return string(kindOf(schema))
+	}
+}
+
+// missingSymbols returns the symbols absent from others
+func missingSymbols(symbols, others []string) []string {
+	missing := make([]string, 0)
+	for _, symbol := range symbols {
+		if !contains(others, symbol) {
+			missing = append(missing, symbol)
+		}
+	}
+	return missing
+}
+
+// equalDefaults compares the values of defaults regardless of their formatting,
+// such as the order of object keys or 1.0 and 1
+func equalDefaults(a, b *json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	valueA, errA := unmarshalJSONValue(*a)
+	valueB, errB := unmarshalJSONValue(*b)
+	if errA != nil || errB != nil {
+		return bytes.Equal(*a, *b)
+	}
+	return equalValues(valueA, valueB)
+}
+
+// equalValues compares decoded JSON values, numbers by their exact value
+func equalValues(a, b interface{}) bool {
+	switch t := a.(type) {
+	case json.Number:
+		n, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ratA, okA := new(big.Rat).SetString(t.String())
+		ratB, okB := new(big.Rat).SetString(n.String())
+		return okA && okB && ratA.Cmp(ratB) == 0
+	case []interface{}:
+		items, ok := b.([]interface{})
+		if !ok || len(items) != len(t) {
+			return false
+		}
+		for i := range t {
+			if !equalValues(t[i], items[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		values, ok := b.(map[string]interface{})
+		if !ok || len(values) != len(t) {
+			return false
+		}
+		for key, value := range t {
+			other, ok := values[key]
+			if !ok || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func intOrZero(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -0,0 +1,132 @@
+package avro
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		oldSchema string
+		newSchema string
+		expected  []string
+	}{
+		{
+			`{"type":"record","name":"post","fields":[{"name":"id","type":"int"}]}`,
+			`{"type":"record","name":"post","fields":[{"name":"id","type":"int"}]}`,
+			[]string{},
+		},
+		{
+			`{"type":"record","name":"post","doc":"a post","fields":[{"name":"id","type":"int"},{"name":"title","type":"string"},{"name":"body","type":"string"}]}`,
+			`{"type":"record","name":"post","doc":"a blog post","fields":[{"name":"id","type":"long"},{"name":"content","aliases":["body"],"type":"string"},{"name":"author","type":"string","default":"anonymous"}]}`,
+			[]string{
+				"DOC_CHANGED doc",
+				"TYPE_CHANGED fields[0].type",
+				"FIELD_RENAMED fields[1]",
+				"FIELD_ADDED fields[2]",
+				"FIELD_REMOVED fields[1]",
+			},
+		},
+		{
+			`{"type":"record","name":"post","fields":[{"name":"title","type":"string"},{"name":"body","type":["null","string"]}]}`,
+			`{"type":"record","name":"post","fields":[{"name":"title","type":["null","string"],"default":null},{"name":"body","type":"string","doc":"content"}]}`,
+			[]string{
+				"DEFAULT_CHANGED fields[0].default",
+				"NULLABLE_ADDED fields[0].type",
+				"DOC_CHANGED fields[1].doc",
+				"NULLABLE_REMOVED fields[1].type",
+			},
+		},
+		{
+			`{"type":"record","name":"post","fields":[{"name":"score","type":["null",{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}]}]}`,
+			`{"type":"record","name":"post","fields":[{"name":"score","type":["null",{"type":"bytes","logicalType":"decimal","precision":6,"scale":3}]}]}`,
+			[]string{
+				"PRECISION_CHANGED fields[0].type[1].precision",
+				"SCALE_CHANGED fields[0].type[1].scale",
+			},
+		},
+		{
+			`{"type":"map","values":{"type":"enum","name":"color","symbols":["RED","GREEN"]}}`,
+			`{"type":"map","values":{"type":"enum","name":"color","symbols":["RED","BLUE"],"default":"RED"}}`,
+			[]string{
+				"ENUM_SYMBOLS_ADDED values.symbols",
+				"ENUM_SYMBOLS_REMOVED values.symbols",
+				"DEFAULT_CHANGED values.default",
+			},
+		},
+		{
+			`{"type":"array","items":{"type":"fixed","name":"md5","size":16}}`,
+			`{"type":"array","items":{"type":"fixed","name":"md5","size":32}}`,
+			[]string{"TYPE_CHANGED items"},
+		},
+		{
+			`["null","int","string"]`,
+			`["null","string"]`,
+			[]string{"TYPE_CHANGED "},
+		},
+		{
+			`{"type":"long","logicalType":"timestamp-millis"}`,
+			`{"type":"long","logicalType":"timestamp-micros"}`,
+			[]string{"TYPE_CHANGED "},
+		},
+		{
+			`{"type":"record","name":"node","fields":[{"name":"next","type":["null","node"]},{"name":"value","type":"int"}]}`,
+			`{"type":"record","name":"node","fields":[{"name":"next","type":["null","node"]},{"name":"value","type":"int","default":0}]}`,
+			[]string{"DEFAULT_CHANGED fields[1].default"},
+		},
+		{
+			`{"type":"record","name":"r","fields":[{"name":"a","type":["null","string","int"]}]}`,
+			`{"type":"record","name":"r","fields":[{"name":"a","type":["int","null","string"]}]}`,
+			[]string{},
+		},
+		{
+			`["string",{"type":"enum","name":"e","symbols":["A"]},{"type":"record","name":"r","fields":[]}]`,
+			`[{"type":"record","name":"r","fields":[]},{"type":"enum","name":"e","symbols":["A","B"]},"string"]`,
+			[]string{"ENUM_SYMBOLS_ADDED [1].symbols"},
+		},
+		{
+			`["string","int"]`,
+			`["long","string"]`,
+			[]string{"TYPE_CHANGED [0]"},
+		},
+		{
+			`{"type":"record","name":"r","fields":[
+				{"name":"a","type":{"type":"map","values":"int"},"default":{"a":1,"b":2}},
+				{"name":"b","type":"double","default":1.0},
+				{"name":"c","type":{"type":"array","items":"double"},"default":[1,2e1]},
+				{"name":"d","type":"long","default":9223372036854775807}]}`,
+			`{"type":"record","name":"r","fields":[
+				{"name":"a","type":{"type":"map","values":"int"},"default":{"b":2,"a":1}},
+				{"name":"b","type":"double","default":1},
+				{"name":"c","type":{"type":"array","items":"double"},"default":[1.0,20]},
+				{"name":"d","type":"long","default":9223372036854775806}]}`,
+			[]string{"DEFAULT_CHANGED fields[3].default"},
+		},
+	}
+	for i, c := range cases {
+		changes := Diff(mustParseSchema(t, c.oldSchema), mustParseSchema(t, c.newSchema))
+		got := make([]string, 0, len(changes))
+		for _, change := range changes {
+			got = append(got, string(change.Type)+" "+change.Path)
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expected, got)
+		}
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	oldSchema := mustParseSchema(t, `{"type":"record","name":"post","fields":[{"name":"body","type":"string"}]}`)
+	newSchema := mustParseSchema(t, `{"type":"record","name":"post","fields":[{"name":"content","aliases":["body"],"type":"string"}]}`)
+	changes := Diff(oldSchema, newSchema)
+	if len(changes) != 1 {
+		t.Fatalf("expected one change, got %v", changes)
+	}
+	change := changes[0]
+	if change.Old != "body" || change.New != "content" {
+		t.Errorf("expected body renamed content, got %v", change)
+	}
+	if change.String() != "FIELD_RENAMED: field body renamed content at fields[0]" {
+		t.Errorf("unexpected message %s", change)
+	}
+}
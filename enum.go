@@ -12,6 +12,9 @@ type EnumSchema struct {
 	Aliases       []string `json:"aliases,omitempty"`
 	Documentation string   `json:"doc,omitempty"`
 	Symbols       []string `json:"symbols"`
+	// Default - symbol used by readers when the writer symbol is unknown (optional)
+	Default    string     `json:"default,omitempty"`
+	Properties Properties `json:"-"`
 }
 
 // TypeName -
@@ -19,32 +22,66 @@ func (t *EnumSchema) TypeName() Type {
 	return TypeEnum
 }
 
-func translateValueToEnumSchema(value *fastjson.Value) (Schema, error) {
+func (p *schemaParser) translateValueToEnumSchema(value *fastjson.Value, path, enclosingNamespace string) (Schema, error) {
 	if !value.Exists("symbols") {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(path, nil, ErrInvalidSchema, "missing symbols")
 	}
+	symbolsPath := joinPath(path, "symbols")
 	symbolsValues, err := value.Get("symbols").Array()
 	if err != nil {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(symbolsPath, value.Get("symbols"), ErrInvalidSchema, "symbols must be an array")
 	}
 	symbolsSchemas := make([]string, 0, len(symbolsValues))
-	for _, symbolValue := range symbolsValues {
+	for i, symbolValue := range symbolsValues {
 		symbolSchema, err := symbolValue.StringBytes()
 		if err != nil {
-			return nil, ErrInvalidSchema
+			return nil, schemaError(indexPath(symbolsPath, i), symbolValue, ErrInvalidSchema, "symbol must be a string")
 		}
-		symbolsSchemas = append(symbolsSchemas, string(symbolSchema))
+		symbol := string(symbolSchema)
+		if !isValidName(symbol) {
+			return nil, schemaError(indexPath(symbolsPath, i), symbolValue, ErrInvalidSchema, "symbol must match [A-Za-z_][A-Za-z0-9_]*")
+		}
+		if contains(symbolsSchemas, symbol) {
+			return nil, schemaError(indexPath(symbolsPath, i), symbolValue, ErrInvalidSchema, "duplicate symbol")
+		}
+		symbolsSchemas = append(symbolsSchemas, symbol)
+	}
+	var defaultSymbol string
+	if value.Exists("default") {
+		defaultBytes, err := value.Get("default").StringBytes()
+		if err != nil || !contains(symbolsSchemas, string(defaultBytes)) {
+			return nil, schemaError(joinPath(path, "default"), value.Get("default"), ErrInvalidSchema, "default must be one of the symbols")
+		}
+		defaultSymbol = string(defaultBytes)
 	}
-	namespace, name, documentation, aliases, err := translateValueToMetaFields(value)
+	namespace, name, documentation, aliases, err := translateValueToMetaFields(value, path)
 	if err != nil {
 		return nil, err
 	}
-	return &EnumSchema{
+	schema := &EnumSchema{
 		Type:          TypeEnum,
 		Namespace:     namespace,
 		Name:          name,
 		Aliases:       aliases,
 		Documentation: documentation,
 		Symbols:       symbolsSchemas,
-	}, nil
+		Default:       defaultSymbol,
+		Properties:    translateValueToProperties(value, enumAttributes),
+	}
+	err = p.define(translateValueToFullName(value, name, namespace, enclosingNamespace), schema, value, path)
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the enum
+func (t *EnumSchema) MarshalJSON() ([]byte, error) {
+	type enumSchema EnumSchema
+	return marshalWithProperties((*enumSchema)(t), t.Properties, enumAttributes)
+}
+
+// FullName -
+func (t *EnumSchema) FullName(namespace string) string {
+	return fullName(t.Name, t.Namespace, namespace)
 }
@@ -1,12 +1,124 @@
 package avro
 
-import "errors"
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
 
 var (
 	// ErrUnsupportedType - Avro doesn't support the given type
 	ErrUnsupportedType = errors.New("ErrUnsupportedType - AVRO doesn't support the given type")
 	// ErrInvalidSchema - Avro doesn't support the given type
 	ErrInvalidSchema = errors.New("ErrInvalidSchema - Given schema is not AVRO")
+	// ErrRedefinedType - a named type is defined more than once in the schema
+	ErrRedefinedType = errors.New("ErrRedefinedType - named type is already defined")
+	// ErrUnsupportedCompatibilityLevel - the given compatibility level is unknown
+	ErrUnsupportedCompatibilityLevel = errors.New("ErrUnsupportedCompatibilityLevel")
+	// ErrInvalidDatum - the datum doesn't match the schema
+	ErrInvalidDatum = errors.New("ErrInvalidDatum - datum doesn't match the schema")
+	// ErrInvalidEncoding - the data is not AVRO encoded according to the schema
+	ErrInvalidEncoding = errors.New("ErrInvalidEncoding - data is not AVRO encoded according to the schema")
+	// ErrIncompatibleSchema - data written with the writer schema cannot be read with the reader schema
+	ErrIncompatibleSchema = errors.New("ErrIncompatibleSchema - data written with the writer schema cannot be read with the reader schema")
+	// ErrReservedMetadata - metadata keys starting with "avro." are reserved
+	ErrReservedMetadata = errors.New("ErrReservedMetadata - metadata keys starting with \"avro.\" are reserved")
+	// ErrSkipChildren - returned by visitors to skip the children of the visited node
+	ErrSkipChildren = errors.New("ErrSkipChildren - children of the node are skipped")
+	// ErrInvalidIDL - the source is not valid AVRO IDL
+	ErrInvalidIDL = errors.New("ErrInvalidIDL - source is not valid AVRO IDL")
+	// ErrUnknownMessage - the message is not defined by the protocol
+	ErrUnknownMessage = errors.New("ErrUnknownMessage - message is not defined by the protocol")
+	// ErrHandshakeFailed - the RPC server doesn't know the protocol of the client
+	ErrHandshakeFailed = errors.New("ErrHandshakeFailed - server doesn't know the protocol of the client")
+	// ErrInvalidSingleObject - the data doesn't start with the single-object marker and the fingerprint of its schema
+	ErrInvalidSingleObject = errors.New("ErrInvalidSingleObject - data is not AVRO single-object encoded")
+	// ErrUnknownFingerprint - no schema is known with this fingerprint
+	ErrUnknownFingerprint = errors.New("ErrUnknownFingerprint - no schema is known with this fingerprint")
+	// ErrMessageTooLarge - the RPC message is larger than MaxRPCMessageSize
+	ErrMessageTooLarge = errors.New("ErrMessageTooLarge - RPC message is larger than MaxRPCMessageSize")
 	// ErrUnsupportedCompression - avro doesn't supprot this compression
 	ErrUnsupportedCompression = errors.New("ErrUnsupportedCompression")
 )
+
+// SchemaError - reason why a schema is invalid, matching ErrInvalidSchema, ErrUnsupportedType or ErrRedefinedType with errors.Is
+type SchemaError struct {
+	// Path - location of the offending value in the schema, e.g. "fields[3].type[1].items"
+	Path string
+	// Value - offending JSON value, if any
+	Value string
+	// Reason - what is wrong with the value
+	Reason string
+	// Err - ErrInvalidSchema, ErrUnsupportedType or ErrRedefinedType
+	Err error
+}
+
+func (e *SchemaError) Error() string {
+	msg := e.Err.Error()
+	if e.Path != "" {
+		msg += " at " + e.Path
+	}
+	msg += ": " + e.Reason
+	if e.Value != "" {
+		msg += ", got " + e.Value
+	}
+	return msg
+}
+
+// Unwrap - returns the sentinel error
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// SchemaErrors - every reason why a schema is invalid, as returned by ValidateSchema
+type SchemaErrors []*SchemaError
+
+func (e SchemaErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is - reports whether one of the errors matches target
+func (e SchemaErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// IDLError - location and reason of a problem in an IDL source, matching ErrInvalidIDL with errors.Is.
+// Invalid declarations also match the underlying *SchemaError.
+type IDLError struct {
+	// File - path of the IDL file, empty for sources given as bytes
+	File string
+	// Line and Column - position of the offending token, starting at 1
+	Line   int
+	Column int
+	// Reason - what is wrong at this position
+	Reason string
+	// Err - ErrInvalidIDL or, for invalid declarations, the *SchemaError
+	Err error
+}
+
+func (e *IDLError) Error() string {
+	location := strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column)
+	if e.File != "" {
+		location = e.File + ":" + location
+	}
+	return ErrInvalidIDL.Error() + " at " + location + ": " + e.Reason
+}
+
+// Unwrap - returns ErrInvalidIDL or the *SchemaError
+func (e *IDLError) Unwrap() error {
+	return e.Err
+}
+
+// Is - every IDLError matches ErrInvalidIDL
+func (e *IDLError) Is(target error) bool {
+	return target == ErrInvalidIDL
+}
@@ -0,0 +1,56 @@
+package avro
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+)
+
+// rabinEmpty - CRC-64-AVRO fingerprint of the empty byte string
+const rabinEmpty uint64 = 0xc15d213aa4d7a795
+
+var rabinTable = func() (table [256]uint64) {
+	for i := range table {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (rabinEmpty & -(fp & 1))
+		}
+		table[i] = fp
+	}
+	return table
+}()
+
+// Rabin - computes the CRC-64-AVRO fingerprint of the given bytes
+func Rabin(buf []byte) uint64 {
+	fp := rabinEmpty
+	for _, b := range buf {
+		fp = (fp >> 8) ^ rabinTable[byte(fp)^b]
+	}
+	return fp
+}
+
+// FingerprintRabin - CRC-64-AVRO fingerprint of the schema's Parsing Canonical Form
+func FingerprintRabin(schema Schema) (uint64, error) {
+	canonical, err := CanonicalForm(schema)
+	if err != nil {
+		return 0, err
+	}
+	return Rabin(canonical), nil
+}
+
+// FingerprintMD5 - MD5 fingerprint of the schema's Parsing Canonical Form
+func FingerprintMD5(schema Schema) ([md5.Size]byte, error) {
+	canonical, err := CanonicalForm(schema)
+	if err != nil {
+		return [md5.Size]byte{}, err
+	}
+	return md5.Sum(canonical), nil
+}
+
+// FingerprintSHA256 - SHA-256 fingerprint of the schema's Parsing Canonical Form
+func FingerprintSHA256(schema Schema) ([sha256.Size]byte, error) {
+	canonical, err := CanonicalForm(schema)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(canonical), nil
+}
@@ -13,39 +13,51 @@ type FixedSchema struct {
 	Aliases       []string    `json:"aliases,omitempty"`
 	Documentation string      `json:"doc,omitempty"`
 	Size          int         `json:"size"`
+	Precision     *int        `json:"precision,omitempty"`
+	Scale         *int        `json:"scale,omitempty"`
+	Properties    Properties  `json:"-"`
 }
 
-// TypeName -
+// TypeName - the logical type, if any and known, or fixed
 func (t *FixedSchema) TypeName() Type {
-	if len(t.LogicalType) > 0 {
+	if t.LogicalType.isKnown() {
 		return Type(t.LogicalType)
 	}
 	return TypeFixed
 }
 
-func translateValueToFixedSchema(value *fastjson.Value) (Schema, error) {
+func (p *schemaParser) translateValueToFixedSchema(value *fastjson.Value, path, enclosingNamespace string) (Schema, error) {
 	if !value.Exists("size") {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(path, nil, ErrInvalidSchema, "missing size")
 	}
 	size, err := value.Get("size").Int()
-	if err != nil {
-		return nil, ErrInvalidSchema
+	if err != nil || size < 0 {
+		return nil, schemaError(joinPath(path, "size"), value.Get("size"), ErrInvalidSchema, "size must be a non negative integer")
 	}
-	if size < 0 {
-		return nil, ErrInvalidSchema
+	namespace, name, documentation, aliases, err := translateValueToMetaFields(value, path)
+	if err != nil {
+		return nil, err
 	}
-	namespace, name, documentation, aliases, err := translateValueToMetaFields(value)
+	logicalType, err := translateValueToLogicalType(value, path)
 	if err != nil {
 		return nil, err
 	}
-	var logicalType LogicalType
-	if value.Exists("logicalType") {
-		logicalType = LogicalType(value.GetStringBytes("logicalType"))
-		if logicalType != LogialTypeDuration || size != 12 {
-			return nil, ErrInvalidSchema
+	var precision, scale *int
+	switch {
+	case !logicalType.isKnown():
+	case !logicalType.annotates(TypeFixed):
+		return nil, schemaError(joinPath(path, "logicalType"), value.Get("logicalType"), ErrInvalidSchema, "logical type doesn't annotate fixed")
+	case logicalType == LogicalTypeDuration:
+		if size != 12 {
+			return nil, schemaError(joinPath(path, "size"), value.Get("size"), ErrInvalidSchema, "size of duration must be 12")
+		}
+	case logicalType == LogicalTypeDecimal:
+		precision, scale, err = translateValueToDecimalParameters(value, path, maxFixedDecimalPrecision(size))
+		if err != nil {
+			return nil, err
 		}
 	}
-	return &FixedSchema{
+	schema := &FixedSchema{
 		Type:          TypeFixed,
 		LogicalType:   logicalType,
 		Namespace:     namespace,
@@ -53,5 +65,24 @@ func translateValueToFixedSchema(value *fastjson.Value) (Schema, error) {
 		Aliases:       aliases,
 		Documentation: documentation,
 		Size:          size,
-	}, nil
+		Precision:     precision,
+		Scale:         scale,
+		Properties:    translateValueToProperties(value, logicalTypeAttributes(fixedAttributes, logicalType)),
+	}
+	err = p.define(translateValueToFullName(value, name, namespace, enclosingNamespace), schema, value, path)
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the fixed
+func (t *FixedSchema) MarshalJSON() ([]byte, error) {
+	type fixedSchema FixedSchema
+	return marshalWithProperties((*fixedSchema)(t), t.Properties, logicalTypeAttributes(fixedAttributes, t.LogicalType))
+}
+
+// FullName -
+func (t *FixedSchema) FullName(namespace string) string {
+	return fullName(t.Name, t.Namespace, namespace)
 }
@@ -4,7 +4,10 @@ go 1.14
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.4.1
+	github.com/dsnet/compress v0.0.1
 	github.com/golang/snappy v0.0.1
+	github.com/klauspost/compress v1.11.13
 	github.com/linkedin/goavro/v2 v2.9.7
+	github.com/ulikunitz/xz v0.5.10
 	github.com/valyala/fastjson v1.5.0
 )
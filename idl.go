@@ -0,0 +1,22 @@
+package avro
+
+import (
+	"io/ioutil"
+	"path/filepath"
+)
+
+// ParseIDL - parses the protocol declared by the AVRO IDL source. Imported files are looked up from importDir.
+// Imports of IDL (.avdl), protocol (.avpr) and schema (.avsc) files are supported.
+// Problems are returned as *IDLError.
+func ParseIDL(src []byte, importDir string) (*Protocol, error) {
+	return parseIDL("", string(src), importDir)
+}
+
+// ParseIDLFile - parses the AVRO IDL file. Imported files are looked up from the directory of the file.
+func ParseIDLFile(path string) (*Protocol, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseIDL(path, string(src), filepath.Dir(path))
+}
@@ -0,0 +1,187 @@
+package avro
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type idlTokenKind int
+
+const (
+	idlEOF idlTokenKind = iota
+	// idlIdent - identifier, possibly dotted or quoted with backticks
+	idlIdent
+	// idlString - JSON string literal, value is unquoted
+	idlString
+	// idlNumber - JSON number literal
+	idlNumber
+	// idlPunct - one of {}()<>[];,=@?:
+	idlPunct
+)
+
+type idlToken struct {
+	kind  idlTokenKind
+	value string
+	// quoted - identifier written between backticks, so never a keyword
+	quoted bool
+	// doc - content of the documentation comment preceding the token, if any
+	doc  string
+	line int
+	col  int
+}
+
+// idlLexer splits IDL sources into tokens, skipping comments but remembering documentation comments
+type idlLexer struct {
+	file string
+	src  string
+	pos  int
+	line int
+	col  int
+	doc  string
+}
+
+func newIDLLexer(file, src string) *idlLexer {
+	return &idlLexer{file: file, src: src, line: 1, col: 1}
+}
+
+func (l *idlLexer) errorf(reason string) error {
+	return &IDLError{File: l.file, Line: l.line, Column: l.col, Reason: reason, Err: ErrInvalidIDL}
+}
+
+func (l *idlLexer) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return r
+}
+
+func (l *idlLexer) advance(n int) {
+	for _, r := range l.src[l.pos : l.pos+n] {
+		if r == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+	}
+	l.pos += n
+}
+
+// skip skips spaces and comments, documentation comments being kept for the next token
+func (l *idlLexer) skip() error {
+	for l.pos < len(l.src) {
+		rest := l.src[l.pos:]
+		switch {
+		case unicode.IsSpace(l.peekRune()):
+			_, size := utf8.DecodeRuneInString(rest)
+			l.advance(size)
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			l.advance(end)
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return l.errorf("unterminated comment")
+			}
+			comment := rest[2 : 2+end]
+			if strings.HasPrefix(comment, "*") && comment != "*" {
+				l.doc = idlDoc(comment[1:])
+			}
+			l.advance(end + 4)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// idlDoc strips the leading stars and spaces of the lines of a documentation comment
+func idlDoc(comment string) string {
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if i > 0 {
+			line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		}
+		lines[i] = line
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (l *idlLexer) next() (idlToken, error) {
+	err := l.skip()
+	if err != nil {
+		return idlToken{}, err
+	}
+	token := idlToken{doc: l.doc, line: l.line, col: l.col}
+	l.doc = ""
+	if l.pos >= len(l.src) {
+		token.kind = idlEOF
+		return token, nil
+	}
+	rest := l.src[l.pos:]
+	r := l.peekRune()
+	switch {
+	case r == '`':
+		end := strings.IndexByte(rest[1:], '`')
+		if end < 0 {
+			return token, l.errorf("unterminated quoted identifier")
+		}
+		token.kind, token.value, token.quoted = idlIdent, rest[1:1+end], true
+		l.advance(end + 2)
+	case r == '"':
+		end, value, ok := idlStringLiteral(rest)
+		if !ok {
+			return token, l.errorf("invalid string literal")
+		}
+		token.kind, token.value = idlString, value
+		l.advance(end)
+	case r == '-' || '0' <= r && r <= '9':
+		end := 1
+		for end < len(rest) && strings.ContainsRune("0123456789.eE+-", rune(rest[end])) {
+			end++
+		}
+		token.kind, token.value = idlNumber, rest[:end]
+		l.advance(end)
+	case r == '_' || unicode.IsLetter(r):
+		end := 0
+		for end < len(rest) {
+			c, size := utf8.DecodeRuneInString(rest[end:])
+			if c != '_' && c != '.' && c != '-' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+				break
+			}
+			end += size
+		}
+		token.kind, token.value = idlIdent, rest[:end]
+		l.advance(end)
+	case strings.ContainsRune("{}()<>[];,=@?:", r):
+		token.kind, token.value = idlPunct, string(r)
+		l.advance(1)
+	default:
+		return token, l.errorf("unexpected character " + string(r))
+	}
+	return token, nil
+}
+
+// idlStringLiteral reads the JSON string starting s, returns its length and its unquoted value
+func idlStringLiteral(s string) (int, string, bool) {
+	escaped := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			value, err := unmarshalJSONValue([]byte(s[:i+1]))
+			if err != nil {
+				return 0, "", false
+			}
+			str, ok := value.(string)
+			return i + 1, str, ok
+		}
+	}
+	return 0, "", false
+}
@@ -0,0 +1,954 @@
+package avro
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+// idlPrimitives - IDL keywords of the primitive types
+var idlPrimitives = map[string]Type{
+	"null":    TypeNull,
+	"boolean": TypeBoolean,
+	"int":     TypeInt32,
+	"long":    TypeInt64,
+	"float":   TypeFloat32,
+	"double":  TypeFloat64,
+	"bytes":   TypeBytes,
+	"string":  TypeString,
+}
+
+// idlLogicalTypes - IDL keywords of the logical types, but decimal which takes parameters
+var idlLogicalTypes = map[string]struct {
+	base        Type
+	logicalType LogicalType
+}{
+	"date":               {TypeInt32, LogicalTypeDate},
+	"time_ms":            {TypeInt32, LogicalTypeTimeMillis},
+	"timestamp_ms":       {TypeInt64, LogicalTypeTimestampMillis},
+	"local_timestamp_ms": {TypeInt64, LogicalTypeLocalTimestampMillis},
+	"uuid":               {TypeString, LogicalTypeUUID},
+}
+
+// annotations written before the type of a field but applying to the field rather than to its type
+var idlFieldAnnotations = []string{"order", "aliases"}
+
+// attributes set by the IDL syntax, which cannot be annotations
+var (
+	idlNamedReserved     = []string{"type", "name", "fields", "symbols", "size", "default"}
+	idlFieldReserved     = []string{"name", "type", "default"}
+	idlMessageReserved   = []string{"request", "response", "errors", "one-way"}
+	idlContainerReserved = []string{"type", "items", "values"}
+	idlLogicalReserved   = []string{"type", "logicalType", "precision", "scale"}
+)
+
+// idlObject - JSON object translated from an IDL declaration
+type idlObject map[string]interface{}
+
+// idlParser translates IDL declarations to their JSON definition, then to schemas.
+// Imported files are parsed by copies of the parser sharing the named types and the result.
+type idlParser struct {
+	lexer *idlLexer
+	token idlToken
+	// dir - directory imports are looked up from
+	dir string
+	// namespace - namespace of the protocol being parsed
+	namespace  string
+	schemas    *schemaParser
+	jsonParser *fastjson.Parser
+	imported   map[string]bool
+	protocol   *Protocol
+}
+
+func parseIDL(file, src, dir string) (*Protocol, error) {
+	p := &idlParser{
+		schemas:    newSchemaParser(false),
+		jsonParser: &fastjson.Parser{},
+		imported:   make(map[string]bool),
+		protocol:   &Protocol{Messages: make(map[string]*Message)},
+	}
+	if file != "" {
+		abs, err := filepath.Abs(file)
+		if err == nil {
+			p.imported[abs] = true
+		}
+	}
+	err := p.parseFile(file, src, dir, true)
+	if err != nil {
+		return nil, err
+	}
+	return p.protocol, nil
+}
+
+// parseFile parses the protocol declared in src, root being false for imported files
+func (p *idlParser) parseFile(file, src, dir string, root bool) error {
+	child := *p
+	child.lexer = newIDLLexer(file, src)
+	child.dir = dir
+	child.namespace = ""
+	return child.parseProtocol(root)
+}
+
+func (p *idlParser) parseProtocol(root bool) error {
+	err := p.next()
+	if err != nil {
+		return err
+	}
+	doc := p.token.doc
+	annotations, err := p.parseAnnotations()
+	if err != nil {
+		return err
+	}
+	if !p.isKeyword("protocol") {
+		return p.expected("protocol")
+	}
+	err = p.next()
+	if err != nil {
+		return err
+	}
+	name, err := p.parseIdentifier()
+	if err != nil {
+		return err
+	}
+	if namespace, ok := annotations["namespace"]; ok {
+		p.namespace, ok = namespace.(string)
+		if !ok {
+			return p.errorf("@namespace must be a string")
+		}
+		delete(annotations, "namespace")
+	}
+	err = p.expect("{")
+	if err != nil {
+		return err
+	}
+	for !p.isPunct("}") {
+		if p.token.kind == idlEOF {
+			return p.expected("}")
+		}
+		err = p.parseDeclaration()
+		if err != nil {
+			return err
+		}
+	}
+	err = p.next()
+	if err != nil {
+		return err
+	}
+	if p.token.kind != idlEOF {
+		return p.expected("end of file")
+	}
+	if !root {
+		return nil
+	}
+	p.protocol.Name = name
+	p.protocol.Namespace = p.namespace
+	p.protocol.Documentation = doc
+	for key, value := range annotations {
+		if contains(protocolAttributes, key) {
+			continue
+		}
+		if p.protocol.Properties == nil {
+			p.protocol.Properties = make(Properties)
+		}
+		p.protocol.Properties[key], err = json.Marshal(value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *idlParser) parseDeclaration() error {
+	start := p.token
+	if p.isKeyword("import") {
+		return p.parseImport()
+	}
+	annotations, err := p.parseAnnotations()
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.isKeyword("record"), p.isKeyword("error"):
+		return p.parseRecord(start, annotations)
+	case p.isKeyword("enum"):
+		return p.parseEnum(start, annotations)
+	case p.isKeyword("fixed"):
+		return p.parseFixed(start, annotations)
+	default:
+		return p.parseMessage(start, annotations)
+	}
+}
+
+// parseNamedDeclaration parses the keyword and the name of a named type
+// and returns its JSON definition, namespaced by the protocol unless annotated otherwise
+func (p *idlParser) parseNamedDeclaration(start idlToken, typeName Type, annotations idlObject) (idlObject, error) {
+	err := p.checkAnnotations(start, annotations, idlNamedReserved)
+	if err != nil {
+		return nil, err
+	}
+	err = p.next()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	object := idlObject{"type": typeName, "name": name}
+	for key, value := range annotations {
+		object[key] = value
+	}
+	if _, ok := object["doc"]; !ok && start.doc != "" {
+		object["doc"] = start.doc
+	}
+	if _, ok := object["namespace"]; !ok && p.namespace != "" && !strings.Contains(name, ".") {
+		object["namespace"] = p.namespace
+	}
+	return object, nil
+}
+
+func (p *idlParser) parseRecord(start idlToken, annotations idlObject) error {
+	typeName := TypeRecord
+	if p.isKeyword("error") {
+		typeName = TypeError
+	}
+	object, err := p.parseNamedDeclaration(start, typeName, annotations)
+	if err != nil {
+		return err
+	}
+	err = p.expect("{")
+	if err != nil {
+		return err
+	}
+	fields := []interface{}{}
+	for !p.isPunct("}") {
+		if p.token.kind == idlEOF {
+			return p.expected("}")
+		}
+		declared, err := p.parseFields()
+		if err != nil {
+			return err
+		}
+		fields = append(fields, declared...)
+	}
+	err = p.next()
+	if err != nil {
+		return err
+	}
+	object["fields"] = fields
+	return p.declare(start, object)
+}
+
+// parseFields parses `Type name [= default], other [= default];`
+func (p *idlParser) parseFields() ([]interface{}, error) {
+	doc := p.token.doc
+	annotations, err := p.parseAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	fieldAnnotations, typeAnnotations := idlObject{}, idlObject{}
+	for key, value := range annotations {
+		if contains(idlFieldAnnotations, key) {
+			fieldAnnotations[key] = value
+		} else {
+			typeAnnotations[key] = value
+		}
+	}
+	schema, optional, err := p.parseVariableType(typeAnnotations)
+	if err != nil {
+		return nil, err
+	}
+	fields := []interface{}{}
+	for {
+		field, err := p.parseVariable(schema, optional, doc, fieldAnnotations)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+		if !p.isPunct(",") {
+			break
+		}
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fields, p.expect(";")
+}
+
+// parseVariableType parses the type of a field or of a message parameter,
+// optional being true for the `Type?` shorthand of a union with null
+func (p *idlParser) parseVariableType(annotations idlObject) (schema interface{}, optional bool, err error) {
+	schema, err = p.parseType(annotations)
+	if err != nil || !p.isPunct("?") {
+		return schema, false, err
+	}
+	return schema, true, p.next()
+}
+
+// parseVariable parses `[annotations] name [= default]` into the JSON definition of a field or of a message parameter.
+// As the default of a union must match its first branch, null comes last in optional types with a non null default.
+func (p *idlParser) parseVariable(schema interface{}, optional bool, doc string, annotations idlObject) (idlObject, error) {
+	start := p.token
+	variableAnnotations, err := p.parseAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	object := idlObject{}
+	for key, value := range annotations {
+		object[key] = value
+	}
+	for key, value := range variableAnnotations {
+		if _, ok := object[key]; ok {
+			return nil, p.errorAt(start, "duplicate annotation @"+key)
+		}
+		object[key] = value
+	}
+	err = p.checkAnnotations(start, object, idlFieldReserved)
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	object["name"] = name
+	if _, ok := object["doc"]; !ok && doc != "" {
+		object["doc"] = doc
+	}
+	var defaultValue interface{}
+	if p.isPunct("=") {
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		defaultValue, err = p.parseJSON()
+		if err != nil {
+			return nil, err
+		}
+		object["default"] = defaultValue
+	}
+	object["type"] = schema
+	if optional {
+		object["type"] = []interface{}{"null", schema}
+		if defaultValue != nil {
+			object["type"] = []interface{}{schema, "null"}
+		}
+	}
+	return object, nil
+}
+
+func (p *idlParser) parseEnum(start idlToken, annotations idlObject) error {
+	object, err := p.parseNamedDeclaration(start, TypeEnum, annotations)
+	if err != nil {
+		return err
+	}
+	err = p.expect("{")
+	if err != nil {
+		return err
+	}
+	symbols := []interface{}{}
+	for !p.isPunct("}") {
+		if len(symbols) > 0 {
+			err = p.expect(",")
+			if err != nil {
+				return err
+			}
+		}
+		symbol, err := p.parseIdentifier()
+		if err != nil {
+			return err
+		}
+		symbols = append(symbols, symbol)
+	}
+	object["symbols"] = symbols
+	err = p.next()
+	if err != nil {
+		return err
+	}
+	if p.isPunct("=") {
+		err = p.next()
+		if err != nil {
+			return err
+		}
+		object["default"], err = p.parseIdentifier()
+		if err != nil {
+			return err
+		}
+	}
+	if p.isPunct(";") {
+		err = p.next()
+		if err != nil {
+			return err
+		}
+	}
+	return p.declare(start, object)
+}
+
+func (p *idlParser) parseFixed(start idlToken, annotations idlObject) error {
+	object, err := p.parseNamedDeclaration(start, TypeFixed, annotations)
+	if err != nil {
+		return err
+	}
+	err = p.expect("(")
+	if err != nil {
+		return err
+	}
+	object["size"], err = p.parseInteger()
+	if err != nil {
+		return err
+	}
+	err = p.expect(")")
+	if err != nil {
+		return err
+	}
+	err = p.expect(";")
+	if err != nil {
+		return err
+	}
+	return p.declare(start, object)
+}
+
+// parseType parses a type, annotations being the ones written before it
+func (p *idlParser) parseType(annotations idlObject) (interface{}, error) {
+	token := p.token
+	if token.kind != idlIdent {
+		return nil, p.expected("type")
+	}
+	err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	keyword := token.value
+	if token.quoted {
+		keyword = ""
+	}
+	switch keyword {
+	case "union":
+		if len(annotations) > 0 {
+			return nil, p.errorAt(token, "unions cannot be annotated")
+		}
+		err = p.expect("{")
+		if err != nil {
+			return nil, err
+		}
+		branches := []interface{}{}
+		for !p.isPunct("}") {
+			if len(branches) > 0 {
+				err = p.expect(",")
+				if err != nil {
+					return nil, err
+				}
+			}
+			branch, err := p.parseType(nil)
+			if err != nil {
+				return nil, err
+			}
+			branches = append(branches, branch)
+		}
+		return branches, p.next()
+	case "array", "map":
+		err = p.checkAnnotations(token, annotations, idlContainerReserved)
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect("<")
+		if err != nil {
+			return nil, err
+		}
+		elements, err := p.parseType(nil)
+		if err != nil {
+			return nil, err
+		}
+		object := idlObject{"type": keyword, "items": elements}
+		if keyword == "map" {
+			object = idlObject{"type": keyword, "values": elements}
+		}
+		for key, value := range annotations {
+			object[key] = value
+		}
+		return object, p.expect(">")
+	case "decimal":
+		err = p.checkAnnotations(token, annotations, idlLogicalReserved)
+		if err != nil {
+			return nil, err
+		}
+		object := idlObject{"type": TypeBytes, "logicalType": LogicalTypeDecimal}
+		for key, value := range annotations {
+			object[key] = value
+		}
+		err = p.expect("(")
+		if err != nil {
+			return nil, err
+		}
+		object["precision"], err = p.parseInteger()
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect(",")
+		if err != nil {
+			return nil, err
+		}
+		object["scale"], err = p.parseInteger()
+		if err != nil {
+			return nil, err
+		}
+		return object, p.expect(")")
+	}
+	if logical, ok := idlLogicalTypes[keyword]; ok {
+		err = p.checkAnnotations(token, annotations, idlLogicalReserved)
+		if err != nil {
+			return nil, err
+		}
+		object := idlObject{"type": logical.base, "logicalType": logical.logicalType}
+		for key, value := range annotations {
+			object[key] = value
+		}
+		return object, nil
+	}
+	if primitive, ok := idlPrimitives[keyword]; ok {
+		if len(annotations) == 0 {
+			return primitive, nil
+		}
+		// annotations become attributes of the type, such as @logicalType, or its custom properties, such as @java-class
+		err = p.checkAnnotations(token, annotations, []string{"type"})
+		if err != nil {
+			return nil, err
+		}
+		object := idlObject{"type": primitive}
+		for key, value := range annotations {
+			object[key] = value
+		}
+		return object, nil
+	}
+	if len(annotations) > 0 {
+		return nil, p.errorAt(token, "references to named types cannot be annotated")
+	}
+	return token.value, nil
+}
+
+// parseMessage parses `Response name(Type param [= default], ...) [oneway | throws Error, ...];`
+func (p *idlParser) parseMessage(start idlToken, annotations idlObject) error {
+	err := p.checkAnnotations(start, annotations, idlMessageReserved)
+	if err != nil {
+		return err
+	}
+	var response interface{} = TypeNull
+	if p.isKeyword("void") {
+		err = p.next()
+	} else {
+		response, err = p.parseType(nil)
+	}
+	if err != nil {
+		return err
+	}
+	nameToken := p.token
+	name, err := p.parseIdentifier()
+	if err != nil {
+		return err
+	}
+	err = p.expect("(")
+	if err != nil {
+		return err
+	}
+	request := []interface{}{}
+	for !p.isPunct(")") {
+		if len(request) > 0 {
+			err = p.expect(",")
+			if err != nil {
+				return err
+			}
+		}
+		doc := p.token.doc
+		typeAnnotations, err := p.parseAnnotations()
+		if err != nil {
+			return err
+		}
+		schema, optional, err := p.parseVariableType(typeAnnotations)
+		if err != nil {
+			return err
+		}
+		param, err := p.parseVariable(schema, optional, doc, nil)
+		if err != nil {
+			return err
+		}
+		request = append(request, param)
+	}
+	err = p.next()
+	if err != nil {
+		return err
+	}
+	message := idlObject{}
+	for key, value := range annotations {
+		message[key] = value
+	}
+	if _, ok := message["doc"]; !ok && start.doc != "" {
+		message["doc"] = start.doc
+	}
+	message["request"] = request
+	message["response"] = response
+	switch {
+	case p.isKeyword("oneway"):
+		message["one-way"] = true
+		err = p.next()
+		if err != nil {
+			return err
+		}
+	case p.isKeyword("throws"):
+		errorNames := []interface{}{}
+		for len(errorNames) == 0 || p.isPunct(",") {
+			err = p.next()
+			if err != nil {
+				return err
+			}
+			errorName, err := p.parseIdentifier()
+			if err != nil {
+				return err
+			}
+			errorNames = append(errorNames, errorName)
+		}
+		message["errors"] = errorNames
+	}
+	err = p.expect(";")
+	if err != nil {
+		return err
+	}
+	return p.defineMessage(nameToken, name, message)
+}
+
+// defineMessage translates the JSON definition of a message and adds it to the protocol
+func (p *idlParser) defineMessage(start idlToken, name string, message idlObject) error {
+	if _, ok := p.protocol.Messages[name]; ok {
+		return p.errorAt(start, "message "+name+" is already defined")
+	}
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	value, err := p.jsonParser.ParseBytes(messageBytes)
+	if err != nil {
+		return err
+	}
+	p.protocol.Messages[name], err = p.schemas.translateValueToMessage(value, "", p.namespace)
+	if err != nil {
+		delete(p.protocol.Messages, name)
+		return p.schemaErrorAt(start, err)
+	}
+	return nil
+}
+
+// parseImport parses `import idl|protocol|schema "file";`
+func (p *idlParser) parseImport() error {
+	start := p.token
+	err := p.next()
+	if err != nil {
+		return err
+	}
+	kind := p.token
+	if kind.kind != idlIdent || kind.quoted {
+		return p.expected("idl, protocol or schema")
+	}
+	err = p.next()
+	if err != nil {
+		return err
+	}
+	if p.token.kind != idlString {
+		return p.expected("file name")
+	}
+	path := p.token.value
+	err = p.next()
+	if err != nil {
+		return err
+	}
+	err = p.expect(";")
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return p.errorAt(start, err.Error())
+	}
+	if p.imported[abs] {
+		return nil
+	}
+	p.imported[abs] = true
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return p.errorAt(start, err.Error())
+	}
+	switch kind.value {
+	case "idl":
+		return p.parseFile(path, string(src), filepath.Dir(path), false)
+	case "protocol":
+		return p.importProtocol(start, src)
+	case "schema":
+		return p.importSchema(start, src)
+	default:
+		return p.errorAt(kind, "unknown import kind "+kind.value)
+	}
+}
+
+// importProtocol adds the types and the messages of a protocol (.avpr) file
+func (p *idlParser) importProtocol(start idlToken, src []byte) error {
+	value, err := p.jsonParser.ParseBytes(src)
+	if err != nil {
+		return p.errorAt(start, "invalid protocol: "+err.Error())
+	}
+	// imported types are explicitly namespaced as they leave the protocol defining their namespace
+	namespace := string(value.GetStringBytes("namespace"))
+	var arena fastjson.Arena
+	for _, typeValue := range value.GetArray("types") {
+		name := string(typeValue.GetStringBytes("name"))
+		if namespace != "" && typeValue.Type() == fastjson.TypeObject && !typeValue.Exists("namespace") && !strings.Contains(name, ".") {
+			typeValue.Set("namespace", arena.NewString(namespace))
+		}
+	}
+	protocol, err := p.schemas.translateValueToProtocol(value)
+	if err != nil {
+		return p.schemaErrorAt(start, err)
+	}
+	p.protocol.Types = append(p.protocol.Types, protocol.Types...)
+	for name, message := range protocol.Messages {
+		p.protocol.Messages[name] = message
+	}
+	return nil
+}
+
+// importSchema adds the named types defined by a schema (.avsc) file, or by the branches of its union
+func (p *idlParser) importSchema(start idlToken, src []byte) error {
+	value, err := p.jsonParser.ParseBytes(src)
+	if err != nil {
+		return p.errorAt(start, "invalid schema: "+err.Error())
+	}
+	schema, err := p.schemas.translateValue2AnySchema(value, "", "")
+	if err != nil {
+		return p.schemaErrorAt(start, err)
+	}
+	schemas := []Schema{schema}
+	if union, ok := schema.(UnionSchema); ok {
+		schemas = union
+	}
+	for _, schema := range schemas {
+		if named, ok := schema.(NamedSchema); ok {
+			p.protocol.Types = append(p.protocol.Types, named)
+		}
+	}
+	return nil
+}
+
+// declare translates the JSON definition of a named type and adds it to the protocol
+func (p *idlParser) declare(start idlToken, object idlObject) error {
+	schema, err := p.translate(start, object)
+	if err != nil {
+		return err
+	}
+	p.protocol.Types = append(p.protocol.Types, schema.(NamedSchema))
+	return nil
+}
+
+// translate translates the JSON definition of a type to a schema, from the namespace of the protocol
+func (p *idlParser) translate(start idlToken, definition interface{}) (Schema, error) {
+	definitionBytes, err := json.Marshal(definition)
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.jsonParser.ParseBytes(definitionBytes)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := p.schemas.translateValue2AnySchema(value, "", p.namespace)
+	if err != nil {
+		return nil, p.schemaErrorAt(start, err)
+	}
+	return schema, nil
+}
+
+// parseAnnotations parses `@name(json value)` annotations, if any
+func (p *idlParser) parseAnnotations() (idlObject, error) {
+	annotations := idlObject{}
+	for p.isPunct("@") {
+		start := p.token
+		err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if p.token.kind != idlIdent {
+			return nil, p.expected("annotation name")
+		}
+		name := p.token.value
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect("(")
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.parseJSON()
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect(")")
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := annotations[name]; ok {
+			return nil, p.errorAt(start, "duplicate annotation @"+name)
+		}
+		annotations[name] = value
+	}
+	return annotations, nil
+}
+
+// checkAnnotations fails if one of the annotations is an attribute set by the IDL syntax
+func (p *idlParser) checkAnnotations(start idlToken, annotations idlObject, reserved []string) error {
+	for key := range annotations {
+		if contains(reserved, key) {
+			return p.errorAt(start, "@"+key+" cannot be annotated, it is set by the IDL syntax")
+		}
+	}
+	return nil
+}
+
+// parseJSON parses a JSON value, numbers being kept as json.Number
+func (p *idlParser) parseJSON() (interface{}, error) {
+	token := p.token
+	switch {
+	case token.kind == idlString:
+		return token.value, p.next()
+	case token.kind == idlNumber:
+		if !json.Valid([]byte(token.value)) {
+			return nil, p.errorf("invalid number " + token.value)
+		}
+		return json.Number(token.value), p.next()
+	case p.isKeyword("true"):
+		return true, p.next()
+	case p.isKeyword("false"):
+		return false, p.next()
+	case p.isKeyword("null"):
+		return nil, p.next()
+	case p.isPunct("["):
+		err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		values := []interface{}{}
+		for !p.isPunct("]") {
+			if len(values) > 0 {
+				err = p.expect(",")
+				if err != nil {
+					return nil, err
+				}
+			}
+			value, err := p.parseJSON()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, p.next()
+	case p.isPunct("{"):
+		err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		object := map[string]interface{}{}
+		for !p.isPunct("}") {
+			if len(object) > 0 {
+				err = p.expect(",")
+				if err != nil {
+					return nil, err
+				}
+			}
+			if p.token.kind != idlString {
+				return nil, p.expected("string")
+			}
+			key := p.token.value
+			err = p.next()
+			if err != nil {
+				return nil, err
+			}
+			err = p.expect(":")
+			if err != nil {
+				return nil, err
+			}
+			object[key], err = p.parseJSON()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return object, p.next()
+	default:
+		return nil, p.expected("JSON value")
+	}
+}
+
+func (p *idlParser) parseInteger() (json.Number, error) {
+	if p.token.kind != idlNumber {
+		return "", p.expected("integer")
+	}
+	value := p.token.value
+	_, err := strconv.Atoi(value)
+	if err != nil {
+		return "", p.errorf("invalid integer " + value)
+	}
+	return json.Number(value), p.next()
+}
+
+func (p *idlParser) parseIdentifier() (string, error) {
+	if p.token.kind != idlIdent {
+		return "", p.expected("identifier")
+	}
+	value := p.token.value
+	return value, p.next()
+}
+
+func (p *idlParser) next() (err error) {
+	p.token, err = p.lexer.next()
+	return err
+}
+
+func (p *idlParser) isPunct(value string) bool {
+	return p.token.kind == idlPunct && p.token.value == value
+}
+
+// isKeyword - identifiers quoted with backticks are never keywords
+func (p *idlParser) isKeyword(value string) bool {
+	return p.token.kind == idlIdent && !p.token.quoted && p.token.value == value
+}
+
+func (p *idlParser) expect(value string) error {
+	if !p.isPunct(value) {
+		return p.expected(value)
+	}
+	return p.next()
+}
+
+// expected reports the current token as unexpected
+func (p *idlParser) expected(what string) error {
+	got := strconv.Quote(p.token.value)
+	if p.token.kind == idlEOF {
+		got = "end of file"
+	}
+	return p.errorf("expected " + what + ", got " + got)
+}
+
+func (p *idlParser) errorf(reason string) error {
+	return p.errorAt(p.token, reason)
+}
+
+func (p *idlParser) errorAt(token idlToken, reason string) error {
+	return &IDLError{File: p.lexer.file, Line: token.line, Column: token.col, Reason: reason, Err: ErrInvalidIDL}
+}
+
+// schemaErrorAt locates the problem of the declaration starting at token
+func (p *idlParser) schemaErrorAt(token idlToken, err error) error {
+	return &IDLError{File: p.lexer.file, Line: token.line, Column: token.col, Reason: err.Error(), Err: err}
+}
@@ -0,0 +1,212 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseIDL(t *testing.T) {
+	cases := []struct {
+		idl          string
+		expectedJSON string
+	}{
+		{
+			`/** Simple protocol */
+@namespace("org.example")
+@version("1.0")
+protocol Simple {
+	/** A kind */
+	enum Kind { FOO, BAR } = FOO;
+	@aliases(["org.old.Hash"]) fixed MD5(16);
+	@namespace("org.other") record Node {
+		long value;
+		Node? next;
+	}
+	record TestRecord {
+		/** the name */
+		string @order("ignore") name;
+		@order("descending") Kind kind = "BAR";
+		union { MD5, null } hash;
+		array<long> longs = [];
+		map<string>? props;
+		int? count = 1;
+		decimal(9,2) amount;
+		date day;
+		@logicalType("timestamp-micros") long ts;
+		string @aliases(["old"]) renamed, other = "x";
+		org.other.Node ` + "`record`" + `;
+		@java-class("java.math.BigDecimal") string price;
+		@foo(1) int? small;
+	}
+	error TestError { string message; }
+	/** Says hello */
+	string hello(string greeting = "hi");
+	void fail() throws TestError;
+	void ping() oneway;
+}`,
+			`{"protocol":"Simple","namespace":"org.example","doc":"Simple protocol","version":"1.0","types":[
+				{"type":"enum","namespace":"org.example","name":"Kind","doc":"A kind","symbols":["FOO","BAR"],"default":"FOO"},
+				{"type":"fixed","namespace":"org.example","name":"MD5","aliases":["org.old.Hash"],"size":16},
+				{"type":"record","namespace":"org.other","name":"Node","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","Node"]}]},
+				{"type":"record","namespace":"org.example","name":"TestRecord","fields":[
+					{"name":"name","doc":"the name","type":"string","order":"ignore"},
+					{"name":"kind","type":"Kind","default":"BAR","order":"descending"},
+					{"name":"hash","type":["MD5","null"]},
+					{"name":"longs","type":{"type":"array","items":"long"},"default":[]},
+					{"name":"props","type":["null",{"type":"map","values":"string"}]},
+					{"name":"count","type":["int","null"],"default":1},
+					{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":9,"scale":2}},
+					{"name":"day","type":{"type":"int","logicalType":"date"}},
+					{"name":"ts","type":{"type":"long","logicalType":"timestamp-micros"}},
+					{"name":"renamed","aliases":["old"],"type":"string"},
+					{"name":"other","type":"string","default":"x"},
+					{"name":"record","type":"org.other.Node"},
+					{"name":"price","type":{"type":"string","java-class":"java.math.BigDecimal"}},
+					{"name":"small","type":["null",{"type":"int","foo":1}]}
+				]},
+				{"type":"error","namespace":"org.example","name":"TestError","fields":[{"name":"message","type":"string"}]}
+			],"messages":{
+				"hello":{"doc":"Says hello","request":[{"name":"greeting","type":"string","default":"hi"}],"response":"string"},
+				"fail":{"request":[],"response":"null","errors":["TestError"]},
+				"ping":{"request":[],"response":"null","one-way":true}
+			}}`,
+		},
+		{
+			"// no namespace\nprotocol Empty {}",
+			`{"protocol":"Empty","types":[],"messages":{}}`,
+		},
+	}
+	for i, c := range cases {
+		protocol, err := ParseIDL([]byte(c.idl), "")
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		protocolBytes, err := json.Marshal(protocol)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		var expected, actual interface{}
+		json.Unmarshal([]byte(c.expectedJSON), &expected)
+		json.Unmarshal(protocolBytes, &actual)
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("case %d - expected %s, got %s", i, c.expectedJSON, protocolBytes)
+		}
+	}
+}
+
+func TestParseIDLSchemas(t *testing.T) {
+	protocol, err := ParseIDL([]byte(`@namespace("test") protocol P {
+		record R { date day; decimal(4,2) amount; union { null, string } s; }
+	}`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, ok := protocol.Types[0].(*RecordSchema)
+	if !ok || record.FullName("") != "test.R" {
+		t.Fatalf("expected record test.R, got %v", protocol.Types[0])
+	}
+	expectedTypes := []Type{Type(LogicalTypeDate), Type(LogicalTypeDecimal), TypeUnion}
+	for i, field := range record.Fields {
+		if field.Type.TypeName() != expectedTypes[i] {
+			t.Errorf("case %d - expected %s, got %s", i, expectedTypes[i], field.Type.TypeName())
+		}
+	}
+}
+
+func TestParseIDLFileImports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "idl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"money.avsc": `{"type":"record","name":"Money","namespace":"finance","fields":[{"name":"amount","type":"long"},{"name":"currency","type":"string"}]}`,
+		"status.avpr": `{"protocol":"StatusProtocol","namespace":"status","types":[{"type":"enum","name":"Status","symbols":["OK","KO"]}],
+			"messages":{"status":{"request":[],"response":"Status"}}}`,
+		"common/common.avdl": `@namespace("common") protocol Common {
+			import schema "../money.avsc";
+			record Price { finance.Money value; }
+		}`,
+		"shop.avdl": `@namespace("shop") protocol Shop {
+			import idl "common/common.avdl";
+			import protocol "status.avpr";
+			import schema "money.avsc";
+			record Item { common.Price price; status.Status status; }
+			Item get(string id);
+		}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	protocol, err := ParseIDLFile(filepath.Join(dir, "shop.avdl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedTypes := []string{"finance.Money", "common.Price", "status.Status", "shop.Item"}
+	if len(protocol.Types) != len(expectedTypes) {
+		t.Fatalf("expected %d types, got %d", len(expectedTypes), len(protocol.Types))
+	}
+	for i, expected := range expectedTypes {
+		if fullname := protocol.Types[i].FullName(""); fullname != expected {
+			t.Errorf("case %d - expected %s, got %s", i, expected, fullname)
+		}
+	}
+	for _, message := range []string{"status", "get"} {
+		if _, ok := protocol.Messages[message]; !ok {
+			t.Errorf("expected message %s", message)
+		}
+	}
+	_, err = ParseIDL([]byte(`protocol P { import schema "missing.avsc"; }`), dir)
+	if !errors.Is(err, ErrInvalidIDL) {
+		t.Errorf("expected %v, got %v", ErrInvalidIDL, err)
+	}
+}
+
+func TestParseIDLErrors(t *testing.T) {
+	cases := []struct {
+		idl          string
+		expectedErr  error
+		expectedLine int
+	}{
+		{`record R {}`, ErrInvalidIDL, 1},
+		{"protocol P {\n  record R { string s }\n}", ErrInvalidIDL, 2},
+		{"protocol P {\n  record R { string s; }", ErrInvalidIDL, 2},
+		{"protocol P {}\nprotocol Q {}", ErrInvalidIDL, 2},
+		{"protocol P {\n  record R { Unknown u; }\n}", ErrUnsupportedType, 2},
+		{"protocol P {\n  record R { int i; }\n  enum R { A }\n}", ErrRedefinedType, 3},
+		{"protocol P {\n  record R { int i = \"zero\"; }\n}", ErrInvalidSchema, 2},
+		{"protocol P {\n  record R { int i; }\n  record S { @foo(1) R r; }\n}", ErrInvalidIDL, 3},
+		{"protocol P {\n  record R { @type(\"long\") int i; }\n}", ErrInvalidIDL, 2},
+		{"protocol P {\n  int get() oneway;\n}", ErrInvalidIDL, 2},
+		{"protocol P {\n  void get() throws Unknown;\n}", ErrInvalidIDL, 2},
+		{"protocol P {\n  record R { int i; }\n  void get() throws R;\n}", ErrInvalidSchema, 3},
+		{"protocol P {\n  void get();\n  int get();\n}", ErrInvalidIDL, 3},
+		{"protocol P {\n  void get(Unknown u);\n}", ErrUnsupportedType, 2},
+		{"protocol P {\n  fixed F(-1);\n}", ErrInvalidIDL, 2},
+		{"protocol P {\n  /* unterminated\n}", ErrInvalidIDL, 2},
+		{"protocol P {\n  record R { string s = \"unterminated; }\n}", ErrInvalidIDL, 2},
+	}
+	for i, c := range cases {
+		_, err := ParseIDL([]byte(c.idl), "")
+		if !errors.Is(err, c.expectedErr) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		var idlErr *IDLError
+		if !errors.As(err, &idlErr) || idlErr.Line != c.expectedLine {
+			t.Errorf("case %d - expected error at line %d, got %v", i, c.expectedLine, err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package avro
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// jsonReader translates generic JSON values, as unmarshaled by encoding/json, to native data.
+// When reading defaults, unions hold a value of their first branch instead of being wrapped.
+// err is reported when the value doesn't match the schema.
+type jsonReader struct {
+	defaults bool
+	err      error
+}
+
+func (r jsonReader) decode(schema Schema, value interface{}, namespace string) (interface{}, error) {
+	switch t := schema.(type) {
+	case Type:
+		return r.decodePrimitive(t, value)
+	case *DerivedPrimitiveSchema:
+		datum, err := r.decodePrimitive(t.Type, value)
+		if err != nil {
+			return nil, err
+		}
+		return nativeDatum(t, datum), nil
+	case *ReferenceSchema:
+		return r.decode(t.Schema, value, namespaceOf(fullName(t.Name, "", namespace)))
+	case UnionSchema:
+		return r.decodeUnion(t, value, namespace)
+	case *ArraySchema:
+		values, ok := value.([]interface{})
+		if !ok {
+			return nil, r.err
+		}
+		items := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			item, err := r.decode(t.Items, v, namespace)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case *MapSchema:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, r.err
+		}
+		datum := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			item, err := r.decode(t.Value, v, namespace)
+			if err != nil {
+				return nil, err
+			}
+			datum[k] = item
+		}
+		return datum, nil
+	case *RecordSchema:
+		return r.decodeRecord(t, value, namespace)
+	case *EnumSchema:
+		symbol, ok := value.(string)
+		if !ok || !contains(t.Symbols, symbol) {
+			return nil, r.err
+		}
+		return symbol, nil
+	case *FixedSchema:
+		b, err := r.decodePrimitive(TypeBytes, value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b.([]byte)) != t.Size {
+			return nil, r.err
+		}
+		return nativeFixedDatum(t, b.([]byte)), nil
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func (r jsonReader) decodeUnion(union UnionSchema, value interface{}, namespace string) (interface{}, error) {
+	if len(union) == 0 {
+		return nil, r.err
+	}
+	var (
+		branch Schema
+		datum  interface{}
+	)
+	switch {
+	case r.defaults:
+		branch, datum = union[0], value
+	case value == nil:
+		if lookupBranch(union, string(TypeNull), namespace) < 0 {
+			return nil, r.err
+		}
+		return nil, nil
+	default:
+		wrapper, ok := value.(map[string]interface{})
+		if !ok || len(wrapper) != 1 {
+			return nil, r.err
+		}
+		for name, v := range wrapper {
+			i := lookupBranch(union, name, namespace)
+			if i < 0 {
+				return nil, r.err
+			}
+			branch, datum = union[i], v
+		}
+	}
+	datum, err := r.decode(branch, datum, namespace)
+	if err != nil || datum == nil {
+		return datum, err
+	}
+	return map[string]interface{}{branchName(branch, namespace): datum}, nil
+}
+
+func (r jsonReader) decodeRecord(schema *RecordSchema, value interface{}, namespace string) (interface{}, error) {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, r.err
+	}
+	namespace = namespaceOf(schema.FullName(namespace))
+	datum := make(map[string]interface{}, len(schema.Fields))
+	for _, field := range schema.Fields {
+		v, ok := values[field.Name]
+		if !ok {
+			if field.Default == nil {
+				return nil, r.err
+			}
+			fieldDatum, err := defaultDatum(field.Type, *field.Default, namespace)
+			if err != nil {
+				return nil, err
+			}
+			datum[field.Name] = fieldDatum
+			continue
+		}
+		fieldDatum, err := r.decode(field.Type, v, namespace)
+		if err != nil {
+			return nil, err
+		}
+		datum[field.Name] = fieldDatum
+	}
+	return datum, nil
+}
+
+func (r jsonReader) decodePrimitive(typeName Type, value interface{}) (interface{}, error) {
+	switch typeName {
+	case TypeNull:
+		if value != nil {
+			return nil, r.err
+		}
+		return nil, nil
+	case TypeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, r.err
+		}
+		return b, nil
+	case TypeInt32, TypeInt64:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, r.err
+		}
+		n, err := number.Int64()
+		if err != nil {
+			return nil, r.err
+		}
+		if typeName == TypeInt32 {
+			if n < math.MinInt32 || n > math.MaxInt32 {
+				return nil, r.err
+			}
+			return int32(n), nil
+		}
+		return n, nil
+	case TypeFloat32, TypeFloat64:
+		f, ok := r.float(value)
+		if !ok {
+			return nil, r.err
+		}
+		if typeName == TypeFloat32 {
+			return float32(f), nil
+		}
+		return f, nil
+	case TypeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, r.err
+		}
+		return s, nil
+	case TypeBytes:
+		s, ok := value.(string)
+		if !ok {
+			return nil, r.err
+		}
+		// bytes are represented by strings whose code points are the byte values (ISO-8859-1)
+		b := make([]byte, 0, len(s))
+		for _, c := range s {
+			if c > 0xff {
+				return nil, r.err
+			}
+			b = append(b, byte(c))
+		}
+		return b, nil
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+// float reads numbers as well as "NaN", "Infinity" and "-Infinity", JSON having no representation of them
+func (r jsonReader) float(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		switch v {
+		case "NaN":
+			return math.NaN(), true
+		case "Infinity":
+			return math.Inf(1), true
+		case "-Infinity":
+			return math.Inf(-1), true
+		}
+	}
+	return 0, false
+}
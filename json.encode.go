@@ -0,0 +1,232 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+)
+
+type jsonWriter struct {
+	buf *bytes.Buffer
+}
+
+func (w jsonWriter) encode(schema Schema, datum interface{}, namespace string) error {
+	switch t := schema.(type) {
+	case Type:
+		return w.encodePrimitive(t, datum)
+	case *DerivedPrimitiveSchema:
+		return w.encodePrimitive(t.Type, primitiveDatum(t, datum))
+	case *ReferenceSchema:
+		return w.encode(t.Schema, datum, namespaceOf(fullName(t.Name, "", namespace)))
+	case UnionSchema:
+		return w.encodeUnion(t, datum, namespace)
+	case *ArraySchema:
+		return w.encodeArray(t, datum, namespace)
+	case *MapSchema:
+		return w.encodeMap(t, datum, namespace)
+	case *RecordSchema:
+		return w.encodeRecord(t, datum, namespace)
+	case *EnumSchema:
+		symbol, ok := stringDatum(datum)
+		if !ok || !contains(t.Symbols, symbol) {
+			return ErrInvalidDatum
+		}
+		w.writeString(symbol)
+		return nil
+	case *FixedSchema:
+		b, ok := fixedDatum(t, datum)
+		if !ok {
+			return ErrInvalidDatum
+		}
+		w.writeBytes(b)
+		return nil
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+func (w jsonWriter) encodePrimitive(typeName Type, datum interface{}) error {
+	switch typeName {
+	case TypeNull:
+		if datum != nil {
+			return ErrInvalidDatum
+		}
+		w.buf.WriteString("null")
+	case TypeBoolean:
+		b, ok := datum.(bool)
+		if !ok {
+			return ErrInvalidDatum
+		}
+		w.buf.WriteString(strconv.FormatBool(b))
+	case TypeInt32, TypeInt64:
+		n, ok := int64Datum(datum)
+		if !ok || (typeName == TypeInt32 && (n < math.MinInt32 || n > math.MaxInt32)) {
+			return ErrInvalidDatum
+		}
+		w.buf.WriteString(strconv.FormatInt(n, 10))
+	case TypeFloat32, TypeFloat64:
+		f, ok := float64Datum(datum)
+		if !ok {
+			return ErrInvalidDatum
+		}
+		w.writeFloat(f, typeName)
+	case TypeString:
+		s, ok := stringDatum(datum)
+		if !ok {
+			return ErrInvalidDatum
+		}
+		w.writeString(s)
+	case TypeBytes:
+		b, ok := bytesDatum(datum)
+		if !ok {
+			return ErrInvalidDatum
+		}
+		w.writeBytes(b)
+	default:
+		return ErrUnsupportedType
+	}
+	return nil
+}
+
+func (w jsonWriter) encodeUnion(union UnionSchema, datum interface{}, namespace string) error {
+	i, value, ok := unionBranch(union, datum, namespace)
+	if ok {
+		return w.encodeBranch(union[i], value, namespace)
+	}
+	// the datum isn't wrapped: the first branch able to encode it is selected
+	length := w.buf.Len()
+	for _, branch := range union {
+		err := w.encodeBranch(branch, datum, namespace)
+		if err == nil {
+			return nil
+		}
+		w.buf.Truncate(length)
+	}
+	return ErrInvalidDatum
+}
+
+func (w jsonWriter) encodeBranch(branch Schema, datum interface{}, namespace string) error {
+	if branch.TypeName() == TypeNull {
+		return w.encode(branch, datum, namespace)
+	}
+	// as stated by the spec, branches are designated by the name of their type, logical types aside
+	name := branchName(branch, namespace)
+	if derived, ok := branch.(*DerivedPrimitiveSchema); ok {
+		name = string(derived.Type)
+	}
+	w.buf.WriteRune('{')
+	w.writeString(name)
+	w.buf.WriteRune(':')
+	err := w.encode(branch, datum, namespace)
+	if err != nil {
+		return err
+	}
+	w.buf.WriteRune('}')
+	return nil
+}
+
+func (w jsonWriter) encodeArray(schema *ArraySchema, datum interface{}, namespace string) error {
+	items, ok := arrayDatum(datum)
+	if !ok {
+		return ErrInvalidDatum
+	}
+	w.buf.WriteRune('[')
+	for i, item := range items {
+		if i > 0 {
+			w.buf.WriteRune(',')
+		}
+		err := w.encode(schema.Items, item, namespace)
+		if err != nil {
+			return err
+		}
+	}
+	w.buf.WriteRune(']')
+	return nil
+}
+
+func (w jsonWriter) encodeMap(schema *MapSchema, datum interface{}, namespace string) error {
+	values, ok := mapDatum(datum)
+	if !ok {
+		return ErrInvalidDatum
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	w.buf.WriteRune('{')
+	for i, key := range keys {
+		if i > 0 {
+			w.buf.WriteRune(',')
+		}
+		w.writeString(key)
+		w.buf.WriteRune(':')
+		err := w.encode(schema.Value, values[key], namespace)
+		if err != nil {
+			return err
+		}
+	}
+	w.buf.WriteRune('}')
+	return nil
+}
+
+func (w jsonWriter) encodeRecord(schema *RecordSchema, datum interface{}, namespace string) error {
+	record, ok := datum.(map[string]interface{})
+	if !ok {
+		return ErrInvalidDatum
+	}
+	namespace = namespaceOf(schema.FullName(namespace))
+	w.buf.WriteRune('{')
+	for i, field := range schema.Fields {
+		value, err := fieldDatum(field, record, namespace)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			w.buf.WriteRune(',')
+		}
+		w.writeString(field.Name)
+		w.buf.WriteRune(':')
+		err = w.encode(field.Type, value, namespace)
+		if err != nil {
+			return err
+		}
+	}
+	w.buf.WriteRune('}')
+	return nil
+}
+
+func (w jsonWriter) writeString(s string) {
+	encoder := json.NewEncoder(w.buf)
+	encoder.SetEscapeHTML(false)
+	// the encoder terminates each value with a newline
+	_ = encoder.Encode(s)
+	w.buf.Truncate(w.buf.Len() - 1)
+}
+
+// writeBytes writes a string whose code points are the byte values (ISO-8859-1)
+func (w jsonWriter) writeBytes(b []byte) {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	w.writeString(string(runes))
+}
+
+// writeFloat writes NaN and infinities as strings, JSON having no representation of them
+func (w jsonWriter) writeFloat(f float64, typeName Type) {
+	switch {
+	case math.IsNaN(f):
+		w.writeString("NaN")
+	case math.IsInf(f, 1):
+		w.writeString("Infinity")
+	case math.IsInf(f, -1):
+		w.writeString("-Infinity")
+	case typeName == TypeFloat32:
+		w.buf.WriteString(strconv.FormatFloat(f, 'g', -1, 32))
+	default:
+		w.buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+}
@@ -0,0 +1,42 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// EncodeJSON - appends the AVRO JSON encoding of the datum, according to the schema, to buf.
+// Unions are encoded as {"<branch name>": value}, bytes and fixed as strings
+// whose code points are the byte values (ISO-8859-1) and enums as their symbol.
+func EncodeJSON(buf []byte, schema Schema, datum interface{}) ([]byte, error) {
+	w := jsonWriter{buf: bytes.NewBuffer(buf)}
+	err := w.encode(schema, datum, "")
+	if err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// DecodeJSON - decodes a datum, according to the schema, from its AVRO JSON encoding.
+func DecodeJSON(buf []byte, schema Schema) (interface{}, error) {
+	value, err := unmarshalJSONValue(buf)
+	if err != nil {
+		return nil, ErrInvalidEncoding
+	}
+	return jsonReader{err: ErrInvalidEncoding}.decode(schema, value, "")
+}
+
+// unmarshalJSONValue unmarshals a single JSON value, keeping numbers as json.Number
+func unmarshalJSONValue(buf []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, ErrInvalidEncoding
+	}
+	return value, nil
+}
@@ -0,0 +1,106 @@
+package avro
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	cases := []struct {
+		schema   string
+		datum    interface{}
+		json     string
+		expected interface{}
+	}{
+		{`"null"`, nil, `null`, nil},
+		{`"boolean"`, true, `true`, true},
+		{`"int"`, 150, `150`, int32(150)},
+		{`"long"`, int64(-1) << 40, `-1099511627776`, int64(-1) << 40},
+		{`"float"`, float32(0.1), `0.1`, float32(0.1)},
+		{`"double"`, math.Inf(-1), `"-Infinity"`, math.Inf(-1)},
+		{`"bytes"`, []byte{0, 'a', 0xff}, `"\u0000aÿ"`, []byte{0, 'a', 0xff}},
+		{`"string"`, "<é>", `"<é>"`, "<é>"},
+		{`{"type":"array","items":"long"}`, []int{1, 2}, `[1,2]`, []interface{}{int64(1), int64(2)}},
+		{`{"type":"map","values":"string"}`, map[string]string{"b": "c", "a": "d"}, `{"a":"d","b":"c"}`, map[string]interface{}{"a": "d", "b": "c"}},
+		{`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]}`, "HEARTS", `"HEARTS"`, "HEARTS"},
+		{`{"type":"fixed","name":"two","size":2}`, [2]byte{'a', 'b'}, `"ab"`, []byte{'a', 'b'}},
+		{`["null","string"]`, nil, `null`, nil},
+		{`["null","string"]`, "foo", `{"string":"foo"}`, map[string]interface{}{"string": "foo"}},
+		{`["null",{"type":"record","namespace":"ns","name":"A","fields":[{"name":"f","type":"int"}]}]`, map[string]interface{}{"A": map[string]interface{}{"f": 1}}, `{"ns.A":{"f":1}}`, map[string]interface{}{"ns.A": map[string]interface{}{"f": int32(1)}}},
+		{`["null",{"type":"long","logicalType":"timestamp-millis"}]`, time.Unix(1, 0), `{"long":1000}`, map[string]interface{}{"long.timestamp-millis": time.Unix(1, 0).UTC()}},
+		{`{"type":"int","logicalType":"date"}`, epoch.Add(48 * time.Hour), `2`, epoch.Add(48 * time.Hour)},
+		{`{"type":"int","logicalType":"date"}`, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), `-719162`, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{`{"type":"int","logicalType":"date"}`, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), `2932896`, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{`{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}`, big.NewRat(-1, 100), `"ÿ"`, big.NewRat(-1, 100)},
+		{
+			`{"type":"record","name":"LongList","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"],"default":null}]}`,
+			map[string]interface{}{"value": 1, "next": map[string]interface{}{"LongList": map[string]interface{}{"value": 2}}},
+			`{"value":1,"next":{"LongList":{"value":2,"next":null}}}`,
+			map[string]interface{}{"value": int64(1), "next": map[string]interface{}{"LongList": map[string]interface{}{"value": int64(2), "next": nil}}},
+		},
+	}
+	for i, c := range cases {
+		schema := mustParseSchema(t, c.schema)
+		buf, err := EncodeJSON(nil, schema, c.datum)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if string(buf) != c.json {
+			t.Errorf("case %d - expected %s, got %s", i, c.json, buf)
+		}
+		datum, err := DecodeJSON(buf, schema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if r, ok := c.expected.(*big.Rat); ok {
+			if datum.(*big.Rat).Cmp(r) != 0 {
+				t.Errorf("case %d - expected %v, got %v", i, r, datum)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(datum, c.expected) {
+			t.Errorf("case %d - expected %#v, got %#v", i, c.expected, datum)
+		}
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	cases := []struct {
+		schema      string
+		json        string
+		expected    interface{}
+		expectedErr error
+	}{
+		{`"double"`, `"NaN"`, nil, nil},
+		{`{"type":"record","name":"R","fields":[{"name":"a","type":["string","null"],"default":"foo"}]}`, `{}`, map[string]interface{}{"a": map[string]interface{}{"string": "foo"}}, nil},
+		{`["null",{"type":"long","logicalType":"time-micros"}]`, `{"long.time-micros":1}`, map[string]interface{}{"long.time-micros": time.Microsecond}, nil},
+		{`"int"`, `2147483648`, nil, ErrInvalidEncoding},
+		{`"int"`, `1.5`, nil, ErrInvalidEncoding},
+		{`"int"`, `1 2`, nil, ErrInvalidEncoding},
+		{`"bytes"`, `"é€"`, nil, ErrInvalidEncoding},
+		{`["null","string"]`, `"foo"`, nil, ErrInvalidEncoding},
+		{`["null","string"]`, `{"int":1}`, nil, ErrInvalidEncoding},
+		{`"string"`, `null`, nil, ErrInvalidEncoding},
+		{`{"type":"enum","name":"Suit","symbols":["SPADES"]}`, `"HEARTS"`, nil, ErrInvalidEncoding},
+		{`{"type":"fixed","name":"two","size":2}`, `"a"`, nil, ErrInvalidEncoding},
+		{`{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`, `{}`, nil, ErrInvalidEncoding},
+	}
+	for i, c := range cases {
+		datum, err := DecodeJSON([]byte(c.json), mustParseSchema(t, c.schema))
+		if err != c.expectedErr {
+			t.Errorf("case %d - expected error %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if f, ok := datum.(float64); ok && math.IsNaN(f) {
+			continue
+		}
+		if !reflect.DeepEqual(datum, c.expected) {
+			t.Errorf("case %d - expected %#v, got %#v", i, c.expected, datum)
+		}
+	}
+}
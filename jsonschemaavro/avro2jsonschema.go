@@ -0,0 +1,295 @@
+package jsonschemaavro
+
+import (
+	"encoding/json"
+
+	"github.com/khezen/avro"
+)
+
+// Draft - dialect of the JSON Schema documents produced by AVRO2JSONSchema
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// AVRO2JSONSchema - converts the AVRO schema to a JSON Schema document, draft 2020-12, describing its data as plain JSON:
+//
+//   - records become objects without additional properties, fields without default being required
+//   - enums become string enums
+//   - unions become type lists when their branches are primitives, anyOf otherwise
+//   - maps become objects with additionalProperties
+//   - bytes and fixed become base64 encoded strings
+//   - logical types date, time, timestamp and uuid become formats of strings
+//   - named types are defined in $defs under their fullname and referred to with $ref
+//
+// Constructs which cannot be represented, such as aliases or decimals, are dropped or approximated and returned.
+func AVRO2JSONSchema(schema avro.Schema) ([]byte, []Unrepresentable, error) {
+	c := avroConverter{
+		defined:         make(map[string]bool),
+		unrepresentable: make([]Unrepresentable, 0),
+	}
+	root, err := c.convert(schema, "", "")
+	if err != nil {
+		return nil, nil, err
+	}
+	document := object{{"$schema", Draft}}
+	document = append(document, root...)
+	if len(c.defs) > 0 {
+		document.set("$defs", c.defs)
+	}
+	documentBytes, err := json.Marshal(document)
+	if err != nil {
+		return nil, nil, err
+	}
+	return documentBytes, c.unrepresentable, nil
+}
+
+type avroConverter struct {
+	// defs - definitions of the named types, by fullname
+	defs            object
+	defined         map[string]bool
+	unrepresentable []Unrepresentable
+}
+
+func (c *avroConverter) report(path, reason string) {
+	c.unrepresentable = append(c.unrepresentable, Unrepresentable{path, reason})
+}
+
+// reportDropped reports the aliases and custom properties of the schema at the path
+func (c *avroConverter) reportDropped(path string, aliases []string, properties avro.Properties) {
+	if len(aliases) > 0 {
+		c.report(path, "aliases are dropped")
+	}
+	if len(properties) > 0 {
+		c.report(path, "custom properties are dropped")
+	}
+}
+
+// convert returns the JSON Schema of the AVRO schema at the path, namespace being the enclosing namespace
+func (c *avroConverter) convert(schema avro.Schema, path, namespace string) (object, error) {
+	switch t := schema.(type) {
+	case avro.Type:
+		return c.convertType(t, namespace)
+	case *avro.DerivedPrimitiveSchema:
+		c.reportDropped(path, nil, t.Properties)
+		return c.convertLogicalType(t, path, namespace)
+	case *avro.ReferenceSchema:
+		return c.ref(t.Schema.FullName(namespace)), nil
+	case *avro.RecordSchema:
+		return c.define(t, path, namespace)
+	case *avro.EnumSchema:
+		return c.define(t, path, namespace)
+	case *avro.FixedSchema:
+		return c.define(t, path, namespace)
+	case *avro.ArraySchema:
+		c.reportDropped(path, nil, t.Properties)
+		items, err := c.convert(t.Items, joinPath(path, "items"), namespace)
+		if err != nil {
+			return nil, err
+		}
+		return object{{"type", "array"}, {"items", items}}, nil
+	case *avro.MapSchema:
+		c.reportDropped(path, nil, t.Properties)
+		values, err := c.convert(t.Value, joinPath(path, "values"), namespace)
+		if err != nil {
+			return nil, err
+		}
+		return object{{"type", "object"}, {"additionalProperties", values}}, nil
+	case avro.UnionSchema:
+		return c.convertUnion(t, path, namespace)
+	default:
+		return nil, ErrUnsupportedSchema
+	}
+}
+
+func (c *avroConverter) convertType(t avro.Type, namespace string) (object, error) {
+	switch t {
+	case avro.TypeNull:
+		return object{{"type", "null"}}, nil
+	case avro.TypeBoolean:
+		return object{{"type", "boolean"}}, nil
+	case avro.TypeInt32:
+		return object{{"type", "integer"}, {"format", "int32"}}, nil
+	case avro.TypeInt64:
+		return object{{"type", "integer"}, {"format", "int64"}}, nil
+	case avro.TypeFloat32:
+		return object{{"type", "number"}, {"format", "float"}}, nil
+	case avro.TypeFloat64:
+		return object{{"type", "number"}}, nil
+	case avro.TypeString:
+		return object{{"type", "string"}}, nil
+	case avro.TypeBytes:
+		return object{{"type", "string"}, {"contentEncoding", "base64"}}, nil
+	case avro.TypeRecord, avro.TypeError, avro.TypeEnum, avro.TypeFixed, avro.TypeArray, avro.TypeMap, avro.TypeUnion, "":
+		return nil, ErrUnsupportedSchema
+	}
+	// name of a named type defined earlier
+	fullname := string(t)
+	if namespace != "" && namespaceOf(fullname) == "" {
+		fullname = namespace + "." + fullname
+	}
+	if !c.defined[fullname] {
+		return nil, ErrUnsupportedSchema
+	}
+	return c.ref(fullname), nil
+}
+
+func (c *avroConverter) convertLogicalType(t *avro.DerivedPrimitiveSchema, path, namespace string) (object, error) {
+	switch t.LogicalType {
+	case avro.LogicalTypeDate:
+		return object{{"type", "string"}, {"format", "date"}}, nil
+	case avro.LogicalTypeTimeMillis, avro.LogicalTypeTimeMicros:
+		return object{{"type", "string"}, {"format", "time"}}, nil
+	case avro.LogicalTypeTimestampMillis, avro.LogicalTypeTimestampMicros:
+		return object{{"type", "string"}, {"format", "date-time"}}, nil
+	case avro.LogicalTypeUUID:
+		return object{{"type", "string"}, {"format", "uuid"}}, nil
+	case avro.LogicalTypeLocalTimestampMillis, avro.LogicalTypeLocalTimestampMicros:
+		c.report(path, "local timestamps have no time zone, as required by the date-time format, they are described as strings")
+		return object{{"type", "string"}}, nil
+	case avro.LogicalTypeDecimal:
+		c.report(path, "precision and scale of decimals are not enforced")
+		return object{{"type", "number"}}, nil
+	default:
+		return c.convertType(t.Type, namespace)
+	}
+}
+
+func (c *avroConverter) ref(fullname string) object {
+	return object{{"$ref", "#/$defs/" + fullname}}
+}
+
+// define adds the definition of the named type to $defs, if not already, and returns a reference to it
+func (c *avroConverter) define(schema avro.NamedSchema, path, namespace string) (object, error) {
+	fullname := schema.FullName(namespace)
+	if c.defined[fullname] {
+		return c.ref(fullname), nil
+	}
+	// the definition is known before it is converted so it can refer to itself
+	c.defined[fullname] = true
+	i := len(c.defs)
+	c.defs.set(fullname, nil)
+	namespace = namespaceOf(fullname)
+	var (
+		definition object
+		err        error
+	)
+	switch t := schema.(type) {
+	case *avro.RecordSchema:
+		c.reportDropped(path, t.Aliases, t.Properties)
+		definition, err = c.convertRecord(t, path, namespace)
+	case *avro.EnumSchema:
+		c.reportDropped(path, t.Aliases, t.Properties)
+		definition = object{{"type", "string"}}
+		if t.Documentation != "" {
+			definition.set("description", t.Documentation)
+		}
+		definition.set("enum", t.Symbols)
+		if t.Default != "" {
+			c.report(joinPath(path, "default"), "default of enums, used by readers for unknown symbols, is dropped")
+		}
+	case *avro.FixedSchema:
+		c.reportDropped(path, t.Aliases, t.Properties)
+		definition = c.convertFixed(t, path)
+	default:
+		return nil, ErrUnsupportedSchema
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.defs[i].value = definition
+	return c.ref(fullname), nil
+}
+
+func (c *avroConverter) convertRecord(t *avro.RecordSchema, path, namespace string) (object, error) {
+	definition := object{{"type", "object"}}
+	if t.Documentation != "" {
+		definition.set("description", t.Documentation)
+	}
+	properties, required := make(object, 0, len(t.Fields)), make([]string, 0, len(t.Fields))
+	for i, field := range t.Fields {
+		fieldPath := indexPath(joinPath(path, "fields"), i)
+		c.reportDropped(fieldPath, field.Aliases, field.Properties)
+		property, err := c.convert(field.Type, joinPath(fieldPath, "type"), namespace)
+		if err != nil {
+			return nil, err
+		}
+		if field.Documentation != "" {
+			property.set("description", field.Documentation)
+		}
+		switch {
+		case field.Default == nil:
+			required = append(required, field.Name)
+		case hasJSONDefault(field.Type):
+			property.set("default", *field.Default)
+		default:
+			c.report(joinPath(fieldPath, "default"), "default of bytes, fixed and logical types, encoded differently in AVRO, is dropped")
+		}
+		properties.set(field.Name, property)
+	}
+	definition.set("properties", properties)
+	if len(required) > 0 {
+		definition.set("required", required)
+	}
+	definition.set("additionalProperties", false)
+	return definition, nil
+}
+
+func (c *avroConverter) convertFixed(t *avro.FixedSchema, path string) object {
+	var definition object
+	switch t.LogicalType {
+	case avro.LogicalTypeDecimal:
+		c.report(path, "precision and scale of decimals are not enforced")
+		definition = object{{"type", "number"}}
+	case avro.LogicalTypeDuration:
+		c.report(path, "durations are described as base64 encoded strings")
+		fallthrough
+	default:
+		// base64 encoding with padding
+		length := 4 * ((t.Size + 2) / 3)
+		definition = object{{"type", "string"}, {"contentEncoding", "base64"}, {"minLength", length}, {"maxLength", length}}
+	}
+	if t.Documentation != "" {
+		definition.set("description", t.Documentation)
+	}
+	return definition
+}
+
+// convertUnion lists the types of unions of primitives, other unions become anyOf
+func (c *avroConverter) convertUnion(union avro.UnionSchema, path, namespace string) (object, error) {
+	if len(union) == 0 {
+		return nil, ErrUnsupportedSchema
+	}
+	branches := make([]interface{}, 0, len(union))
+	types := make([]string, 0, len(union))
+	for i, branch := range union {
+		branchSchema, err := c.convert(branch, indexPath(path, i), namespace)
+		if err != nil {
+			return nil, err
+		}
+		if typeName, ok := branchSchema[0].value.(string); ok && len(branchSchema) == 1 && branchSchema[0].key == "type" {
+			types = append(types, typeName)
+		}
+		branches = append(branches, branchSchema)
+	}
+	if len(types) == len(union) {
+		return object{{"type", types}}, nil
+	}
+	return object{{"anyOf", branches}}, nil
+}
+
+// hasJSONDefault reports whether the AVRO default of the type is also its JSON default:
+// bytes, fixed and logical types are encoded differently
+func hasJSONDefault(schema avro.Schema) bool {
+	if union, ok := schema.(avro.UnionSchema); ok && len(union) > 0 {
+		schema = union[0]
+	}
+	if reference, ok := schema.(*avro.ReferenceSchema); ok {
+		schema = reference.Schema
+	}
+	switch t := schema.(type) {
+	case *avro.FixedSchema:
+		return false
+	case *avro.DerivedPrimitiveSchema:
+		return t.LogicalType == "" || t.LogicalType == avro.LogicalTypeUUID || t.LogicalType == avro.LogicalTypeTime || t.LogicalType == avro.LogicalTypeTimestamp
+	default:
+		return schema.TypeName() != avro.TypeBytes
+	}
+}
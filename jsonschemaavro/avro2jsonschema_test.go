@@ -0,0 +1,153 @@
+package jsonschemaavro
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/khezen/avro"
+)
+
+func TestAVRO2JSONSchema(t *testing.T) {
+	cases := []struct {
+		schema                string
+		expectedDocument      string
+		expectedUnrepresented []string
+	}{
+		{`"null"`, `{"$schema":"` + Draft + `","type":"null"}`, []string{}},
+		{`"int"`, `{"$schema":"` + Draft + `","type":"integer","format":"int32"}`, []string{}},
+		{`"long"`, `{"$schema":"` + Draft + `","type":"integer","format":"int64"}`, []string{}},
+		{`"float"`, `{"$schema":"` + Draft + `","type":"number","format":"float"}`, []string{}},
+		{`"double"`, `{"$schema":"` + Draft + `","type":"number"}`, []string{}},
+		{`"bytes"`, `{"$schema":"` + Draft + `","type":"string","contentEncoding":"base64"}`, []string{}},
+		{`{"type":"int","logicalType":"date"}`, `{"$schema":"` + Draft + `","type":"string","format":"date"}`, []string{}},
+		{`{"type":"long","logicalType":"time-micros"}`, `{"$schema":"` + Draft + `","type":"string","format":"time"}`, []string{}},
+		{`{"type":"long","logicalType":"timestamp-millis"}`, `{"$schema":"` + Draft + `","type":"string","format":"date-time"}`, []string{}},
+		{`{"type":"string","logicalType":"uuid"}`, `{"$schema":"` + Draft + `","type":"string","format":"uuid"}`, []string{}},
+		{`{"type":"long","logicalType":"local-timestamp-millis"}`, `{"$schema":"` + Draft + `","type":"string"}`, []string{""}},
+		{`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`, `{"$schema":"` + Draft + `","type":"number"}`, []string{""}},
+		{`{"type":"array","items":"string"}`, `{"$schema":"` + Draft + `","type":"array","items":{"type":"string"}}`, []string{}},
+		{`{"type":"map","values":"boolean","owner":"team"}`, `{"$schema":"` + Draft + `","type":"object","additionalProperties":{"type":"boolean"}}`, []string{""}},
+		{`["null","string"]`, `{"$schema":"` + Draft + `","type":["null","string"]}`, []string{}},
+		{`["null",{"type":"array","items":"int"}]`,
+			`{"$schema":"` + Draft + `","anyOf":[{"type":"null"},{"type":"array","items":{"type":"integer","format":"int32"}}]}`, []string{}},
+		{`{"type":"enum","name":"Suit","namespace":"cards","doc":"a suit","symbols":["SPADES","HEARTS"],"default":"SPADES"}`,
+			`{"$schema":"` + Draft + `","$ref":"#/$defs/cards.Suit","$defs":{"cards.Suit":{"type":"string","description":"a suit","enum":["SPADES","HEARTS"]}}}`, []string{"default"}},
+		{`{"type":"fixed","name":"MD5","size":16,"doc":"a hash"}`,
+			`{"$schema":"` + Draft + `","$ref":"#/$defs/MD5","$defs":{"MD5":{"type":"string","contentEncoding":"base64","minLength":24,"maxLength":24,"description":"a hash"}}}`, []string{}},
+		{`{"type":"fixed","name":"Duration","size":12,"logicalType":"duration"}`,
+			`{"$schema":"` + Draft + `","$ref":"#/$defs/Duration","$defs":{"Duration":{"type":"string","contentEncoding":"base64","minLength":16,"maxLength":16}}}`, []string{""}},
+		{`{"type":"fixed","name":"Amount","size":8,"logicalType":"decimal","precision":10,"aliases":["Money"]}`,
+			`{"$schema":"` + Draft + `","$ref":"#/$defs/Amount","$defs":{"Amount":{"type":"number"}}}`, []string{"", ""}},
+		{`{"type":"record","name":"User","namespace":"test","doc":"a user","fields":[
+				{"name":"id","type":{"type":"string","logicalType":"uuid"},"default":"00000000-0000-0000-0000-000000000000"},
+				{"name":"name","type":"string","doc":"full name","aliases":["fullname"]},
+				{"name":"age","type":"int","default":18},
+				{"name":"hash","type":{"type":"fixed","name":"MD5","size":16},"default":"\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000"},
+				{"name":"created","type":{"type":"long","logicalType":"timestamp-millis"},"default":0},
+				{"name":"email","type":["null","string"],"default":null},
+				{"name":"previous","type":["null","MD5"],"default":null},
+				{"name":"friends","type":{"type":"array","items":"User"}}]}`,
+			`{"$schema":"` + Draft + `","$ref":"#/$defs/test.User","$defs":{
+				"test.User":{"type":"object","description":"a user","properties":{
+					"id":{"type":"string","format":"uuid","default":"00000000-0000-0000-0000-000000000000"},
+					"name":{"type":"string","description":"full name"},
+					"age":{"type":"integer","format":"int32","default":18},
+					"hash":{"$ref":"#/$defs/test.MD5"},
+					"created":{"type":"string","format":"date-time"},
+					"email":{"type":["null","string"],"default":null},
+					"previous":{"anyOf":[{"type":"null"},{"$ref":"#/$defs/test.MD5"}],"default":null},
+					"friends":{"type":"array","items":{"$ref":"#/$defs/test.User"}}},
+				"required":["name","friends"],"additionalProperties":false},
+				"test.MD5":{"type":"string","contentEncoding":"base64","minLength":24,"maxLength":24}}}`,
+			[]string{"fields[1]", "fields[3].default", "fields[4].default"}},
+	}
+	for i, c := range cases {
+		var anySchema avro.AnySchema
+		err := json.Unmarshal([]byte(c.schema), &anySchema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		document, unrepresentable, err := AVRO2JSONSchema(anySchema.Schema())
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		var expected, got interface{}
+		json.Unmarshal([]byte(c.expectedDocument), &expected)
+		json.Unmarshal(document, &got)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("case %d - expected:\n%s\ngot:\n%s", i, c.expectedDocument, document)
+		}
+		if paths := unrepresentablePaths(unrepresentable); !reflect.DeepEqual(paths, c.expectedUnrepresented) {
+			t.Errorf("case %d - expected unrepresentable %v, got %v", i, c.expectedUnrepresented, unrepresentable)
+		}
+	}
+}
+
+func TestAVRO2JSONSchemaErrors(t *testing.T) {
+	cases := []avro.Schema{
+		nil,
+		avro.Type("Unknown"),
+		avro.TypeRecord,
+		avro.UnionSchema{},
+		&avro.ArraySchema{Type: avro.TypeArray, Items: avro.Type("Unknown")},
+		&avro.MapSchema{Type: avro.TypeMap, Value: avro.Type("Unknown")},
+		avro.UnionSchema{avro.TypeNull, avro.Type("Unknown")},
+		&avro.RecordSchema{Type: avro.TypeRecord, Name: "R", Fields: []avro.RecordFieldSchema{{Name: "f", Type: avro.Type("Unknown")}}},
+	}
+	for i, schema := range cases {
+		_, _, err := AVRO2JSONSchema(schema)
+		if err != ErrUnsupportedSchema {
+			t.Errorf("case %d - expected %v, got %v", i, ErrUnsupportedSchema, err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	cases := []string{
+		`"string"`,
+		`["null","long","string"]`,
+		`{"type":"map","values":{"type":"array","items":"double"}}`,
+		`{"type":"record","name":"Node","namespace":"tree","fields":[
+			{"name":"label","type":"string"},
+			{"name":"kind","type":{"type":"enum","name":"Kind","symbols":["LEAF","BRANCH"]}},
+			{"name":"day","type":{"type":"int","logicalType":"date"}},
+			{"name":"weight","type":["null","float"],"default":null},
+			{"name":"children","type":{"type":"array","items":"Node"}},
+			{"name":"parent","type":["null","Node"],"default":null}]}`,
+	}
+	for i, c := range cases {
+		var anySchema avro.AnySchema
+		err := json.Unmarshal([]byte(c), &anySchema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		schema := anySchema.Schema()
+		document, _, err := AVRO2JSONSchema(schema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		converted, _, err := JSONSchema2AVRO(document, "", "")
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		expected, err := avro.CanonicalForm(schema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		got, err := avro.CanonicalForm(converted)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if string(got) != string(expected) {
+			t.Errorf("case %d - expected:\n%s\ngot:\n%s\nfrom:\n%s", i, expected, got, document)
+		}
+	}
+}
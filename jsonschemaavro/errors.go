@@ -0,0 +1,12 @@
+package jsonschemaavro
+
+import "errors"
+
+var (
+	// ErrInvalidJSONSchema - the document is not a JSON Schema
+	ErrInvalidJSONSchema = errors.New("ErrInvalidJSONSchema")
+	// ErrUnresolvedRef - a local $ref points to nothing in the document
+	ErrUnresolvedRef = errors.New("ErrUnresolvedRef")
+	// ErrUnsupportedSchema - the AVRO schema is not one of the types defined by the avro package
+	ErrUnsupportedSchema = errors.New("ErrUnsupportedSchema")
+)
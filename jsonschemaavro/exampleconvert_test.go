@@ -0,0 +1,41 @@
+package jsonschemaavro_test
+
+import (
+	"fmt"
+
+	"github.com/khezen/avro"
+	"github.com/khezen/avro/jsonschemaavro"
+)
+
+// ExampleJSONSchema2AVRO -
+func ExampleJSONSchema2AVRO() {
+	document := []byte(`
+	{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Post",
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"title": {"type": "string", "maxLength": 140},
+			"published": {"type": "string", "format": "date-time"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["id", "title", "published"]
+	}`)
+	schema, unrepresentable, err := jsonschemaavro.JSONSchema2AVRO(document, "", "blog")
+	if err != nil {
+		panic(err)
+	}
+	schemaBytes, err := avro.MarshalSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(schemaBytes))
+	for _, u := range unrepresentable {
+		fmt.Println(u)
+	}
+
+	// Output:
+	// {"type":"record","namespace":"blog","name":"Post","fields":[{"name":"id","type":{"type":"string","logicalType":"uuid"}},{"name":"title","type":"string"},{"name":"published","type":{"type":"long","logicalType":"timestamp-millis"}},{"name":"tags","type":["null",{"type":"array","items":"string"}],"default":null}]}
+	// #/properties/title/maxLength: maxLength is not enforced by AVRO
+}
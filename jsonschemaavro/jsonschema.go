@@ -0,0 +1,126 @@
+package jsonschemaavro
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Unrepresentable - construct of the source schema without equivalent in the target one, which was dropped or approximated
+type Unrepresentable struct {
+	// Path - location of the construct in the source schema:
+	// a JSON pointer such as "#/properties/tags/uniqueItems" for JSON Schemas,
+	// a path such as "fields[3].type[1]" for AVRO schemas
+	Path string
+	// Reason - what was dropped or approximated
+	Reason string
+}
+
+func (u Unrepresentable) String() string {
+	return u.Path + ": " + u.Reason
+}
+
+// object - JSON object keeping the order of its members
+type object []member
+
+type member struct {
+	key   string
+	value interface{}
+}
+
+func (o *object) set(key string, value interface{}) {
+	*o = append(*o, member{key, value})
+}
+
+// MarshalJSON - members are written in order
+func (o object) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteRune('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteString(strconv.Quote(m.key))
+		buf.WriteRune(':')
+		valueBytes, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(valueBytes)
+	}
+	buf.WriteRune('}')
+	return buf.Bytes(), nil
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// joinPointer appends the escaped token to the JSON pointer
+func joinPointer(pointer string, token string) string {
+	return pointer + "/" + pointerEscaper.Replace(token)
+}
+
+func indexPointer(pointer string, i int) string {
+	return pointer + "/" + strconv.Itoa(i)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// avroName turns the string into a valid AVRO name, replacing invalid characters with underscores
+func avroName(s string) string {
+	buf := new(strings.Builder)
+	for i, r := range s {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			buf.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				buf.WriteRune('_')
+			}
+			buf.WriteRune(r)
+		default:
+			buf.WriteRune('_')
+		}
+	}
+	if buf.Len() == 0 {
+		return "_"
+	}
+	return buf.String()
+}
+
+// isAVROName reports whether the string is a valid AVRO name
+func isAVROName(s string) bool {
+	return s != "" && avroName(s) == s
+}
+
+// avroFullName turns the string into a valid AVRO fullname, each part being a valid name
+func avroFullName(s string) string {
+	parts := strings.Split(s, ".")
+	for i := range parts {
+		parts[i] = avroName(parts[i])
+	}
+	return strings.Join(parts, ".")
+}
+
+func namespaceOf(fullname string) string {
+	i := strings.LastIndex(fullname, ".")
+	if i < 0 {
+		return ""
+	}
+	return fullname[:i]
+}
+
+func nameOf(fullname string) string {
+	return fullname[strings.LastIndex(fullname, ".")+1:]
+}
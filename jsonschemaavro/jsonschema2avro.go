@@ -0,0 +1,657 @@
+package jsonschemaavro
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/khezen/avro"
+	"github.com/valyala/fastjson"
+)
+
+// droppedKeywords - validation keywords of JSON Schema without AVRO equivalent
+var droppedKeywords = []string{
+	"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+	"minLength", "maxLength", "pattern",
+	"minItems", "maxItems", "uniqueItems", "contains", "minContains", "maxContains", "additionalItems", "unevaluatedItems",
+	"minProperties", "maxProperties", "patternProperties", "propertyNames", "unevaluatedProperties",
+	"dependencies", "dependentRequired", "dependentSchemas",
+	"if", "then", "else", "not",
+	"$dynamicRef", "$recursiveRef",
+}
+
+var nullDefault = json.RawMessage("null")
+
+// JSONSchema2AVRO - converts a JSON Schema document, draft-07 or 2020-12, to an AVRO schema:
+//
+//   - objects with properties become records, properties which are not required being nullable with a null default
+//   - objects with additionalProperties only become maps
+//   - string enums become enums, other enums and const are not enforced
+//   - oneOf, anyOf and nullable types become unions
+//   - formats date-time, date, time and uuid become logical types, base64 encoded strings become bytes
+//   - local $ref, to $defs or definitions, become named types
+//
+// Records and enums are named after their title, their key in $defs or their property name.
+// The root schema is named after the given name, if not empty, and namespace is the one of the named types without namespace.
+// Constructs which cannot be represented, such as validation keywords, are dropped or approximated and returned.
+func JSONSchema2AVRO(document []byte, name, namespace string) (avro.Schema, []Unrepresentable, error) {
+	var parser fastjson.Parser
+	root, err := parser.ParseBytes(document)
+	if err != nil {
+		return nil, nil, ErrInvalidJSONSchema
+	}
+	c := jsonSchemaConverter{
+		root:            root,
+		rootName:        name,
+		namespace:       namespace,
+		schemas:         make(map[string]avro.Schema),
+		named:           make(map[string]avro.NamedSchema),
+		converting:      make(map[string]bool),
+		taken:           make(map[string]bool),
+		unrepresentable: make([]Unrepresentable, 0),
+	}
+	schema, err := c.convert(root, "#", c.rootHint())
+	if err != nil {
+		return nil, nil, err
+	}
+	return schema, c.unrepresentable, nil
+}
+
+type jsonSchemaConverter struct {
+	root      *fastjson.Value
+	rootName  string
+	namespace string
+	// schemas - converted schemas, by JSON pointer
+	schemas map[string]avro.Schema
+	// named - records being converted, by JSON pointer, so that their fields can refer to them
+	named map[string]avro.NamedSchema
+	// converting - JSON pointers of the schemas being converted
+	converting map[string]bool
+	// taken - fullnames of the named types defined so far
+	taken           map[string]bool
+	unrepresentable []Unrepresentable
+}
+
+func (c *jsonSchemaConverter) report(pointer, reason string) {
+	c.unrepresentable = append(c.unrepresentable, Unrepresentable{pointer, reason})
+}
+
+// convert converts the schema at the JSON pointer, named types being named after hint if they have no title.
+// The named types of a schema met again are referred to by name.
+func (c *jsonSchemaConverter) convert(node *fastjson.Value, pointer, hint string) (avro.Schema, error) {
+	if schema, ok := c.schemas[pointer]; ok {
+		return referenced(schema), nil
+	}
+	if named, ok := c.named[pointer]; ok {
+		// the record refers to itself, null is kept if it is nullable
+		if types, err := c.types(node); err == nil && contains(types, "null") {
+			return avro.UnionSchema{avro.TypeNull, reference(named)}, nil
+		}
+		return reference(named), nil
+	}
+	if c.converting[pointer] {
+		c.report(pointer, "recursive schema which is not an object is described as a string")
+		return avro.TypeString, nil
+	}
+	switch node.Type() {
+	case fastjson.TypeTrue:
+		c.report(pointer, "any value is valid, described as a string")
+		return avro.TypeString, nil
+	case fastjson.TypeFalse:
+		c.report(pointer, "no value is valid, described as null")
+		return avro.TypeNull, nil
+	case fastjson.TypeObject:
+	default:
+		return nil, ErrInvalidJSONSchema
+	}
+	c.converting[pointer] = true
+	defer delete(c.converting, pointer)
+	for _, keyword := range droppedKeywords {
+		if node.Exists(keyword) {
+			c.report(joinPointer(pointer, keyword), keyword+" is not enforced by AVRO")
+		}
+	}
+	schema, err := c.convertNode(node, pointer, hint)
+	if err != nil {
+		return nil, err
+	}
+	c.schemas[pointer] = schema
+	return schema, nil
+}
+
+func reference(named avro.NamedSchema) *avro.ReferenceSchema {
+	return &avro.ReferenceSchema{Name: named.FullName(""), Schema: named}
+}
+
+// referenced returns the schema converted earlier, its named types and the ones of its union being referred to by name
+func referenced(schema avro.Schema) avro.Schema {
+	switch t := schema.(type) {
+	case avro.NamedSchema:
+		return reference(t)
+	case avro.UnionSchema:
+		union := make(avro.UnionSchema, 0, len(t))
+		for _, branch := range t {
+			union = append(union, referenced(branch))
+		}
+		return union
+	default:
+		return schema
+	}
+}
+
+func (c *jsonSchemaConverter) convertNode(node *fastjson.Value, pointer, hint string) (avro.Schema, error) {
+	if ref := node.Get("$ref"); ref != nil {
+		return c.convertRef(ref, pointer)
+	}
+	if allOf := node.Get("allOf"); allOf != nil {
+		return c.convertAllOf(node, allOf, pointer, hint)
+	}
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if branchesValue := node.Get(keyword); branchesValue != nil {
+			branchValues, err := branchesValue.Array()
+			if err != nil || len(branchValues) == 0 {
+				return nil, ErrInvalidJSONSchema
+			}
+			branches := make([]avro.Schema, 0, len(branchValues)+1)
+			for i, branchValue := range branchValues {
+				branch, err := c.convert(branchValue, indexPointer(joinPointer(pointer, keyword), i), hint)
+				if err != nil {
+					return nil, err
+				}
+				branches = append(branches, branch)
+			}
+			if node.GetBool("nullable") {
+				branches = append(branches, avro.TypeNull)
+			}
+			return c.union(pointer, branches), nil
+		}
+	}
+	types, err := c.types(node)
+	if err != nil {
+		return nil, err
+	}
+	if node.Exists("enum") || node.Exists("const") {
+		schema, ok, err := c.convertEnum(node, pointer, hint, types)
+		if err != nil || ok {
+			return schema, err
+		}
+	}
+	branches := make([]avro.Schema, 0, len(types))
+	for _, typeName := range types {
+		branch, err := c.convertType(node, pointer, hint, typeName)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, branch)
+	}
+	return c.union(pointer, branches), nil
+}
+
+// types returns the JSON types of the schema, inferred from its keywords if it has none
+func (c *jsonSchemaConverter) types(node *fastjson.Value) ([]string, error) {
+	var types []string
+	typeValue := node.Get("type")
+	switch {
+	case typeValue == nil:
+	case typeValue.Type() == fastjson.TypeString:
+		types = append(types, string(typeValue.GetStringBytes()))
+	case typeValue.Type() == fastjson.TypeArray:
+		for _, value := range typeValue.GetArray() {
+			typeBytes, err := value.StringBytes()
+			if err != nil {
+				return nil, ErrInvalidJSONSchema
+			}
+			types = append(types, string(typeBytes))
+		}
+	default:
+		return nil, ErrInvalidJSONSchema
+	}
+	if len(types) == 0 {
+		switch {
+		case node.Exists("properties") || node.Exists("additionalProperties"):
+			types = append(types, "object")
+		case node.Exists("items") || node.Exists("prefixItems"):
+			types = append(types, "array")
+		case node.Exists("format") || node.Exists("contentEncoding"):
+			types = append(types, "string")
+		default:
+			for _, value := range append(node.GetArray("enum"), node.Get("const")) {
+				if value != nil {
+					types = append(types, jsonType(value))
+				}
+			}
+		}
+	}
+	if node.GetBool("nullable") && !contains(types, "null") {
+		types = append(types, "null")
+	}
+	if len(types) == 0 {
+		types = append(types, "")
+	}
+	return types, nil
+}
+
+func jsonType(value *fastjson.Value) string {
+	switch value.Type() {
+	case fastjson.TypeNull:
+		return "null"
+	case fastjson.TypeTrue, fastjson.TypeFalse:
+		return "boolean"
+	case fastjson.TypeNumber:
+		return "number"
+	case fastjson.TypeString:
+		return "string"
+	case fastjson.TypeArray:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *jsonSchemaConverter) convertType(node *fastjson.Value, pointer, hint, typeName string) (avro.Schema, error) {
+	format := string(node.GetStringBytes("format"))
+	switch typeName {
+	case "null":
+		return avro.TypeNull, nil
+	case "boolean":
+		return avro.TypeBoolean, nil
+	case "integer":
+		if format == "int32" {
+			return avro.TypeInt32, nil
+		}
+		return avro.TypeInt64, nil
+	case "number":
+		if format == "float" {
+			return avro.TypeFloat32, nil
+		}
+		return avro.TypeFloat64, nil
+	case "string":
+		switch format {
+		case "date-time":
+			return &avro.DerivedPrimitiveSchema{Type: avro.TypeInt64, LogicalType: avro.LogicalTypeTimestampMillis}, nil
+		case "date":
+			return &avro.DerivedPrimitiveSchema{Type: avro.TypeInt32, LogicalType: avro.LogicalTypeDate}, nil
+		case "time":
+			return &avro.DerivedPrimitiveSchema{Type: avro.TypeInt32, LogicalType: avro.LogicalTypeTimeMillis}, nil
+		case "uuid":
+			return &avro.DerivedPrimitiveSchema{Type: avro.TypeString, LogicalType: avro.LogicalTypeUUID}, nil
+		}
+		if string(node.GetStringBytes("contentEncoding")) == "base64" {
+			return avro.TypeBytes, nil
+		}
+		return avro.TypeString, nil
+	case "array":
+		return c.convertArray(node, pointer, hint)
+	case "object":
+		return c.convertObject(node, pointer, hint)
+	case "":
+		c.report(pointer, "any value is valid, described as a string")
+		return avro.TypeString, nil
+	default:
+		return nil, ErrInvalidJSONSchema
+	}
+}
+
+// convertRef converts the schema pointed by the local reference, external references being described as strings
+func (c *jsonSchemaConverter) convertRef(ref *fastjson.Value, pointer string) (avro.Schema, error) {
+	refBytes, err := ref.StringBytes()
+	if err != nil {
+		return nil, ErrInvalidJSONSchema
+	}
+	fragment, err := url.PathUnescape(string(refBytes))
+	if err != nil || !strings.HasPrefix(fragment, "#") || (len(fragment) > 1 && fragment[1] != '/') {
+		c.report(joinPointer(pointer, "$ref"), "reference "+string(refBytes)+" is not resolved, described as a string")
+		return avro.TypeString, nil
+	}
+	target, targetPointer, hint := c.root, "#", c.rootHint()
+	if len(fragment) > 1 {
+		tokens := strings.Split(fragment[2:], "/")
+		for i := range tokens {
+			tokens[i] = pointerUnescaper.Replace(tokens[i])
+			targetPointer = joinPointer(targetPointer, tokens[i])
+		}
+		target, hint = c.root.Get(tokens...), tokens[len(tokens)-1]
+	}
+	if target == nil {
+		return nil, ErrUnresolvedRef
+	}
+	return c.convert(target, targetPointer, hint)
+}
+
+// convertAllOf merges the properties of the objects of allOf.
+// Other schemas cannot be merged, the first one is used.
+func (c *jsonSchemaConverter) convertAllOf(node, allOf *fastjson.Value, pointer, hint string) (avro.Schema, error) {
+	branchValues, err := allOf.Array()
+	if err != nil || len(branchValues) == 0 {
+		return nil, ErrInvalidJSONSchema
+	}
+	if len(branchValues) == 1 && !node.Exists("properties") {
+		return c.convert(branchValues[0], indexPointer(joinPointer(pointer, "allOf"), 0), hint)
+	}
+	var arena fastjson.Arena
+	merged, properties, required := arena.NewObject(), arena.NewObject(), arena.NewArray()
+	requiredCount := 0
+	for _, value := range append([]*fastjson.Value{node}, branchValues...) {
+		value = c.dereference(value)
+		if value == nil || value.Type() != fastjson.TypeObject || (value != node && !value.Exists("properties")) {
+			c.report(joinPointer(pointer, "allOf"), "allOf of schemas which are not objects is not represented, the first schema is used")
+			return c.convert(branchValues[0], indexPointer(joinPointer(pointer, "allOf"), 0), hint)
+		}
+		if object := value.GetObject("properties"); object != nil {
+			object.Visit(func(key []byte, property *fastjson.Value) {
+				properties.Set(string(key), property)
+			})
+		}
+		for _, name := range value.GetArray("required") {
+			required.SetArrayItem(requiredCount, name)
+			requiredCount++
+		}
+	}
+	for _, keyword := range []string{"title", "description"} {
+		if value := node.Get(keyword); value != nil {
+			merged.Set(keyword, value)
+		}
+	}
+	merged.Set("properties", properties)
+	merged.Set("required", required)
+	merged.Set("additionalProperties", arena.NewFalse())
+	return c.convertObject(merged, pointer, hint)
+}
+
+// maxRefs - maximum number of references followed in a row, so that cycles end
+const maxRefs = 32
+
+// dereference follows the local references of the schema, nil if they cannot be resolved
+func (c *jsonSchemaConverter) dereference(value *fastjson.Value) *fastjson.Value {
+	for i := 0; value != nil && value.Exists("$ref"); i++ {
+		fragment, err := url.PathUnescape(string(value.GetStringBytes("$ref")))
+		if err != nil || !strings.HasPrefix(fragment, "#") || i == maxRefs {
+			return nil
+		}
+		if fragment == "#" {
+			return c.root
+		}
+		if !strings.HasPrefix(fragment, "#/") {
+			return nil
+		}
+		tokens := strings.Split(fragment[2:], "/")
+		for i := range tokens {
+			tokens[i] = pointerUnescaper.Replace(tokens[i])
+		}
+		value = c.root.Get(tokens...)
+	}
+	return value
+}
+
+// convertEnum converts string enums and const to AVRO enums, false is returned for other values which cannot be enforced
+func (c *jsonSchemaConverter) convertEnum(node *fastjson.Value, pointer, hint string, types []string) (avro.Schema, bool, error) {
+	values := node.GetArray("enum")
+	if node.Exists("const") {
+		values = []*fastjson.Value{node.Get("const")}
+	}
+	nullable := contains(types, "null")
+	symbols := make([]string, 0, len(values))
+	for _, value := range values {
+		if value.Type() == fastjson.TypeNull {
+			nullable = true
+			continue
+		}
+		symbol, err := value.StringBytes()
+		if err != nil || !isAVROName(string(symbol)) || contains(symbols, string(symbol)) {
+			symbols = nil
+			break
+		}
+		symbols = append(symbols, string(symbol))
+	}
+	if len(symbols) == 0 {
+		c.report(pointer, "enum of values which are not valid AVRO enum symbols is not enforced")
+		return nil, false, nil
+	}
+	name, namespace := c.namedType(node, pointer, hint)
+	enum := &avro.EnumSchema{
+		Type:          avro.TypeEnum,
+		Name:          name,
+		Namespace:     namespace,
+		Documentation: string(node.GetStringBytes("description")),
+		Symbols:       symbols,
+	}
+	if nullable {
+		return avro.UnionSchema{avro.TypeNull, enum}, true, nil
+	}
+	return enum, true, nil
+}
+
+func (c *jsonSchemaConverter) convertArray(node *fastjson.Value, pointer, hint string) (avro.Schema, error) {
+	items := node.Get("items")
+	switch {
+	case node.Exists("prefixItems") || (items != nil && items.Type() == fastjson.TypeArray):
+		c.report(pointer, "tuples are not represented, items are described as strings")
+		items = nil
+	case items == nil:
+		c.report(pointer, "items of any type are described as strings")
+	}
+	if items == nil {
+		return &avro.ArraySchema{Type: avro.TypeArray, Items: avro.TypeString}, nil
+	}
+	itemsSchema, err := c.convert(items, joinPointer(pointer, "items"), hint+"Item")
+	if err != nil {
+		return nil, err
+	}
+	return &avro.ArraySchema{Type: avro.TypeArray, Items: itemsSchema}, nil
+}
+
+func (c *jsonSchemaConverter) convertObject(node *fastjson.Value, pointer, hint string) (avro.Schema, error) {
+	properties, additional := node.Get("properties"), node.Get("additionalProperties")
+	if properties == nil && (additional == nil || additional.Type() != fastjson.TypeFalse) {
+		if additional == nil || additional.Type() == fastjson.TypeTrue {
+			c.report(pointer, "properties of any type are described as a map of strings")
+			return &avro.MapSchema{Type: avro.TypeMap, Value: avro.TypeString}, nil
+		}
+		values, err := c.convert(additional, joinPointer(pointer, "additionalProperties"), hint+"Value")
+		if err != nil {
+			return nil, err
+		}
+		return &avro.MapSchema{Type: avro.TypeMap, Value: values}, nil
+	}
+	if properties != nil && additional != nil && additional.Type() != fastjson.TypeFalse {
+		c.report(joinPointer(pointer, "additionalProperties"), "additional properties are not represented along with properties")
+	}
+	name, namespace := c.namedType(node, pointer, hint)
+	record := &avro.RecordSchema{
+		Type:          avro.TypeRecord,
+		Name:          name,
+		Namespace:     namespace,
+		Documentation: string(node.GetStringBytes("description")),
+		Fields:        make([]avro.RecordFieldSchema, 0),
+	}
+	// the record is known before its fields so they can refer to it
+	c.named[pointer] = record
+	defer delete(c.named, pointer)
+	var (
+		keys   []string
+		values []*fastjson.Value
+	)
+	if properties != nil {
+		object, err := properties.Object()
+		if err != nil {
+			return nil, ErrInvalidJSONSchema
+		}
+		object.Visit(func(key []byte, value *fastjson.Value) {
+			keys = append(keys, string(key))
+			values = append(values, value)
+		})
+	}
+	required := make(map[string]bool)
+	for _, value := range node.GetArray("required") {
+		required[string(value.GetStringBytes())] = true
+	}
+	for i, key := range keys {
+		propertyPointer := joinPointer(joinPointer(pointer, "properties"), key)
+		if !isAVROName(key) {
+			c.report(propertyPointer, "property name is not a valid AVRO name, the property is dropped")
+			continue
+		}
+		fieldType, err := c.convert(values[i], propertyPointer, capitalize(key))
+		if err != nil {
+			return nil, err
+		}
+		field := avro.RecordFieldSchema{
+			Name:          key,
+			Documentation: string(values[i].GetStringBytes("description")),
+			Type:          fieldType,
+		}
+		c.fieldDefault(&field, values[i].Get("default"), propertyPointer, required[key])
+		record.Fields = append(record.Fields, field)
+	}
+	return record, nil
+}
+
+// fieldDefault sets the default of the field: properties which are not required are nullable and null by default,
+// unless they have another default, which is kept only if it matches the AVRO type.
+func (c *jsonSchemaConverter) fieldDefault(field *avro.RecordFieldSchema, defaultValue *fastjson.Value, pointer string, required bool) {
+	isNull := defaultValue != nil && defaultValue.Type() == fastjson.TypeNull
+	if defaultValue != nil && !isNull {
+		fieldType := field.Type
+		if !required {
+			fieldType = withNull(fieldType, false)
+		}
+		rawDefault := json.RawMessage(defaultValue.MarshalTo(nil))
+		if avro.ValidateDefault(fieldType, rawDefault) == nil {
+			field.Type, field.Default = fieldType, &rawDefault
+			return
+		}
+		c.report(joinPointer(pointer, "default"), "default doesn't match the AVRO type, it is dropped")
+	}
+	if !required || (isNull && isNullable(field.Type)) {
+		field.Type, field.Default = withNull(field.Type, true), &nullDefault
+		return
+	}
+	if isNull {
+		c.report(joinPointer(pointer, "default"), "default doesn't match the AVRO type, it is dropped")
+	}
+}
+
+func isNullable(schema avro.Schema) bool {
+	union, ok := schema.(avro.UnionSchema)
+	if !ok {
+		return schema.TypeName() == avro.TypeNull
+	}
+	for _, branch := range union {
+		if branch.TypeName() == avro.TypeNull {
+			return true
+		}
+	}
+	return false
+}
+
+// withNull returns the union of the schema and null, null being the first or the last branch
+func withNull(schema avro.Schema, first bool) avro.Schema {
+	branches := avro.UnionSchema{schema}
+	if union, ok := schema.(avro.UnionSchema); ok {
+		branches = make(avro.UnionSchema, 0, len(union))
+		for _, branch := range union {
+			if branch.TypeName() != avro.TypeNull {
+				branches = append(branches, branch)
+			}
+		}
+	}
+	if first {
+		return append(avro.UnionSchema{avro.TypeNull}, branches...)
+	}
+	return append(branches, avro.TypeNull)
+}
+
+// union returns the union of the branches, nested unions being flattened.
+// AVRO unions cannot hold two branches of the same type, only the first one is kept.
+func (c *jsonSchemaConverter) union(pointer string, branches []avro.Schema) avro.Schema {
+	union := make(avro.UnionSchema, 0, len(branches))
+	keys := make([]string, 0, len(branches))
+	merged := false
+	var add func(branch avro.Schema)
+	add = func(branch avro.Schema) {
+		if nested, ok := branch.(avro.UnionSchema); ok {
+			for _, nestedBranch := range nested {
+				add(nestedBranch)
+			}
+			return
+		}
+		key := unionKey(branch)
+		if contains(keys, key) {
+			merged = true
+			return
+		}
+		keys = append(keys, key)
+		union = append(union, branch)
+	}
+	for _, branch := range branches {
+		add(branch)
+	}
+	if merged {
+		c.report(pointer, "schemas of the same AVRO type cannot be distinguished in a union, the first one is kept")
+	}
+	if len(union) == 1 {
+		return union[0]
+	}
+	return union
+}
+
+// unionKey identifies the branches an AVRO union cannot hold twice
+func unionKey(schema avro.Schema) string {
+	switch t := schema.(type) {
+	case *avro.ReferenceSchema:
+		return t.Schema.FullName("")
+	case avro.NamedSchema:
+		return t.FullName("")
+	case *avro.DerivedPrimitiveSchema:
+		return string(t.Type)
+	default:
+		return string(schema.TypeName())
+	}
+}
+
+// rootHint is the name given to the root, if any, types nested without title being named after it
+func (c *jsonSchemaConverter) rootHint() string {
+	if c.rootName != "" {
+		return c.rootName
+	}
+	return "Root"
+}
+
+// namedType names the type after the given name for the root, its title or the hint, making it unique
+func (c *jsonSchemaConverter) namedType(node *fastjson.Value, pointer, hint string) (name, namespace string) {
+	candidate := hint
+	if title := node.GetStringBytes("title"); len(title) > 0 {
+		candidate = string(title)
+	}
+	if pointer == "#" && c.rootName != "" {
+		candidate = c.rootName
+	}
+	fullname := avroFullName(candidate)
+	if !strings.Contains(fullname, ".") && c.namespace != "" {
+		fullname = c.namespace + "." + fullname
+	}
+	base := fullname
+	for i := 2; c.taken[fullname]; i++ {
+		fullname = base + strconv.Itoa(i)
+	}
+	c.taken[fullname] = true
+	return nameOf(fullname), namespaceOf(fullname)
+}
+
+// capitalize turns a property name into a type name, e.g. "address" becomes "Address"
+func capitalize(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
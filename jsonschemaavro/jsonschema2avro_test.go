@@ -0,0 +1,168 @@
+package jsonschemaavro
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/khezen/avro"
+)
+
+func unrepresentablePaths(unrepresentable []Unrepresentable) []string {
+	paths := make([]string, 0, len(unrepresentable))
+	for _, u := range unrepresentable {
+		paths = append(paths, u.Path)
+	}
+	return paths
+}
+
+func TestJSONSchema2AVRO(t *testing.T) {
+	cases := []struct {
+		document              string
+		name                  string
+		namespace             string
+		expectedSchema        string
+		expectedUnrepresented []string
+	}{
+		{`{"type":"string"}`, "", "", `"string"`, []string{}},
+		{`{"type":"string","format":"date-time"}`, "", "", `{"type":"long","logicalType":"timestamp-millis"}`, []string{}},
+		{`{"type":"string","format":"date"}`, "", "", `{"type":"int","logicalType":"date"}`, []string{}},
+		{`{"type":"string","format":"time"}`, "", "", `{"type":"int","logicalType":"time-millis"}`, []string{}},
+		{`{"format":"uuid"}`, "", "", `{"type":"string","logicalType":"uuid"}`, []string{}},
+		{`{"type":"string","contentEncoding":"base64","maxLength":8}`, "", "", `"bytes"`, []string{"#/maxLength"}},
+		{`{"type":"integer","minimum":0}`, "", "", `"long"`, []string{"#/minimum"}},
+		{`{"type":"integer","format":"int32"}`, "", "", `"int"`, []string{}},
+		{`{"type":"number"}`, "", "", `"double"`, []string{}},
+		{`{"type":"number","format":"float"}`, "", "", `"float"`, []string{}},
+		{`{"type":["boolean","null"]}`, "", "", `["boolean","null"]`, []string{}},
+		{`{"type":"string","nullable":true}`, "", "", `["string","null"]`, []string{}},
+		{`{"type":["integer","number"]}`, "", "", `["long","double"]`, []string{}},
+		{`{"oneOf":[{"type":"string"},{"type":"string","format":"uuid"},{"type":"null"}]}`, "", "", `["string","null"]`, []string{"#"}},
+		{`{"anyOf":[{"type":"integer"},{"type":"array","items":{"type":"string"}}]}`, "", "", `["long",{"type":"array","items":"string"}]`, []string{}},
+		{`{"title":"Suit","enum":["SPADES","HEARTS"]}`, "", "", `{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]}`, []string{}},
+		{`{"enum":["SPADES",null]}`, "Suit", "", `["null",{"type":"enum","name":"Suit","symbols":["SPADES"]}]`, []string{}},
+		{`{"enum":["not a symbol"]}`, "", "", `"string"`, []string{"#"}},
+		{`{"enum":[1,2]}`, "", "", `"double"`, []string{"#", "#"}},
+		{`{"type":"integer","const":1}`, "", "", `"long"`, []string{"#"}},
+		{`{"const":"cat"}`, "Kind", "", `{"type":"enum","name":"Kind","symbols":["cat"]}`, []string{}},
+		{`{"type":"object","additionalProperties":{"type":"integer"}}`, "", "", `{"type":"map","values":"long"}`, []string{}},
+		{`{"type":"object"}`, "", "", `{"type":"map","values":"string"}`, []string{"#"}},
+		{`{"type":"object","additionalProperties":false}`, "Empty", "", `{"type":"record","name":"Empty","fields":[]}`, []string{}},
+		{`{"type":"array"}`, "", "", `{"type":"array","items":"string"}`, []string{"#"}},
+		{`{"type":"array","prefixItems":[{"type":"string"}]}`, "", "", `{"type":"array","items":"string"}`, []string{"#"}},
+		{`{"type":"array","items":[{"type":"string"}],"additionalItems":false}`, "", "", `{"type":"array","items":"string"}`, []string{"#/additionalItems", "#"}},
+		{`true`, "", "", `"string"`, []string{"#"}},
+		{`{}`, "", "", `"string"`, []string{"#"}},
+		{`{"type":"object","properties":{"any":true,"none":false},"required":["any","none"]}`, "Root", "",
+			`{"type":"record","name":"Root","fields":[{"name":"any","type":"string"},{"name":"none","type":"null"}]}`,
+			[]string{"#/properties/any", "#/properties/none"}},
+		{`{"$ref":"https://example.com/schema.json"}`, "", "", `"string"`, []string{"#/$ref"}},
+		{`{"$ref":"#/$defs/a~1b","$defs":{"a/b":{"type":"string"}}}`, "", "", `"string"`, []string{}},
+		{`{"$ref":"#/$defs/list","$defs":{"list":{"type":"array","items":{"$ref":"#/$defs/list"}}}}`, "", "", `{"type":"array","items":"string"}`, []string{"#/$defs/list"}},
+		{`{"title":"User","type":"object","description":"a user","properties":{
+				"name":{"type":"string","description":"full name"},
+				"age":{"type":"integer","format":"int32","default":18},
+				"email":{"type":["string","null"]},
+				"created":{"type":"string","format":"date-time","default":"2020-01-01T00:00:00Z"},
+				"nickname":{"type":"string","default":null},
+				"status":{"type":["string","null"],"default":null},
+				"bad-name":{"type":"string"},
+				"address":{"type":"object","properties":{"street":{"type":"string"}},"required":["street"],"additionalProperties":true},
+				"previous":{"$ref":"#/properties/address"},
+				"friends":{"type":"array","items":{"$ref":"#"}}},
+			"required":["name","age","email","created","nickname","status","address","friends"]}`, "", "",
+			`{"type":"record","name":"User","doc":"a user","fields":[
+				{"name":"name","doc":"full name","type":"string"},
+				{"name":"age","type":"int","default":18},
+				{"name":"email","type":["string","null"]},
+				{"name":"created","type":{"type":"long","logicalType":"timestamp-millis"}},
+				{"name":"nickname","type":"string"},
+				{"name":"status","type":["null","string"],"default":null},
+				{"name":"address","type":{"type":"record","name":"Address","fields":[{"name":"street","type":"string"}]}},
+				{"name":"previous","type":["null","Address"],"default":null},
+				{"name":"friends","type":{"type":"array","items":"User"}}]}`,
+			[]string{"#/properties/created/default", "#/properties/nickname/default", "#/properties/bad-name", "#/properties/address/additionalProperties"}},
+		{`{"definitions":{"Pet":{"type":"object","properties":{"name":{"type":"string"}}}},"type":"object","properties":{
+				"cat":{"$ref":"#/definitions/Pet"},
+				"dog":{"title":"Pet","type":"object","properties":{"name":{"type":"string"}}},
+				"pets":{"type":"object","additionalProperties":{"$ref":"#/definitions/Pet"}}},
+			"required":["cat","dog","pets"]}`, "Owner", "",
+			`{"type":"record","name":"Owner","fields":[
+				{"name":"cat","type":{"type":"record","name":"Pet","fields":[{"name":"name","type":["null","string"],"default":null}]}},
+				{"name":"dog","type":{"type":"record","name":"Pet2","fields":[{"name":"name","type":["null","string"],"default":null}]}},
+				{"name":"pets","type":{"type":"map","values":"Pet"}}]}`, []string{}},
+		{`{"$defs":{"test.Animal":{"type":"object","properties":{"kind":{"type":"string"}},"required":["kind"]}},
+			"title":"Dog","allOf":[{"$ref":"#/$defs/test.Animal"},{"properties":{"good":{"type":"boolean","default":true}}}]}`, "", "ns",
+			`{"type":"record","name":"Dog","namespace":"ns","fields":[{"name":"kind","type":"string"},{"name":"good","type":["boolean","null"],"default":true}]}`, []string{}},
+		{`{"allOf":[{"type":"string"},{"maxLength":3}]}`, "", "", `"string"`, []string{"#/allOf"}},
+		{`{"allOf":[{"type":"string"}]}`, "", "", `"string"`, []string{}},
+		{`{"oneOf":[{"type":"object","properties":{"a":{"type":"string"}}},{"type":"object","properties":{"b":{"type":"string"}}}],"nullable":true}`, "Choice", "",
+			`[{"type":"record","name":"Choice","fields":[{"name":"a","type":["null","string"],"default":null}]},{"type":"record","name":"Choice2","fields":[{"name":"b","type":["null","string"],"default":null}]},"null"]`, []string{}},
+		// the same nullable definitions referred to twice
+		{`{"$defs":{"Color":{"enum":["RED","GREEN",null]},"Point":{"type":["object","null"],"properties":{"x":{"type":"number"}},"required":["x"]}},
+			"type":"object","properties":{
+				"a":{"$ref":"#/$defs/Color"},"b":{"$ref":"#/$defs/Color"},
+				"c":{"$ref":"#/$defs/Point"},"d":{"$ref":"#/$defs/Point"}},
+			"required":["a","b","c","d"]}`, "R", "",
+			`{"type":"record","name":"R","fields":[
+				{"name":"a","type":["null",{"type":"enum","name":"Color","symbols":["RED","GREEN"]}]},
+				{"name":"b","type":["null","Color"]},
+				{"name":"c","type":[{"type":"record","name":"Point","fields":[{"name":"x","type":"double"}]},"null"]},
+				{"name":"d","type":["Point","null"]}]}`, []string{}},
+		{`{"title":"Node","type":["object","null"],"properties":{"next":{"$ref":"#"}},"required":["next"]}`, "", "",
+			`[{"type":"record","name":"Node","fields":[{"name":"next","type":["null","Node"]}]},"null"]`, []string{}},
+	}
+	for i, c := range cases {
+		schema, unrepresentable, err := JSONSchema2AVRO([]byte(c.document), c.name, c.namespace)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		schemaBytes, err := avro.MarshalSchema(schema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		err = avro.ValidateSchema(schemaBytes)
+		if err != nil {
+			t.Errorf("case %d - invalid schema %s: %v", i, schemaBytes, err)
+		}
+		var expected, got interface{}
+		json.Unmarshal([]byte(c.expectedSchema), &expected)
+		json.Unmarshal(schemaBytes, &got)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("case %d - expected:\n%s\ngot:\n%s", i, c.expectedSchema, schemaBytes)
+		}
+		if paths := unrepresentablePaths(unrepresentable); !reflect.DeepEqual(paths, c.expectedUnrepresented) {
+			t.Errorf("case %d - expected unrepresentable %v, got %v", i, c.expectedUnrepresented, unrepresentable)
+		}
+	}
+}
+
+func TestJSONSchema2AVROErrors(t *testing.T) {
+	cases := []struct {
+		document    string
+		expectedErr error
+	}{
+		{`{`, ErrInvalidJSONSchema},
+		{`42`, ErrInvalidJSONSchema},
+		{`{"type":"something"}`, ErrInvalidJSONSchema},
+		{`{"type":42}`, ErrInvalidJSONSchema},
+		{`{"type":["string",42]}`, ErrInvalidJSONSchema},
+		{`{"oneOf":[]}`, ErrInvalidJSONSchema},
+		{`{"allOf":{}}`, ErrInvalidJSONSchema},
+		{`{"oneOf":[{"type":"something"}]}`, ErrInvalidJSONSchema},
+		{`{"$ref":42}`, ErrInvalidJSONSchema},
+		{`{"$ref":"#/$defs/missing"}`, ErrUnresolvedRef},
+		{`{"type":"object","properties":[]}`, ErrInvalidJSONSchema},
+		{`{"type":"object","properties":{"a":{"type":"something"}}}`, ErrInvalidJSONSchema},
+		{`{"type":"array","items":{"type":"something"}}`, ErrInvalidJSONSchema},
+		{`{"type":"object","additionalProperties":{"type":"something"}}`, ErrInvalidJSONSchema},
+	}
+	for i, c := range cases {
+		_, _, err := JSONSchema2AVRO([]byte(c.document), "", "")
+		if err != c.expectedErr {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+		}
+	}
+}
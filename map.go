@@ -6,8 +6,15 @@ import (
 
 // MapSchema -
 type MapSchema struct {
-	Type  Type   `json:"type"`
-	Value Schema `json:"values"`
+	Type       Type       `json:"type"`
+	Value      Schema     `json:"values"`
+	Properties Properties `json:"-"`
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the map
+func (t *MapSchema) MarshalJSON() ([]byte, error) {
+	type mapSchema MapSchema
+	return marshalWithProperties((*mapSchema)(t), t.Properties, mapAttributes)
 }
 
 // TypeName -
@@ -15,17 +22,18 @@ func (t *MapSchema) TypeName() Type {
 	return TypeMap
 }
 
-func translateValueToMapSchema(value *fastjson.Value, additionalTypes ...Type) (Schema, error) {
+func (p *schemaParser) translateValueToMapSchema(value *fastjson.Value, path, namespace string) (Schema, error) {
 	if !value.Exists("values") {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(path, nil, ErrInvalidSchema, "missing values")
 	}
 	valueVal := value.Get("values")
-	valueSchema, err := translateValue2AnySchema(valueVal, additionalTypes...)
+	valueSchema, err := p.translateValue2AnySchema(valueVal, joinPath(path, "values"), namespace)
 	if err != nil {
 		return nil, err
 	}
 	return &MapSchema{
-		Type:  TypeMap,
-		Value: valueSchema,
+		Type:       TypeMap,
+		Value:      valueSchema,
+		Properties: translateValueToProperties(value, mapAttributes),
 	}, nil
 }
@@ -0,0 +1,89 @@
+package avro
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// MarshalSchema - marshals the schema to JSON, defining each named type (record, enum or fixed) on its first occurrence
+// and referencing it by its fullname afterwards, whereas json.Marshal renders the definition every time.
+// ErrRedefinedType is returned if distinct definitions share the same fullname. The schema is left untouched.
+func MarshalSchema(schema Schema) ([]byte, error) {
+	d := deduplicator{
+		defined: make(map[string]NamedSchema),
+	}
+	deduplicated, err := d.deduplicate(schema, "")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(deduplicated)
+}
+
+type deduplicator struct {
+	// defined - named schemas defined so far, indexed by fullname
+	defined map[string]NamedSchema
+}
+
+// deduplicate returns a copy of the schema where named types already defined are replaced by references.
+// namespace is the enclosing namespace.
+func (d *deduplicator) deduplicate(schema Schema, namespace string) (Schema, error) {
+	switch t := schema.(type) {
+	case UnionSchema:
+		union := make(UnionSchema, 0, len(t))
+		for _, branch := range t {
+			deduplicated, err := d.deduplicate(branch, namespace)
+			if err != nil {
+				return nil, err
+			}
+			union = append(union, deduplicated)
+		}
+		return union, nil
+	case *ArraySchema:
+		array := *t
+		items, err := d.deduplicate(t.Items, namespace)
+		if err != nil {
+			return nil, err
+		}
+		array.Items = items
+		return &array, nil
+	case *MapSchema:
+		mapSchema := *t
+		values, err := d.deduplicate(t.Value, namespace)
+		if err != nil {
+			return nil, err
+		}
+		mapSchema.Value = values
+		return &mapSchema, nil
+	case *RecordSchema, *EnumSchema, *FixedSchema:
+		named := t.(NamedSchema)
+		fullname := named.FullName(namespace)
+		if defined, ok := d.defined[fullname]; ok {
+			if defined != named && !reflect.DeepEqual(defined, named) {
+				return nil, ErrRedefinedType
+			}
+			return &ReferenceSchema{Name: fullname, Schema: defined}, nil
+		}
+		d.defined[fullname] = named
+		record, ok := t.(*RecordSchema)
+		if !ok {
+			return t, nil
+		}
+		return d.deduplicateRecord(record, namespaceOf(fullname))
+	default:
+		return schema, nil
+	}
+}
+
+func (d *deduplicator) deduplicateRecord(schema *RecordSchema, namespace string) (Schema, error) {
+	record := *schema
+	record.Fields = make([]RecordFieldSchema, 0, len(schema.Fields))
+	for _, field := range schema.Fields {
+		fieldType, err := d.deduplicate(field.Type, namespace)
+		if err != nil {
+			return nil, err
+		}
+		field.Type = fieldType
+		record.Fields = append(record.Fields, field)
+	}
+	return &record, nil
+}
@@ -0,0 +1,72 @@
+package avro
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalSchema(t *testing.T) {
+	md5 := &FixedSchema{Type: TypeFixed, Name: "md5", Namespace: "hash", Size: 16}
+	color := &EnumSchema{Type: TypeEnum, Name: "color", Symbols: []string{"RED", "BLUE"}}
+	node := &RecordSchema{Type: TypeRecord, Name: "node", Namespace: "tree"}
+	node.Fields = []RecordFieldSchema{
+		{Name: "next", Type: UnionSchema{TypeNull, node}},
+	}
+	cases := []struct {
+		schema      Schema
+		expected    string
+		expectedErr error
+	}{
+		{
+			&RecordSchema{
+				Type: TypeRecord,
+				Name: "file",
+				Fields: []RecordFieldSchema{
+					{Name: "checksum", Type: md5},
+					{Name: "checksums", Type: &ArraySchema{Type: TypeArray, Items: md5}},
+					{Name: "colors", Type: &MapSchema{Type: TypeMap, Value: color}},
+					{Name: "color", Type: color},
+				},
+			},
+			`{"type":"record","name":"file","fields":[{"name":"checksum","type":{"type":"fixed","name":"md5","namespace":"hash","size":16}},{"name":"checksums","type":{"type":"array","items":"hash.md5"}},{"name":"colors","type":{"type":"map","values":{"type":"enum","name":"color","symbols":["RED","BLUE"]}}},{"name":"color","type":"color"}]}`,
+			nil,
+		},
+		{
+			node,
+			`{"type":"record","namespace":"tree","name":"node","fields":[{"name":"next","type":["null","tree.node"]}]}`,
+			nil,
+		},
+		{
+			UnionSchema{md5, &FixedSchema{Type: TypeFixed, Name: "md5", Namespace: "hash", Size: 16}},
+			`[{"type":"fixed","name":"md5","namespace":"hash","size":16},"hash.md5"]`,
+			nil,
+		},
+		{
+			UnionSchema{md5, &FixedSchema{Type: TypeFixed, Name: "md5", Namespace: "hash", Size: 32}},
+			"",
+			ErrRedefinedType,
+		},
+	}
+	for i, c := range cases {
+		schemaBytes, err := MarshalSchema(c.schema)
+		if err != c.expectedErr {
+			t.Errorf("case %d - expected error %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if string(schemaBytes) != c.expected {
+			t.Errorf("case %d - expected %s, got %s", i, c.expected, schemaBytes)
+		}
+		var anySchema AnySchema
+		err = json.Unmarshal(schemaBytes, &anySchema)
+		if err != nil {
+			t.Errorf("case %d - output isn't a valid schema: %v", i, err)
+		}
+	}
+	// the schema is left untouched
+	if _, ok := node.Fields[0].Type.(UnionSchema)[1].(*RecordSchema); !ok {
+		t.Errorf("expected the schema to be left untouched")
+	}
+}
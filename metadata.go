@@ -2,36 +2,47 @@ package avro
 
 import "github.com/valyala/fastjson"
 
-func translateValueToMetaFields(value *fastjson.Value) (namespace, name, documentation string, aliases []string, err error) {
+// translateValueToMetaFields reads the name, namespace, doc and aliases of a named type or of a field.
+// Names and aliases may be fullnames, as named types allow, namespaces may be empty.
+func translateValueToMetaFields(value *fastjson.Value, path string) (namespace, name, documentation string, aliases []string, err error) {
 	if !value.Exists("name") {
-		return "", "", "", nil, ErrInvalidSchema
+		return "", "", "", nil, schemaError(path, nil, ErrInvalidSchema, "missing name")
 	}
 	nameBytes, err := value.Get("name").StringBytes()
 	if err != nil {
-		return "", "", "", nil, ErrInvalidSchema
+		return "", "", "", nil, schemaError(joinPath(path, "name"), value.Get("name"), ErrInvalidSchema, "name must be a string")
 	}
 	name = string(nameBytes)
+	if !isValidFullName(name) {
+		return "", "", "", nil, schemaError(joinPath(path, "name"), value.Get("name"), ErrInvalidSchema, "name must match [A-Za-z_][A-Za-z0-9_]*, with dot separated namespace")
+	}
 	if value.Exists("namespace") {
 		namespaceBytes, err := value.Get("namespace").StringBytes()
 		if err != nil {
-			return "", "", "", nil, ErrInvalidSchema
+			return "", "", "", nil, schemaError(joinPath(path, "namespace"), value.Get("namespace"), ErrInvalidSchema, "namespace must be a string")
 		}
 		namespace = string(namespaceBytes)
+		if namespace != "" && !isValidFullName(namespace) {
+			return "", "", "", nil, schemaError(joinPath(path, "namespace"), value.Get("namespace"), ErrInvalidSchema, "namespace must be dot separated names matching [A-Za-z_][A-Za-z0-9_]*")
+		}
 	}
-	documentation, err = translateValueToDocumentation(value)
+	documentation, err = translateValueToDocumentation(value, path)
 	if err != nil {
 		return "", "", "", nil, err
 	}
 	if value.Exists("aliases") {
 		aliasValues, err := value.Get("aliases").Array()
 		if err != nil {
-			return "", "", "", nil, ErrInvalidSchema
+			return "", "", "", nil, schemaError(joinPath(path, "aliases"), value.Get("aliases"), ErrInvalidSchema, "aliases must be an array")
 		}
 		aliases = make([]string, 0, len(aliasValues))
-		for _, aliasValue := range aliasValues {
+		for i, aliasValue := range aliasValues {
 			aliasStringBytes, err := aliasValue.StringBytes()
 			if err != nil {
-				return "", "", "", nil, ErrInvalidSchema
+				return "", "", "", nil, schemaError(indexPath(joinPath(path, "aliases"), i), aliasValue, ErrInvalidSchema, "alias must be a string")
+			}
+			if !isValidFullName(string(aliasStringBytes)) {
+				return "", "", "", nil, schemaError(indexPath(joinPath(path, "aliases"), i), aliasValue, ErrInvalidSchema, "alias must match [A-Za-z_][A-Za-z0-9_]*, with dot separated namespace")
 			}
 			aliases = append(aliases, string(aliasStringBytes))
 		}
@@ -39,13 +50,22 @@ func translateValueToMetaFields(value *fastjson.Value) (namespace, name, documen
 	return namespace, name, documentation, aliases, nil
 }
 
-func translateValueToDocumentation(value *fastjson.Value) (documentation string, err error) {
+func translateValueToDocumentation(value *fastjson.Value, path string) (documentation string, err error) {
 	if value.Exists("doc") {
 		documentationBytes, err := value.Get("doc").StringBytes()
 		if err != nil {
-			return "", ErrInvalidSchema
+			return "", schemaError(joinPath(path, "doc"), value.Get("doc"), ErrInvalidSchema, "doc must be a string")
 		}
 		documentation = string(documentationBytes)
 	}
 	return documentation, nil
 }
+
+// translateValueToFullName resolves the fullname of the named type defined by value
+func translateValueToFullName(value *fastjson.Value, name, namespace, enclosingNamespace string) string {
+	if value.Exists("namespace") && namespace == "" {
+		// explicitly set to the null namespace
+		enclosingNamespace = ""
+	}
+	return fullName(name, namespace, enclosingNamespace)
+}
@@ -0,0 +1,112 @@
+package avro
+
+import (
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+// NamedSchema - record, enum or fixed schema, identified by its fullname
+type NamedSchema interface {
+	Schema
+	// FullName returns the fullname of the schema.
+	// namespace is the enclosing namespace, used when the schema doesn't define its own.
+	FullName(namespace string) string
+}
+
+// namedTypes - registry of the named schemas defined so far, indexed by fullname
+type namedTypes map[string]NamedSchema
+
+// define registers the given schema under its fullname
+func (nt namedTypes) define(fullname string, schema NamedSchema) error {
+	if isPrimitive(Type(fullname)) {
+		return ErrInvalidSchema
+	}
+	if _, ok := nt[fullname]; ok {
+		return ErrRedefinedType
+	}
+	nt[fullname] = schema
+	return nil
+}
+
+// define registers the named schema defined by value, at path in the schema, under its fullname
+func (p *schemaParser) define(fullname string, schema NamedSchema, value *fastjson.Value, path string) error {
+	err := p.names.define(fullname, schema)
+	switch err {
+	case nil:
+		return nil
+	case ErrRedefinedType:
+		return schemaError(joinPath(path, "name"), value.Get("name"), err, fullname+" is already defined")
+	default:
+		return schemaError(joinPath(path, "name"), value.Get("name"), err, "name of a primitive type")
+	}
+}
+
+// resolve looks up the given name from the enclosing namespace
+func (nt namedTypes) resolve(name, namespace string) (NamedSchema, bool) {
+	schema, ok := nt[fullName(name, "", namespace)]
+	if !ok && !strings.Contains(name, ".") {
+		schema, ok = nt[name]
+	}
+	return schema, ok
+}
+
+// isValidName reports whether name matches [A-Za-z_][A-Za-z0-9_]*
+func isValidName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'A' <= r && r <= 'Z', 'a' <= r && r <= 'z':
+		case i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isValidFullName reports whether fullname is a dot separated list of valid names
+func isValidFullName(fullname string) bool {
+	for _, name := range strings.Split(fullname, ".") {
+		if !isValidName(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// fullName resolves the fullname of a named type following the spec:
+// a name containing a dot is already a fullname,
+// otherwise it is qualified by its own namespace or, when empty, the enclosing one.
+func fullName(name, namespace, enclosingNamespace string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	if namespace == "" {
+		namespace = enclosingNamespace
+	}
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// namespaceOf returns the namespace of the given fullname
+func namespaceOf(fullname string) string {
+	i := strings.LastIndex(fullname, ".")
+	if i < 0 {
+		return ""
+	}
+	return fullname[:i]
+}
+
+func isPrimitive(typeName Type) bool {
+	switch typeName {
+	case TypeNull, TypeBoolean, TypeFloat32, TypeFloat64, TypeInt32, TypeInt64, TypeString, TypeBytes:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// Object container files hold a header followed by blocks of records:
+//
+//	header  "Obj\x01", metadata as a map of bytes, 16 bytes sync marker
+//	block   record count as long, size in bytes as long, compressed records, sync marker
+//
+// The metadata hold the schema of the records under "avro.schema"
+// and the compression codec of the blocks under "avro.codec".
+
+const (
+	// OCFMetadataSchema - metadata key of the schema of the records
+	OCFMetadataSchema = "avro.schema"
+	// OCFMetadataCodec - metadata key of the compression codec of the blocks
+	OCFMetadataCodec = "avro.codec"
+)
+
+var ocfMagic = []byte{'O', 'b', 'j', 1}
+
+// ocfHeaderSchema - schema of the header of object container files
+var ocfHeaderSchema = &RecordSchema{
+	Type: TypeRecord,
+	Name: "org.apache.avro.file.Header",
+	Fields: []RecordFieldSchema{
+		{Name: "magic", Type: &FixedSchema{Type: TypeFixed, Name: "Magic", Size: 4}},
+		{Name: "meta", Type: &MapSchema{Type: TypeMap, Value: TypeBytes}},
+		{Name: "sync", Type: &FixedSchema{Type: TypeFixed, Name: "Sync", Size: 16}},
+	},
+}
+
+// streamDecoder reads AVRO binary encoded values from a stream
+type streamDecoder struct {
+	r *bufio.Reader
+}
+
+func (d streamDecoder) readLong() (int64, error) {
+	u, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, err
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+func (d streamDecoder) readFixed(size int64) ([]byte, error) {
+	if size < 0 {
+		return nil, ErrInvalidEncoding
+	}
+	buf := new(bytes.Buffer)
+	n, err := io.CopyN(buf, d.r, size)
+	if err != nil {
+		if n > 0 || err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d streamDecoder) readBytes() ([]byte, error) {
+	size, err := d.readLong()
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	return d.readFixed(size)
+}
+
+// noEOF reports io.EOF as io.ErrUnexpectedEOF, for reads that cannot end the stream
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// OCFReader - reads the records of an object container file block by block
+type OCFReader struct {
+	d           streamDecoder
+	schema      Schema
+	reader      Schema
+	zeroSize    bool // records of the schema are encoded in no byte, such as records without fields
+	compression string
+	codec       Codec
+	metadata    map[string][]byte
+	sync        []byte
+}
+
+// NewOCFReader - reads the header of the file from r
+// and returns a reader of the blocks that follow.
+func NewOCFReader(r io.Reader) (*OCFReader, error) {
+	d := streamDecoder{r: bufio.NewReader(r)}
+	magic, err := d.readFixed(int64(len(ocfMagic)))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, ocfMagic) {
+		return nil, ErrInvalidEncoding
+	}
+	metadata, err := readOCFMetadata(d)
+	if err != nil {
+		return nil, err
+	}
+	sync, err := d.readFixed(16)
+	if err != nil {
+		return nil, err
+	}
+	var anySchema AnySchema
+	err = json.Unmarshal(metadata[OCFMetadataSchema], &anySchema)
+	if err != nil {
+		return nil, err
+	}
+	compression := string(metadata[OCFMetadataCodec])
+	if compression == "" {
+		compression = CompressionNull
+	}
+	codec, err := LookupCodec(compression)
+	if err != nil {
+		return nil, err
+	}
+	schema := anySchema.Schema()
+	return &OCFReader{
+		d:           d,
+		schema:      schema,
+		zeroSize:    isZeroSize(schema, make(map[*RecordSchema]bool)),
+		compression: compression,
+		codec:       codec,
+		metadata:    metadata,
+		sync:        sync,
+	}, nil
+}
+
+// NewOCFReaderWithSchema - reads the header of the file from r
+// and returns a reader of the blocks that follow, their records being resolved to the reader schema.
+func NewOCFReaderWithSchema(r io.Reader, reader Schema) (*OCFReader, error) {
+	ocfr, err := NewOCFReader(r)
+	if err != nil {
+		return nil, err
+	}
+	ocfr.reader = reader
+	return ocfr, nil
+}
+
+func readOCFMetadata(d streamDecoder) (map[string][]byte, error) {
+	metadata := make(map[string][]byte)
+	for {
+		count, err := d.readLong()
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		if count == 0 {
+			return metadata, nil
+		}
+		if count < 0 {
+			// negative count is followed by the block size in bytes
+			count = -count
+			_, err = d.readLong()
+			if err != nil {
+				return nil, noEOF(err)
+			}
+		}
+		for ; count > 0; count-- {
+			key, err := d.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			metadata[string(key)] = value
+		}
+	}
+}
+
+// Schema - schema the records were written with, as stated by the header
+func (ocfr *OCFReader) Schema() Schema {
+	return ocfr.schema
+}
+
+// Compression - name of the compression codec of the blocks
+func (ocfr *OCFReader) Compression() string {
+	return ocfr.compression
+}
+
+// Metadata - metadata of the header, including the schema and the codec
+func (ocfr *OCFReader) Metadata() map[string][]byte {
+	return ocfr.metadata
+}
+
+// NextBlock - reads the records of the next block; io.EOF is returned after the last one.
+// Records are shaped like the reader schema, if any.
+func (ocfr *OCFReader) NextBlock() ([]interface{}, error) {
+	count, err := ocfr.d.readLong()
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, ErrInvalidEncoding
+	}
+	size, err := ocfr.d.readLong()
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	compressed, err := ocfr.d.readFixed(size)
+	if err != nil {
+		return nil, err
+	}
+	sync, err := ocfr.d.readFixed(int64(len(ocfr.sync)))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sync, ocfr.sync) {
+		return nil, ErrInvalidEncoding
+	}
+	block, err := ocfr.codec.Decompress(compressed)
+	if err != nil {
+		return nil, err
+	}
+	// the count read from the file is not trusted: records take at least a byte, unless they are zero-size
+	if ocfr.zeroSize && count > int64(MaxZeroSizeItems) || !ocfr.zeroSize && count > int64(len(block)) {
+		return nil, ErrInvalidEncoding
+	}
+	records := make([]interface{}, 0)
+	for ; count > 0; count-- {
+		var record interface{}
+		record, block, err = DecodeBinary(block, ocfr.schema)
+		if err != nil {
+			return nil, err
+		}
+		if ocfr.reader != nil {
+			record, err = ResolveDatum(ocfr.schema, ocfr.reader, record)
+			if err != nil {
+				return nil, err
+			}
+		}
+		records = append(records, record)
+	}
+	if len(block) > 0 {
+		return nil, ErrInvalidEncoding
+	}
+	return records, nil
+}
@@ -0,0 +1,151 @@
+package avro
+
+import (
+	"crypto/rand"
+	"io"
+	"strings"
+)
+
+const (
+	// DefaultOCFBlockLength - number of records above which blocks are flushed by default
+	DefaultOCFBlockLength = 1000
+	// DefaultOCFBlockSize - size in bytes of the encoded records above which blocks are flushed by default
+	DefaultOCFBlockSize = 64 * 1024
+)
+
+// OCFWriterConfig - configuration of object container file writers
+type OCFWriterConfig struct {
+	// Schema - schema of the records
+	Schema Schema
+	// Compression - Optional name of the compression codec used to compress blocks, "null" by default
+	Compression string
+	// Metadata - Optional user metadata, keys starting with "avro." are reserved
+	Metadata map[string][]byte
+	// BlockLength - Optional number of records above which a block is flushed, DefaultOCFBlockLength by default
+	BlockLength int
+	// BlockSize - Optional size in bytes of the encoded records above which a block is flushed, DefaultOCFBlockSize by default
+	BlockSize int
+	// Sync - Optional sync marker, random by default
+	Sync *[16]byte
+}
+
+// OCFWriter - writes records to an object container file
+type OCFWriter struct {
+	w           io.Writer
+	schema      Schema
+	codec       Codec
+	sync        [16]byte
+	blockLength int
+	blockSize   int
+	block       []byte
+	count       int
+}
+
+// NewOCFWriter - writes the header of the file to w
+// and returns a writer appending blocks of records to it.
+func NewOCFWriter(w io.Writer, cfg OCFWriterConfig) (*OCFWriter, error) {
+	if cfg.Schema == nil {
+		return nil, ErrInvalidSchema
+	}
+	if cfg.Compression == "" {
+		cfg.Compression = CompressionNull
+	}
+	codec, err := LookupCodec(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	schemaBytes, err := MarshalSchema(cfg.Schema)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]interface{}, len(cfg.Metadata)+2)
+	for key, value := range cfg.Metadata {
+		if strings.HasPrefix(key, "avro.") {
+			return nil, ErrReservedMetadata
+		}
+		metadata[key] = value
+	}
+	metadata[OCFMetadataSchema] = schemaBytes
+	metadata[OCFMetadataCodec] = []byte(cfg.Compression)
+	ocfw := &OCFWriter{
+		w:           w,
+		schema:      cfg.Schema,
+		codec:       codec,
+		blockLength: cfg.BlockLength,
+		blockSize:   cfg.BlockSize,
+	}
+	if ocfw.blockLength <= 0 {
+		ocfw.blockLength = DefaultOCFBlockLength
+	}
+	if ocfw.blockSize <= 0 {
+		ocfw.blockSize = DefaultOCFBlockSize
+	}
+	if cfg.Sync != nil {
+		ocfw.sync = *cfg.Sync
+	} else {
+		_, err = rand.Read(ocfw.sync[:])
+		if err != nil {
+			return nil, err
+		}
+	}
+	header, err := EncodeBinary(nil, ocfHeaderSchema, map[string]interface{}{
+		"magic": ocfMagic,
+		"meta":  metadata,
+		"sync":  ocfw.sync,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(header)
+	if err != nil {
+		return nil, err
+	}
+	return ocfw, nil
+}
+
+// Append - encodes the records to the current block,
+// which is flushed as soon as it exceeds the block length or size.
+func (ocfw *OCFWriter) Append(records ...interface{}) error {
+	for _, record := range records {
+		block, err := EncodeBinary(ocfw.block, ocfw.schema, record)
+		if err != nil {
+			return err
+		}
+		ocfw.block = block
+		ocfw.count++
+		if ocfw.count >= ocfw.blockLength || len(ocfw.block) >= ocfw.blockSize {
+			err = ocfw.Flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush - writes the current block, if not empty
+func (ocfw *OCFWriter) Flush() error {
+	if ocfw.count == 0 {
+		return nil
+	}
+	compressed, err := ocfw.codec.Compress(ocfw.block)
+	if err != nil {
+		return err
+	}
+	buf := appendLong(nil, int64(ocfw.count))
+	buf = appendLong(buf, int64(len(compressed)))
+	buf = append(buf, compressed...)
+	buf = append(buf, ocfw.sync[:]...)
+	_, err = ocfw.w.Write(buf)
+	if err != nil {
+		return err
+	}
+	ocfw.block = ocfw.block[:0]
+	ocfw.count = 0
+	return nil
+}
+
+// Close - flushes the current block. The underlying writer is left open.
+func (ocfw *OCFWriter) Close() error {
+	return ocfw.Flush()
+}
@@ -0,0 +1,285 @@
+package avro
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+const ocfTestSchema = `{"type":"record","name":"post","fields":[{"name":"id","type":"long"},{"name":"title","type":["null","string"]}]}`
+
+func ocfTestRecords(n int) []interface{} {
+	records := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		records = append(records, map[string]interface{}{
+			"id":    int64(i),
+			"title": map[string]interface{}{"string": "title"},
+		})
+	}
+	return records
+}
+
+func TestOCFWriter(t *testing.T) {
+	cases := []struct {
+		compression    string
+		blockLength    int
+		blockSize      int
+		records        int
+		expectedBlocks []int
+	}{
+		{CompressionNull, 2, 0, 5, []int{2, 2, 1}},
+		{CompressionDeflate, 0, 0, 5, []int{5}},
+		{CompressionSnappy, 0, 1, 3, []int{1, 1, 1}},
+		{CompressionZstandard, 0, 0, 4, []int{4}},
+		{CompressionBzip2, 3, 0, 4, []int{3, 1}},
+		{CompressionXZ, 0, 0, 2, []int{2}},
+		{"", 0, 0, 0, []int{}},
+	}
+	for i, c := range cases {
+		buf := new(bytes.Buffer)
+		ocfw, err := NewOCFWriter(buf, OCFWriterConfig{
+			Schema:      mustParseSchema(t, ocfTestSchema),
+			Compression: c.compression,
+			Metadata:    map[string][]byte{"origin": []byte("test")},
+			BlockLength: c.blockLength,
+			BlockSize:   c.blockSize,
+		})
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		records := ocfTestRecords(c.records)
+		err = ocfw.Append(records...)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		err = ocfw.Close()
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		switch c.compression {
+		case "", CompressionNull, CompressionDeflate, CompressionSnappy:
+			// goavro only supports these codecs
+			goavroReader, err := goavro.NewOCFReader(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Errorf("case %d - goavro failed to read the header: %v", i, err)
+				continue
+			}
+			goavroRecords := 0
+			for goavroReader.Scan() {
+				_, err = goavroReader.Read()
+				if err != nil {
+					t.Errorf("case %d - goavro failed to read a record: %v", i, err)
+				}
+				goavroRecords++
+			}
+			if goavroRecords != c.records {
+				t.Errorf("case %d - goavro read %d records, expected %d", i, goavroRecords, c.records)
+			}
+		}
+		ocfr, err := NewOCFReader(buf)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if string(ocfr.Metadata()["origin"]) != "test" {
+			t.Errorf("case %d - metadata lost: %v", i, ocfr.Metadata())
+		}
+		if c.compression != "" && ocfr.Compression() != c.compression {
+			t.Errorf("case %d - expected compression %s, got %s", i, c.compression, ocfr.Compression())
+		}
+		blocks := make([]int, 0)
+		read := make([]interface{}, 0)
+		for {
+			block, err := ocfr.NextBlock()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("case %d - %v", i, err)
+				break
+			}
+			blocks = append(blocks, len(block))
+			read = append(read, block...)
+		}
+		if !reflect.DeepEqual(blocks, c.expectedBlocks) {
+			t.Errorf("case %d - expected blocks %v, got %v", i, c.expectedBlocks, blocks)
+		}
+		if !reflect.DeepEqual(read, records) {
+			t.Errorf("case %d - expected %v, got %v", i, records, read)
+		}
+	}
+}
+
+func TestOCFReaderGoavroFile(t *testing.T) {
+	for _, compression := range []string{CompressionNull, CompressionDeflate, CompressionSnappy} {
+		buf := new(bytes.Buffer)
+		goavroWriter, err := goavro.NewOCFWriter(goavro.OCFConfig{W: buf, Schema: ocfTestSchema, CompressionName: compression})
+		if err != nil {
+			t.Fatal(err)
+		}
+		records := ocfTestRecords(3)
+		err = goavroWriter.Append(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ocfr, err := NewOCFReader(buf)
+		if err != nil {
+			t.Fatalf("%s - %v", compression, err)
+		}
+		if _, ok := ocfr.Schema().(*RecordSchema); !ok {
+			t.Errorf("%s - expected record schema, got %T", compression, ocfr.Schema())
+		}
+		block, err := ocfr.NextBlock()
+		if err != nil {
+			t.Fatalf("%s - %v", compression, err)
+		}
+		if !reflect.DeepEqual(block, records) {
+			t.Errorf("%s - expected %v, got %v", compression, records, block)
+		}
+		_, err = ocfr.NextBlock()
+		if err != io.EOF {
+			t.Errorf("%s - expected EOF, got %v", compression, err)
+		}
+	}
+}
+
+func TestOCFSharedNamedTypes(t *testing.T) {
+	hash := &FixedSchema{Type: TypeFixed, Name: "MD5", Size: 16}
+	schema := &RecordSchema{
+		Type: TypeRecord,
+		Name: "file",
+		Fields: []RecordFieldSchema{
+			{Name: "content", Type: hash},
+			{Name: "previous", Type: UnionSchema{TypeNull, hash}},
+		},
+	}
+	buf := new(bytes.Buffer)
+	ocfw, err := NewOCFWriter(buf, OCFWriterConfig{Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ocfw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ocfr, err := NewOCFReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"record","name":"file","fields":[{"name":"content","type":{"type":"fixed","name":"MD5","size":16}},{"name":"previous","type":["null","MD5"]}]}`
+	if schemaJSON := string(ocfr.Metadata()[OCFMetadataSchema]); schemaJSON != expected {
+		t.Errorf("expected %s, got %s", expected, schemaJSON)
+	}
+}
+
+func TestOCFZeroSizeRecords(t *testing.T) {
+	schema := mustParseSchema(t, `{"type":"record","name":"empty","fields":[]}`)
+	buf := new(bytes.Buffer)
+	ocfw, err := NewOCFWriter(buf, OCFWriterConfig{Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sync := buf.Bytes()[buf.Len()-16:]
+	header := append([]byte{}, buf.Bytes()...)
+	records := []interface{}{map[string]interface{}{}, map[string]interface{}{}}
+	err = ocfw.Append(records...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ocfw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ocfr, err := NewOCFReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ocfr.NextBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("expected %v, got %v", records, got)
+	}
+	_, err = ocfr.NextBlock()
+	if err != io.EOF {
+		t.Errorf("expected %v, got %v", io.EOF, err)
+	}
+	hostileBlock := append(appendLong(appendLong(nil, int64(MaxZeroSizeItems)+1), 0), sync...)
+	ocfr, err = NewOCFReader(bytes.NewReader(append(header, hostileBlock...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ocfr.NextBlock()
+	if err != ErrInvalidEncoding {
+		t.Errorf("expected %v, got %v", ErrInvalidEncoding, err)
+	}
+}
+
+func TestOCFErrors(t *testing.T) {
+	schema := mustParseSchema(t, ocfTestSchema)
+	_, err := NewOCFWriter(new(bytes.Buffer), OCFWriterConfig{Schema: schema, Compression: "lz4"})
+	if err != ErrUnsupportedCompression {
+		t.Errorf("expected %v, got %v", ErrUnsupportedCompression, err)
+	}
+	_, err = NewOCFWriter(new(bytes.Buffer), OCFWriterConfig{Schema: schema, Metadata: map[string][]byte{"avro.schema": nil}})
+	if err != ErrReservedMetadata {
+		t.Errorf("expected %v, got %v", ErrReservedMetadata, err)
+	}
+	_, err = NewOCFReader(bytes.NewReader([]byte("Obj\x02")))
+	if err != ErrInvalidEncoding {
+		t.Errorf("expected %v, got %v", ErrInvalidEncoding, err)
+	}
+	buf := new(bytes.Buffer)
+	ocfw, err := NewOCFWriter(buf, OCFWriterConfig{Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ocfw.Append(ocfTestRecords(1)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ocfw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ocfr, err := NewOCFReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ocfr.NextBlock()
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+	header := new(bytes.Buffer)
+	ocfw, err = NewOCFWriter(header, OCFWriterConfig{Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sync := header.Bytes()[header.Len()-16:]
+	hostileBlocks := [][]byte{
+		append(appendLong(appendLong(nil, 1<<60), 0), sync...),
+		append(appendLong(appendLong(nil, -1), 0), sync...),
+		append(append(appendLong(appendLong(nil, 3), 2), 0, 0), sync...),
+	}
+	for i, block := range hostileBlocks {
+		ocfr, err := NewOCFReader(bytes.NewReader(append(append([]byte{}, header.Bytes()...), block...)))
+		if err != nil {
+			t.Fatalf("block %d - %v", i, err)
+		}
+		_, err = ocfr.NextBlock()
+		if err != ErrInvalidEncoding {
+			t.Errorf("block %d - expected %v, got %v", i, ErrInvalidEncoding, err)
+		}
+	}
+	err = ocfw.Append(map[string]interface{}{"id": "wrong"})
+	if err != ErrInvalidDatum {
+		t.Errorf("expected %v, got %v", ErrInvalidDatum, err)
+	}
+}
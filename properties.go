@@ -0,0 +1,91 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/valyala/fastjson"
+)
+
+// Properties - custom attributes of a schema or a field, not defined by the spec,
+// mapped to their JSON value
+type Properties map[string]json.RawMessage
+
+// attributes defined by the spec for each kind of schema
+var (
+	recordAttributes  = []string{"type", "namespace", "name", "aliases", "doc", "fields"}
+	fieldAttributes   = []string{"name", "aliases", "doc", "type", "default", "order"}
+	enumAttributes    = []string{"type", "namespace", "name", "aliases", "doc", "symbols", "default"}
+	fixedAttributes   = []string{"type", "logicalType", "namespace", "name", "aliases", "doc", "size"}
+	arrayAttributes   = []string{"type", "items"}
+	mapAttributes     = []string{"type", "values"}
+	derivedAttributes = []string{"type", "doc", "logicalType"}
+	// precision and scale are only defined by decimals
+	decimalAttributes = []string{"precision", "scale"}
+)
+
+// logicalTypeAttributes returns the attributes defined by the spec for a logical type annotating a schema
+func logicalTypeAttributes(attributes []string, logicalType LogicalType) []string {
+	if logicalType != LogicalTypeDecimal {
+		return attributes
+	}
+	return append(decimalAttributes[:len(decimalAttributes):len(decimalAttributes)], attributes...)
+}
+
+// translateValueToProperties returns the attributes of value which are not in attributes, nil if none
+func translateValueToProperties(value *fastjson.Value, attributes []string) Properties {
+	var properties Properties
+	value.GetObject().Visit(func(key []byte, v *fastjson.Value) {
+		if contains(attributes, string(key)) {
+			return
+		}
+		if properties == nil {
+			properties = make(Properties)
+		}
+		properties[string(key)] = v.MarshalTo(nil)
+	})
+	return properties
+}
+
+// marshalWithProperties appends the properties, sorted by key, to the JSON object encoding v.
+// properties colliding with the attributes of the schema are skipped.
+func marshalWithProperties(v interface{}, properties Properties, attributes []string) ([]byte, error) {
+	objectBytes, err := json.Marshal(v)
+	if err != nil || len(properties) == 0 {
+		return objectBytes, err
+	}
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		if !contains(attributes, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	buf := bytes.NewBuffer(objectBytes[:len(objectBytes)-1])
+	for _, key := range keys {
+		keyBytes, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueBytes, err := json.Marshal(properties[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteRune(',')
+		buf.Write(keyBytes)
+		buf.WriteRune(':')
+		buf.Write(valueBytes)
+	}
+	buf.WriteRune('}')
+	return buf.Bytes(), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package avro
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestProperties(t *testing.T) {
+	cases := []string{
+		`{"type":"record","name":"post","fields":[{"name":"id","type":"long","pii":false,"sqlType":"BIGINT"}],"connect.name":"blog.post"}`,
+		`{"type":"enum","name":"status","symbols":["DRAFT"],"tags":["a","b"]}`,
+		`{"type":"fixed","name":"md5","size":16,"encoding":{"kind":"hex"}}`,
+		`{"type":"fixed","logicalType":"decimal","name":"money","size":4,"precision":9,"scale":2,"currency":"EUR"}`,
+		`{"type":"array","items":"string","java-class":"java.util.List"}`,
+		`{"type":"map","values":"long","sorted":true}`,
+		`{"type":"string","logicalType":"uuid","version":4}`,
+		`{"type":"int","logicalType":"something","precision":3}`,
+		`{"type":"bytes","logicalType":"decimal","precision":5,"scale":2,"rounding":"HALF_EVEN"}`,
+		`{"type":"int","connect.type":"int16"}`,
+		`{"type":"string","sqlType":"VARCHAR"}`,
+		`{"type":"string","doc":"name","connect.parameters":{"length":"64"}}`,
+		`{"type":"record","name":"r","fields":[{"name":"a","type":["null",{"type":"long","connect.version":1}]}]}`,
+	}
+	for i, c := range cases {
+		schema := mustParseSchema(t, c)
+		schemaBytes, err := json.Marshal(schema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if string(schemaBytes) != c {
+			t.Errorf("case %d -\nexpected:\n%s\ngot:\n%s\n", i, c, schemaBytes)
+		}
+	}
+}
+
+func TestPropertiesPlainPrimitive(t *testing.T) {
+	cases := []struct {
+		schema   string
+		expected Schema
+	}{
+		{`{"type":"string"}`, TypeString},
+		{`{"type":"int","connect.type":"int16"}`, &DerivedPrimitiveSchema{Type: TypeInt32, Properties: Properties{"connect.type": json.RawMessage(`"int16"`)}}},
+		{`{"type":"null","doc":"nothing"}`, &DerivedPrimitiveSchema{Type: TypeNull, Documentation: "nothing"}},
+	}
+	for i, c := range cases {
+		schema := mustParseSchema(t, c.schema)
+		if !reflect.DeepEqual(schema, c.expected) {
+			t.Errorf("case %d - expected %#v, got %#v", i, c.expected, schema)
+		}
+		if schema.TypeName() != c.expected.TypeName() {
+			t.Errorf("case %d - expected %s, got %s", i, c.expected.TypeName(), schema.TypeName())
+		}
+		canonical, err := CanonicalForm(schema)
+		if err != nil || string(canonical) != `"`+string(schema.TypeName())+`"` {
+			t.Errorf("case %d - expected \"%s\", got %s, %v", i, schema.TypeName(), canonical, err)
+		}
+	}
+}
+
+func TestPropertiesAttached(t *testing.T) {
+	schema := &RecordSchema{
+		Type: TypeRecord,
+		Name: "post",
+		Fields: []RecordFieldSchema{
+			{Name: "id", Type: TypeInt64, Properties: Properties{"pii": json.RawMessage(`true`)}},
+		},
+		Properties: Properties{
+			"name":     json.RawMessage(`"ignored"`),
+			"b":        json.RawMessage(`{"c": 1}`),
+			"a.custom": json.RawMessage(`"x"`),
+		},
+	}
+	schemaBytes, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"record","name":"post","fields":[{"name":"id","type":"long","pii":true}],"a.custom":"x","b":{"c":1}}`
+	if string(schemaBytes) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s\n", expected, schemaBytes)
+	}
+}
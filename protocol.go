@@ -0,0 +1,226 @@
+package avro
+
+import (
+	"crypto/md5"
+	"encoding/json"
+
+	"github.com/valyala/fastjson"
+)
+
+// Protocol - AVRO protocol (.avpr), describing the messages exchanged through RPC and the types they use
+type Protocol struct {
+	// Name - name of the protocol
+	Name          string
+	Namespace     string
+	Documentation string
+	// Types - named types used by the messages, in order of definition
+	Types []NamedSchema
+	// Messages - messages of the protocol, indexed by name
+	Messages   map[string]*Message
+	Properties Properties
+}
+
+// Message - request parameters, response and errors of a protocol message
+type Message struct {
+	Documentation string              `json:"doc,omitempty"`
+	Request       []RecordFieldSchema `json:"request"`
+	Response      Schema              `json:"response"`
+	// Errors - error types thrown by the message, besides the string of unexpected errors
+	Errors []Schema `json:"errors,omitempty"`
+	// OneWay - no response is sent, Response being null
+	OneWay     bool       `json:"one-way,omitempty"`
+	Properties Properties `json:"-"`
+}
+
+// attributes defined by the spec for protocols and messages
+var (
+	protocolAttributes = []string{"protocol", "namespace", "doc", "types", "messages"}
+	messageAttributes  = []string{"doc", "request", "response", "errors", "one-way"}
+)
+
+// UnmarshalJSON - parsing stops at the first problem met, returned as a *SchemaError
+func (pr *Protocol) UnmarshalJSON(bytes []byte) error {
+	value, err := unmarshaller.Parse(string(bytes))
+	if err != nil {
+		return err
+	}
+	protocol, err := newSchemaParser(false).translateValueToProtocol(value)
+	if err != nil {
+		return err
+	}
+	*pr = *protocol
+	return nil
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the protocol
+func (pr *Protocol) MarshalJSON() ([]byte, error) {
+	types := pr.Types
+	if types == nil {
+		types = []NamedSchema{}
+	}
+	messages := pr.Messages
+	if messages == nil {
+		messages = map[string]*Message{}
+	}
+	return marshalWithProperties(struct {
+		Name          string              `json:"protocol"`
+		Namespace     string              `json:"namespace,omitempty"`
+		Documentation string              `json:"doc,omitempty"`
+		Types         []NamedSchema       `json:"types"`
+		Messages      map[string]*Message `json:"messages"`
+	}{pr.Name, pr.Namespace, pr.Documentation, types, messages}, pr.Properties, protocolAttributes)
+}
+
+// MD5 - hash of the JSON encoding of the protocol, identifying it in RPC handshakes
+func (pr *Protocol) MD5() ([md5.Size]byte, error) {
+	protocolBytes, err := json.Marshal(pr)
+	if err != nil {
+		return [md5.Size]byte{}, err
+	}
+	return md5.Sum(protocolBytes), nil
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the message
+func (m *Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	t := *m
+	if t.Request == nil {
+		t.Request = []RecordFieldSchema{}
+	}
+	return marshalWithProperties((*message)(&t), t.Properties, messageAttributes)
+}
+
+// ErrorUnion - union of the errors the message may respond with:
+// string for unexpected errors, followed by the declared error types
+func (m *Message) ErrorUnion() UnionSchema {
+	union := make(UnionSchema, 0, len(m.Errors)+1)
+	union = append(union, TypeString)
+	return append(union, m.Errors...)
+}
+
+func (p *schemaParser) translateValueToProtocol(value *fastjson.Value) (*Protocol, error) {
+	if value.Type() != fastjson.TypeObject {
+		return nil, schemaError("", value, ErrInvalidSchema, "protocol must be an object")
+	}
+	if !value.Exists("protocol") {
+		return nil, schemaError("", nil, ErrInvalidSchema, "missing protocol")
+	}
+	nameBytes, err := value.Get("protocol").StringBytes()
+	if err != nil {
+		return nil, schemaError("protocol", value.Get("protocol"), ErrInvalidSchema, "protocol must be a string")
+	}
+	protocol := &Protocol{
+		Name:       string(nameBytes),
+		Messages:   make(map[string]*Message),
+		Properties: translateValueToProperties(value, protocolAttributes),
+	}
+	if value.Exists("namespace") {
+		namespaceBytes, err := value.Get("namespace").StringBytes()
+		if err != nil {
+			return nil, schemaError("namespace", value.Get("namespace"), ErrInvalidSchema, "namespace must be a string")
+		}
+		protocol.Namespace = string(namespaceBytes)
+	}
+	protocol.Documentation, err = translateValueToDocumentation(value, "")
+	if err != nil {
+		return nil, err
+	}
+	if value.Exists("types") {
+		typeValues, err := value.Get("types").Array()
+		if err != nil {
+			return nil, schemaError("types", value.Get("types"), ErrInvalidSchema, "types must be an array")
+		}
+		for i, typeValue := range typeValues {
+			schema, err := p.translateValue2AnySchema(typeValue, indexPath("types", i), protocol.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			named, ok := schema.(NamedSchema)
+			if !ok {
+				return nil, schemaError(indexPath("types", i), typeValue, ErrInvalidSchema, "types must define named types")
+			}
+			protocol.Types = append(protocol.Types, named)
+		}
+	}
+	if value.Exists("messages") {
+		messageValues, err := value.Get("messages").Object()
+		if err != nil {
+			return nil, schemaError("messages", value.Get("messages"), ErrInvalidSchema, "messages must be an object")
+		}
+		messageValues.Visit(func(key []byte, messageValue *fastjson.Value) {
+			if err != nil {
+				return
+			}
+			var message *Message
+			message, err = p.translateValueToMessage(messageValue, joinPath("messages", string(key)), protocol.Namespace)
+			protocol.Messages[string(key)] = message
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return protocol, nil
+}
+
+func (p *schemaParser) translateValueToMessage(value *fastjson.Value, path, namespace string) (*Message, error) {
+	if value.Type() != fastjson.TypeObject {
+		return nil, schemaError(path, value, ErrInvalidSchema, "message must be an object")
+	}
+	documentation, err := translateValueToDocumentation(value, path)
+	if err != nil {
+		return nil, err
+	}
+	if !value.Exists("request") {
+		return nil, schemaError(path, nil, ErrInvalidSchema, "missing request")
+	}
+	requestValues, err := value.Get("request").Array()
+	if err != nil {
+		return nil, schemaError(joinPath(path, "request"), value.Get("request"), ErrInvalidSchema, "request must be an array")
+	}
+	message := &Message{
+		Documentation: documentation,
+		Request:       make([]RecordFieldSchema, 0, len(requestValues)),
+		Properties:    translateValueToProperties(value, messageAttributes),
+	}
+	for i, requestValue := range requestValues {
+		param, err := p.translateValueToRecordFieldSchema(requestValue, indexPath(joinPath(path, "request"), i), namespace)
+		if err != nil {
+			return nil, err
+		}
+		message.Request = append(message.Request, *param)
+	}
+	if !value.Exists("response") {
+		return nil, schemaError(path, nil, ErrInvalidSchema, "missing response")
+	}
+	message.Response, err = p.translateValue2AnySchema(value.Get("response"), joinPath(path, "response"), namespace)
+	if err != nil {
+		return nil, err
+	}
+	if value.Exists("errors") {
+		errorValues, err := value.Get("errors").Array()
+		if err != nil {
+			return nil, schemaError(joinPath(path, "errors"), value.Get("errors"), ErrInvalidSchema, "errors must be an array")
+		}
+		for i, errorValue := range errorValues {
+			errorPath := indexPath(joinPath(path, "errors"), i)
+			schema, err := p.translateValue2AnySchema(errorValue, errorPath, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if record, ok := underlyingSchema(schema).(*RecordSchema); !ok || record.Type != TypeError {
+				return nil, schemaError(errorPath, errorValue, ErrInvalidSchema, "errors must be error types")
+			}
+			message.Errors = append(message.Errors, schema)
+		}
+	}
+	if value.Exists("one-way") {
+		message.OneWay, err = value.Get("one-way").Bool()
+		if err != nil {
+			return nil, schemaError(joinPath(path, "one-way"), value.Get("one-way"), ErrInvalidSchema, "one-way must be a boolean")
+		}
+	}
+	if message.OneWay && (message.Response != TypeNull || len(message.Errors) > 0) {
+		return nil, schemaError(path, nil, ErrInvalidSchema, "one-way messages have a null response and no errors")
+	}
+	return message, nil
+}
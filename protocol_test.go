@@ -0,0 +1,84 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestProtocolMarshaling(t *testing.T) {
+	cases := [][]byte{
+		[]byte(`{"protocol":"Greeter","namespace":"test","doc":"Greets people","types":[{"type":"record","name":"Greeting","fields":[{"name":"message","type":"string"}]},{"type":"error","name":"Curse","fields":[{"name":"message","type":"string"}]}],"messages":{"greet":{"doc":"Greets back","request":[{"name":"greeting","type":"Greeting"},{"name":"times","type":"int","default":1}],"response":"Greeting","errors":["Curse"]},"ping":{"request":[],"response":"null","one-way":true}},"version":"1.0"}`),
+		[]byte(`{"protocol":"Empty","types":[],"messages":{}}`),
+	}
+	for i, protocolBytes := range cases {
+		var protocol Protocol
+		err := json.Unmarshal(protocolBytes, &protocol)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		marshaledBytes, err := json.Marshal(&protocol)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if !bytes.Equal(marshaledBytes, protocolBytes) {
+			t.Errorf("case %d -\nexpected:\n%s\ngot:\n%s\n", i, protocolBytes, marshaledBytes)
+		}
+	}
+}
+
+func TestProtocolMessages(t *testing.T) {
+	var protocol Protocol
+	err := json.Unmarshal([]byte(`{"protocol":"Greeter","namespace":"test","types":[{"type":"error","name":"Curse","fields":[{"name":"message","type":"string"}]}],"messages":{"greet":{"request":[{"name":"name","type":"string"}],"response":"string","errors":["test.Curse"]}}}`), &protocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curse := protocol.Types[0].(*RecordSchema)
+	if curse.Type != TypeError || curse.TypeName() != TypeRecord || curse.FullName(protocol.Namespace) != "test.Curse" {
+		t.Errorf("expected the test.Curse error, got %v", curse)
+	}
+	message := protocol.Messages["greet"]
+	if len(message.Request) != 1 || message.Request[0].Name != "name" || message.Response != TypeString || message.OneWay {
+		t.Errorf("unexpected message %v", message)
+	}
+	union := message.ErrorUnion()
+	if len(union) != 2 || union[0] != TypeString || union[1].(*ReferenceSchema).Schema != curse {
+		t.Errorf("expected the union of string and test.Curse, got %v", union)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	cases := []struct {
+		protocolBytes string
+		expectedErr   error
+		expectedPath  string
+	}{
+		{`[]`, ErrInvalidSchema, ""},
+		{`{"namespace":"test"}`, ErrInvalidSchema, ""},
+		{`{"protocol":1}`, ErrInvalidSchema, "protocol"},
+		{`{"protocol":"P","types":["int"]}`, ErrInvalidSchema, "types[0]"},
+		{`{"protocol":"P","types":[{"type":"record","name":"R","fields":[]},{"type":"enum","name":"R","symbols":["A"]}]}`, ErrRedefinedType, "types[1].name"},
+		{`{"protocol":"P","messages":{"m":{"response":"null"}}}`, ErrInvalidSchema, "messages.m"},
+		{`{"protocol":"P","messages":{"m":{"request":{},"response":"null"}}}`, ErrInvalidSchema, "messages.m.request"},
+		{`{"protocol":"P","messages":{"m":{"request":[]}}}`, ErrInvalidSchema, "messages.m"},
+		{`{"protocol":"P","messages":{"m":{"request":[{"name":"a","type":"Unknown"}],"response":"null"}}}`, ErrUnsupportedType, "messages.m.request[0].type"},
+		{`{"protocol":"P","types":[{"type":"record","name":"R","fields":[]}],"messages":{"m":{"request":[],"response":"null","errors":["R"]}}}`, ErrInvalidSchema, "messages.m.errors[0]"},
+		{`{"protocol":"P","messages":{"m":{"request":[],"response":"string","one-way":true}}}`, ErrInvalidSchema, "messages.m"},
+		{`{"protocol":"P","messages":{"m":{"request":[],"response":"null","one-way":"yes"}}}`, ErrInvalidSchema, "messages.m.one-way"},
+	}
+	for i, c := range cases {
+		var protocol Protocol
+		err := json.Unmarshal([]byte(c.protocolBytes), &protocol)
+		if !errors.Is(err, c.expectedErr) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) || schemaErr.Path != c.expectedPath {
+			t.Errorf("case %d - expected error at %s, got %v", i, c.expectedPath, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package avro
 
 import (
 	"encoding/json"
+	"strconv"
 
 	"github.com/valyala/fastjson"
 )
@@ -14,6 +15,13 @@ type RecordFieldSchema struct {
 	Type          Schema           `json:"type"`
 	Default       *json.RawMessage `json:"default,omitempty"`
 	Order         Order            `json:"order,omitempty"`
+	Properties    Properties       `json:"-"`
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the field
+func (t RecordFieldSchema) MarshalJSON() ([]byte, error) {
+	type recordFieldSchema RecordFieldSchema
+	return marshalWithProperties(recordFieldSchema(t), t.Properties, fieldAttributes)
 }
 
 // Order - specifies how this field impacts sort ordering of this record (optional).
@@ -29,11 +37,11 @@ const (
 	Ignore Order = "ignore"
 )
 
-func translateValueToRecordFieldSchema(value *fastjson.Value, additionalTypes ...Type) (*RecordFieldSchema, error) {
+func (p *schemaParser) translateValueToRecordFieldSchema(value *fastjson.Value, path, namespace string) (*RecordFieldSchema, error) {
 	if !value.Exists("type") {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(path, nil, ErrInvalidSchema, "missing type")
 	}
-	anySchema, err := translateValue2AnySchema(value.Get("type"), additionalTypes...)
+	anySchema, err := p.translateValue2AnySchema(value.Get("type"), joinPath(path, "type"), namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -44,17 +52,29 @@ func translateValueToRecordFieldSchema(value *fastjson.Value, additionalTypes ..
 	if value.Exists("order") {
 		order = Order(value.GetStringBytes("order"))
 		if order != Ascending && order != Ignore && order != Descending {
-			return nil, ErrInvalidSchema
+			return nil, schemaError(joinPath(path, "order"), value.Get("order"), ErrInvalidSchema, "order must be ascending, descending or ignore")
 		}
 	}
 	if value.Exists("default") {
 		defaultValue = new(json.RawMessage)
 		*defaultValue = value.Get("default").MarshalTo(*defaultValue)
+		_, err = defaultDatum(anySchema, *defaultValue, namespace)
+		if err != nil {
+			return nil, schemaError(joinPath(path, "default"), value.Get("default"), ErrInvalidSchema, "default doesn't match the type of the field")
+		}
 	}
-	_, name, documentation, aliases, err := translateValueToMetaFields(value)
+	_, name, documentation, aliases, err := translateValueToMetaFields(value, path)
 	if err != nil {
 		return nil, err
 	}
+	if !isValidName(name) {
+		return nil, schemaError(joinPath(path, "name"), value.Get("name"), ErrInvalidSchema, "field name must match [A-Za-z_][A-Za-z0-9_]*")
+	}
+	for i, alias := range aliases {
+		if !isValidName(alias) {
+			return nil, schemaError(indexPath(joinPath(path, "aliases"), i), value.Get("aliases", strconv.Itoa(i)), ErrInvalidSchema, "field alias must match [A-Za-z_][A-Za-z0-9_]*")
+		}
+	}
 	return &RecordFieldSchema{
 		Name:          name,
 		Aliases:       aliases,
@@ -62,5 +82,6 @@ func translateValueToRecordFieldSchema(value *fastjson.Value, additionalTypes ..
 		Type:          anySchema,
 		Default:       defaultValue,
 		Order:         order,
+		Properties:    translateValueToProperties(value, fieldAttributes),
 	}, nil
 }
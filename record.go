@@ -4,7 +4,8 @@ import (
 	"github.com/valyala/fastjson"
 )
 
-// RecordSchema has fields
+// RecordSchema has fields.
+// Type is TypeError for the errors declared by protocols, TypeName being TypeRecord in both cases.
 type RecordSchema struct {
 	Type          Type                `json:"type"`
 	Namespace     string              `json:"namespace,omitempty"`
@@ -12,6 +13,7 @@ type RecordSchema struct {
 	Aliases       []string            `json:"aliases,omitempty"`
 	Documentation string              `json:"doc,omitempty"`
 	Fields        []RecordFieldSchema `json:"fields"`
+	Properties    Properties          `json:"-"`
 }
 
 // TypeName -
@@ -19,32 +21,58 @@ func (t *RecordSchema) TypeName() Type {
 	return TypeRecord
 }
 
-func translateValueToRecordSchema(value *fastjson.Value, additionalTypes ...Type) (Schema, error) {
+func (p *schemaParser) translateValueToRecordSchema(value *fastjson.Value, path, enclosingNamespace string) (Schema, error) {
 	if !value.Exists("fields") {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(path, nil, ErrInvalidSchema, "missing fields")
 	}
 	fieldValues, err := value.Get("fields").Array()
 	if err != nil {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(joinPath(path, "fields"), value.Get("fields"), ErrInvalidSchema, "fields must be an array")
 	}
-	namespace, name, documentation, aliases, err := translateValueToMetaFields(value)
+	namespace, name, documentation, aliases, err := translateValueToMetaFields(value, path)
 	if err != nil {
 		return nil, err
 	}
-	fieldSchemas := make([]RecordFieldSchema, 0, len(fieldValues))
-	for _, fieldValue := range fieldValues {
-		fieldSchema, err := translateValueToRecordFieldSchema(fieldValue, append(additionalTypes, Type(name))...)
-		if err != nil {
-			return nil, err
-		}
-		fieldSchemas = append(fieldSchemas, *fieldSchema)
+	typeName := TypeRecord
+	if string(value.GetStringBytes("type")) == string(TypeError) {
+		typeName = TypeError
 	}
-	return &RecordSchema{
-		Type:          TypeRecord,
+	schema := &RecordSchema{
+		Type:          typeName,
 		Namespace:     namespace,
 		Name:          name,
 		Aliases:       aliases,
 		Documentation: documentation,
-		Fields:        fieldSchemas,
-	}, nil
+		Properties:    translateValueToProperties(value, recordAttributes),
+	}
+	// the record is defined before its fields so they can refer to it
+	fullname := translateValueToFullName(value, name, namespace, enclosingNamespace)
+	err = p.define(fullname, schema, value, path)
+	if err != nil {
+		return nil, err
+	}
+	fieldSchemas := make([]RecordFieldSchema, 0, len(fieldValues))
+	for i, fieldValue := range fieldValues {
+		fieldSchema, err := p.translateValueToRecordFieldSchema(fieldValue, indexPath(joinPath(path, "fields"), i), namespaceOf(fullname))
+		if err != nil {
+			if err = p.report(err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fieldSchemas = append(fieldSchemas, *fieldSchema)
+	}
+	schema.Fields = fieldSchemas
+	return schema, nil
+}
+
+// MarshalJSON - custom properties are written along with the attributes of the record
+func (t *RecordSchema) MarshalJSON() ([]byte, error) {
+	type recordSchema RecordSchema
+	return marshalWithProperties((*recordSchema)(t), t.Properties, recordAttributes)
+}
+
+// FullName -
+func (t *RecordSchema) FullName(namespace string) string {
+	return fullName(t.Name, t.Namespace, namespace)
 }
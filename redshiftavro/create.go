@@ -127,6 +127,9 @@ func renderType(field avro.RecordFieldSchema) (typeStatement string, redshiftTyp
 	} else {
 		schema = field.Type
 	}
+	if ref, ok := schema.(*avro.ReferenceSchema); ok {
+		schema = ref.Schema
+	}
 	switch schema.TypeName() {
 	case avro.TypeInt32:
 		return string(Integer), Integer, isNullable, nil
@@ -140,15 +143,21 @@ func renderType(field avro.RecordFieldSchema) (typeStatement string, redshiftTyp
 		buf := new(bytes.Buffer)
 		buf.WriteString(string(Decimal))
 		buf.WriteRune('(')
-		dec := schema.(*avro.DerivedPrimitiveSchema)
-		precistionStr := strconv.FormatInt(int64(*dec.Precision), 10)
+		var precision, scale int
+		switch dec := schema.(type) {
+		case *avro.DerivedPrimitiveSchema:
+			precision, scale = *dec.Precision, intOrZero(dec.Scale)
+		case *avro.FixedSchema:
+			precision, scale = *dec.Precision, intOrZero(dec.Scale)
+		}
+		precistionStr := strconv.FormatInt(int64(precision), 10)
 		buf.WriteString(precistionStr)
 		buf.WriteRune(',')
-		scaleStr := strconv.FormatInt(int64(*dec.Scale), 10)
+		scaleStr := strconv.FormatInt(int64(scale), 10)
 		buf.WriteString(scaleStr)
 		buf.WriteRune(')')
 		return buf.String(), Decimal, isNullable, nil
-	case avro.TypeEnum, avro.TypeString, avro.TypeBytes:
+	case avro.TypeEnum, avro.TypeString, avro.TypeBytes, avro.Type(avro.LogicalTypeUUID):
 		buf := new(bytes.Buffer)
 		buf.WriteString(string(VarChar))
 		buf.WriteRune('(')
@@ -168,7 +177,10 @@ func renderType(field avro.RecordFieldSchema) (typeStatement string, redshiftTyp
 		return string(Boolean), Boolean, isNullable, nil
 	case avro.Type(avro.LogicalTypeDate):
 		return string(Date), Date, isNullable, nil
-	case avro.Type(avro.LogicalTypeTime), avro.Type(avro.LogicalTypeTimestamp):
+	case avro.Type(avro.LogicalTypeTimestampMillis), avro.Type(avro.LogicalTypeTimestampMicros):
+		return string(Timestamptz), Timestamptz, isNullable, nil
+	case avro.Type(avro.LogicalTypeTime), avro.Type(avro.LogicalTypeTimestamp),
+		avro.Type(avro.LogicalTypeLocalTimestampMillis), avro.Type(avro.LogicalTypeLocalTimestampMicros):
 		buf := bytes.NewBufferString(string(Timestamp))
 		buf.WriteRune(' ')
 		buf.WriteString("WITHOUT TIME ZONE")
@@ -201,13 +213,20 @@ func renderDefaultEncoding(redshiftType RedshiftType, isSortKey bool) (encoding
 		return ZSTD, nil
 	case Date:
 		return LZO, nil
-	case Timestamp:
+	case Timestamp, Timestamptz:
 		return LZO, nil
 	default:
 		return RedshiftEncoding(""), ErrUnsupportedRedshiftType
 	}
 }
 
+func intOrZero(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
 func mapSortKeys(sortKeys []string) map[string]struct{} {
 	sortKeyMap := make(map[string]struct{})
 	if sortKeys == nil {
@@ -0,0 +1,21 @@
+package avro
+
+import "encoding/json"
+
+// ReferenceSchema - use of a named schema (record, enum or fixed) defined earlier in the schema
+type ReferenceSchema struct {
+	// Name - name of the referenced type as written in the schema
+	Name string
+	// Schema - referenced schema
+	Schema NamedSchema
+}
+
+// TypeName -
+func (t *ReferenceSchema) TypeName() Type {
+	return t.Schema.TypeName()
+}
+
+// MarshalJSON - a reference is rendered as the name of the referenced type
+func (t *ReferenceSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Name)
+}
@@ -0,0 +1,285 @@
+package avro
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	bigRatType = reflect.TypeOf(big.Rat{})
+)
+
+// Struct2AVRO - derives a record schema from the given struct type.
+//
+// Exported fields are translated according to their `avro` tag, formatted as
+// `avro:"name,aliases=a|b,doc=some doc,default=json value,order=descending,precision=5,scale=2"`.
+// The name defaults to the Go field name, and fields tagged `avro:"-"` are skipped.
+// Pointers are translated to ["null", T] unions, slices to arrays, map[string]T to maps,
+// time.Time to timestamp-millis, big.Rat to decimals (precision being required) and
+// nested structs to records, defined once and referenced afterwards.
+func Struct2AVRO(structType reflect.Type) (*RecordSchema, error) {
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, ErrUnsupportedType
+	}
+	reflector := structReflector{
+		defined: make(map[reflect.Type]NamedSchema),
+	}
+	schema, err := reflector.reflectStruct(structType, structType.Name())
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+type structReflector struct {
+	// defined - named schemas already defined, including the records being defined
+	defined map[reflect.Type]NamedSchema
+}
+
+func (sr *structReflector) reflectStruct(structType reflect.Type, name string) (*RecordSchema, error) {
+	schema := &RecordSchema{
+		Type: TypeRecord,
+		Name: name,
+	}
+	sr.defined[structType] = schema
+	fields, err := sr.reflectFields(structType)
+	if err != nil {
+		return nil, err
+	}
+	schema.Fields = fields
+	return schema, nil
+}
+
+func (sr *structReflector) reflectFields(structType reflect.Type) ([]RecordFieldSchema, error) {
+	fields := make([]RecordFieldSchema, 0, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		tag, hasTag := structField.Tag.Lookup("avro")
+		if tag == "-" {
+			continue
+		}
+		if structField.Anonymous && !hasTag && structField.Type.Kind() == reflect.Struct {
+			// embedded structs are flattened, as encoding/json does
+			embeddedFields, err := sr.reflectFields(structField.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embeddedFields...)
+			continue
+		}
+		if structField.PkgPath != "" {
+			// unexported
+			continue
+		}
+		field, err := sr.reflectField(structField, tag)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, *field)
+	}
+	return fields, nil
+}
+
+func (sr *structReflector) reflectField(structField reflect.StructField, tag string) (*RecordFieldSchema, error) {
+	options, err := parseStructTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	field := &RecordFieldSchema{
+		Name:          options.name,
+		Aliases:       options.aliases,
+		Documentation: options.doc,
+		Order:         options.order,
+	}
+	if field.Name == "" {
+		field.Name = structField.Name
+	}
+	if options.defaultValue != nil {
+		field.Default = new(json.RawMessage)
+		*field.Default = options.defaultValue
+	}
+	field.Type, err = sr.reflectType(structField.Type, field.Name, options)
+	if err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (sr *structReflector) reflectType(goType reflect.Type, fieldName string, options structTagOptions) (Schema, error) {
+	if named, ok := sr.defined[goType]; ok {
+		return &ReferenceSchema{
+			Name:   named.FullName(""),
+			Schema: named,
+		}, nil
+	}
+	switch goType {
+	case timeType:
+		return &DerivedPrimitiveSchema{
+			Type:        TypeInt64,
+			LogicalType: LogicalTypeTimestampMillis,
+		}, nil
+	case bigRatType:
+		if options.precision == nil {
+			return nil, ErrInvalidSchema
+		}
+		return &DerivedPrimitiveSchema{
+			Type:        TypeBytes,
+			LogicalType: LogicalTypeDecimal,
+			Precision:   options.precision,
+			Scale:       options.scale,
+		}, nil
+	}
+	switch goType.Kind() {
+	case reflect.Bool:
+		return TypeBoolean, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return TypeInt32, nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return TypeInt64, nil
+	case reflect.Float32:
+		return TypeFloat32, nil
+	case reflect.Float64:
+		return TypeFloat64, nil
+	case reflect.String:
+		return TypeString, nil
+	case reflect.Ptr:
+		schema, err := sr.reflectType(goType.Elem(), fieldName, options)
+		if err != nil {
+			return nil, err
+		}
+		return UnionSchema{TypeNull, schema}, nil
+	case reflect.Slice:
+		if goType.Elem().Kind() == reflect.Uint8 {
+			return TypeBytes, nil
+		}
+		items, err := sr.reflectType(goType.Elem(), fieldName, structTagOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &ArraySchema{
+			Type:  TypeArray,
+			Items: items,
+		}, nil
+	case reflect.Array:
+		if goType.Elem().Kind() != reflect.Uint8 {
+			return nil, ErrUnsupportedType
+		}
+		schema := &FixedSchema{
+			Type: TypeFixed,
+			Name: typeNameOr(goType, fieldName),
+			Size: goType.Len(),
+		}
+		sr.defined[goType] = schema
+		return schema, nil
+	case reflect.Map:
+		if goType.Key().Kind() != reflect.String {
+			return nil, ErrUnsupportedType
+		}
+		values, err := sr.reflectType(goType.Elem(), fieldName, structTagOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &MapSchema{
+			Type:  TypeMap,
+			Value: values,
+		}, nil
+	case reflect.Struct:
+		return sr.reflectStruct(goType, typeNameOr(goType, fieldName))
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+// typeNameOr returns the name of the Go type, or the given name if the type is anonymous
+func typeNameOr(goType reflect.Type, name string) string {
+	if goType.Name() != "" {
+		return goType.Name()
+	}
+	return name
+}
+
+type structTagOptions struct {
+	name         string
+	aliases      []string
+	doc          string
+	defaultValue json.RawMessage
+	order        Order
+	precision    *int
+	scale        *int
+}
+
+var structTagKeys = []string{"aliases", "doc", "default", "order", "precision", "scale"}
+
+// parseStructTag - options are comma separated key=value pairs following the name.
+// A comma which isn't followed by a known key belongs to the previous value,
+// so doc and default may contain commas.
+func parseStructTag(tag string) (options structTagOptions, err error) {
+	parts := strings.Split(tag, ",")
+	options.name = parts[0]
+	values := make(map[string]string)
+	var key string
+	for _, part := range parts[1:] {
+		if k := structTagKey(part); k != "" {
+			key = k
+			values[key] = part[len(k)+1:]
+		} else if key != "" {
+			values[key] += "," + part
+		} else {
+			return options, ErrInvalidSchema
+		}
+	}
+	if aliases, ok := values["aliases"]; ok {
+		options.aliases = strings.Split(aliases, "|")
+	}
+	options.doc = values["doc"]
+	if defaultValue, ok := values["default"]; ok {
+		if !json.Valid([]byte(defaultValue)) {
+			return options, ErrInvalidSchema
+		}
+		options.defaultValue = json.RawMessage(defaultValue)
+	}
+	if order, ok := values["order"]; ok {
+		options.order = Order(order)
+		if options.order != Ascending && options.order != Descending && options.order != Ignore {
+			return options, ErrInvalidSchema
+		}
+	}
+	if precision, ok := values["precision"]; ok {
+		options.precision, err = parseStructTagInt(precision)
+		if err != nil {
+			return options, err
+		}
+	}
+	if scale, ok := values["scale"]; ok {
+		options.scale, err = parseStructTagInt(scale)
+		if err != nil {
+			return options, err
+		}
+	}
+	return options, nil
+}
+
+func structTagKey(part string) string {
+	for _, key := range structTagKeys {
+		if strings.HasPrefix(part, key+"=") {
+			return key
+		}
+	}
+	return ""
+}
+
+func parseStructTagInt(str string) (*int, error) {
+	i, err := strconv.Atoi(str)
+	if err != nil || i < 0 {
+		return nil, ErrInvalidSchema
+	}
+	return &i, nil
+}
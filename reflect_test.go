@@ -0,0 +1,93 @@
+package avro
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reflectedMD5 [16]byte
+
+type reflectedAuthor struct {
+	Name  string `avro:"name"`
+	Email *string
+}
+
+type reflectedAudit struct {
+	CreatedAt time.Time `avro:"created_at"`
+}
+
+type reflectedPost struct {
+	reflectedAudit
+	ID          int64            `avro:"id,order=descending"`
+	Title       string           `avro:"title,aliases=headline|subject,doc=title of the post, in plain text"`
+	Body        []byte           `avro:"body"`
+	Draft       bool             `avro:"draft,default=false"`
+	Rating      float32          `avro:"rating"`
+	Score       float64          `avro:"score"`
+	Views       int32            `avro:"views,default=0"`
+	Price       big.Rat          `avro:"price,precision=5,scale=2"`
+	Hash        reflectedMD5     `avro:"hash"`
+	Tags        []string         `avro:"tags,default=[]"`
+	Meta        map[string]int   `avro:"meta,default={\"a\":1,\"b\":2}"`
+	Author      reflectedAuthor  `avro:"author"`
+	Reviewer    *reflectedAuthor `avro:"reviewer,default=null"`
+	PreviousMD5 *reflectedMD5    `avro:"previous_hash"`
+	Related     []*reflectedPost `avro:"related"`
+	Ignored     string           `avro:"-"`
+	unexported  string
+}
+
+func TestStruct2AVRO(t *testing.T) {
+	schema, err := Struct2AVRO(reflect.TypeOf(&reflectedPost{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaBytes, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"record","name":"reflectedPost","fields":[{"name":"created_at","type":{"type":"long","logicalType":"timestamp-millis"}},{"name":"id","type":"long","order":"descending"},{"name":"title","aliases":["headline","subject"],"doc":"title of the post, in plain text","type":"string"},{"name":"body","type":"bytes"},{"name":"draft","type":"boolean","default":false},{"name":"rating","type":"float"},{"name":"score","type":"double"},{"name":"views","type":"int","default":0},{"name":"price","type":{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}},{"name":"hash","type":{"type":"fixed","name":"reflectedMD5","size":16}},{"name":"tags","type":{"type":"array","items":"string"},"default":[]},{"name":"meta","type":{"type":"map","values":"long"},"default":{"a":1,"b":2}},{"name":"author","type":{"type":"record","name":"reflectedAuthor","fields":[{"name":"name","type":"string"},{"name":"Email","type":["null","string"]}]}},{"name":"reviewer","type":["null","reflectedAuthor"],"default":null},{"name":"previous_hash","type":["null","reflectedMD5"]},{"name":"related","type":{"type":"array","items":["null","reflectedPost"]}}]}`
+	if string(schemaBytes) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s\n", expected, schemaBytes)
+	}
+	var anySchema AnySchema
+	err = json.Unmarshal(schemaBytes, &anySchema)
+	if err != nil {
+		t.Errorf("reflected schema cannot be parsed: %v", err)
+	}
+}
+
+func TestStruct2AVROErrors(t *testing.T) {
+	cases := []struct {
+		value       interface{}
+		expectedErr error
+	}{
+		{42, ErrUnsupportedType},
+		{struct{ C chan int }{}, ErrUnsupportedType},
+		{struct{ M map[int]string }{}, ErrUnsupportedType},
+		{struct{ U uint64 }{}, ErrUnsupportedType},
+		{struct{ A [4]int }{}, ErrUnsupportedType},
+		{struct{ R big.Rat }{}, ErrInvalidSchema},
+		{struct {
+			I int `avro:"i,default={"`
+		}{}, ErrInvalidSchema},
+		{struct {
+			I int `avro:"i,order=random"`
+		}{}, ErrInvalidSchema},
+		{struct {
+			I int `avro:"i,something"`
+		}{}, ErrInvalidSchema},
+		{struct {
+			R big.Rat `avro:"r,precision=-1"`
+		}{}, ErrInvalidSchema},
+	}
+	for i, c := range cases {
+		_, err := Struct2AVRO(reflect.TypeOf(c.value))
+		if err != c.expectedErr {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+		}
+	}
+}
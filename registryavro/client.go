@@ -0,0 +1,278 @@
+package registryavro
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/khezen/avro"
+)
+
+// LatestVersion - designates the latest version of a subject
+const LatestVersion = -1
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// SubjectSchema - schema registered under a subject
+type SubjectSchema struct {
+	Subject string
+	ID      int
+	Version int
+	Schema  avro.Schema
+}
+
+// Client - client of a Confluent compatible schema registry.
+// Schemas are cached by ID, and IDs by subject and schema, so that each one is only fetched once.
+type Client struct {
+	url        string
+	httpClient *http.Client
+	mu         sync.RWMutex
+	schemas    map[int]avro.Schema
+	ids        map[string]int
+}
+
+// NewClient - client of the registry at the URL. http.DefaultClient is used if httpClient is nil.
+func NewClient(url string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		url:        strings.TrimSuffix(url, "/"),
+		httpClient: httpClient,
+		schemas:    make(map[int]avro.Schema),
+		ids:        make(map[string]int),
+	}
+}
+
+// schemaRequest - body of the requests sending a schema
+type schemaRequest struct {
+	Schema string `json:"schema"`
+}
+
+// subjectSchemaResponse - body of the responses describing a schema registered under a subject
+type subjectSchemaResponse struct {
+	Subject string `json:"subject"`
+	ID      int    `json:"id"`
+	Version int    `json:"version"`
+	Schema  string `json:"schema"`
+}
+
+// Register - registers the schema under the subject, if not already, and returns its ID
+func (c *Client) Register(subject string, schema avro.Schema) (int, error) {
+	schemaJSON, err := avro.MarshalSchema(schema)
+	if err != nil {
+		return 0, err
+	}
+	key := cacheKey(subject, schemaJSON)
+	c.mu.RLock()
+	id, ok := c.ids[key]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+	var response struct {
+		ID int `json:"id"`
+	}
+	err = c.do(http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", schemaRequest{string(schemaJSON)}, &response)
+	if err != nil {
+		return 0, err
+	}
+	c.cache(response.ID, schema, key)
+	return response.ID, nil
+}
+
+// Lookup - returns the version of the subject registering the schema
+func (c *Client) Lookup(subject string, schema avro.Schema) (*SubjectSchema, error) {
+	schemaJSON, err := avro.MarshalSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	var response subjectSchemaResponse
+	err = c.do(http.MethodPost, "/subjects/"+url.PathEscape(subject), schemaRequest{string(schemaJSON)}, &response)
+	if err != nil {
+		return nil, err
+	}
+	c.cache(response.ID, schema, cacheKey(subject, schemaJSON))
+	return &SubjectSchema{response.Subject, response.ID, response.Version, schema}, nil
+}
+
+// SchemaByID - returns the schema registered with the ID
+func (c *Client) SchemaByID(id int) (avro.Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+	var response schemaRequest
+	err := c.do(http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	schema, err = unmarshalSchema(response.Schema)
+	if err != nil {
+		return nil, err
+	}
+	c.cache(id, schema, "")
+	return schema, nil
+}
+
+// SchemaBySubject - returns the version of the subject, or its latest version with LatestVersion
+func (c *Client) SchemaBySubject(subject string, version int) (*SubjectSchema, error) {
+	var response subjectSchemaResponse
+	err := c.do(http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/"+versionPath(version), nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := unmarshalSchema(response.Schema)
+	if err != nil {
+		return nil, err
+	}
+	c.cache(response.ID, schema, "")
+	return &SubjectSchema{response.Subject, response.ID, response.Version, schema}, nil
+}
+
+// Subjects - returns the registered subjects
+func (c *Client) Subjects() ([]string, error) {
+	var subjects []string
+	err := c.do(http.MethodGet, "/subjects", nil, &subjects)
+	return subjects, err
+}
+
+// Versions - returns the registered versions of the subject
+func (c *Client) Versions(subject string) ([]int, error) {
+	var versions []int
+	err := c.do(http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions", nil, &versions)
+	return versions, err
+}
+
+// CheckCompatibility - reports whether the schema is compatible with the version of the subject,
+// or with its latest version with LatestVersion, according to the compatibility level of the subject
+func (c *Client) CheckCompatibility(subject string, version int, schema avro.Schema) (bool, error) {
+	schemaJSON, err := avro.MarshalSchema(schema)
+	if err != nil {
+		return false, err
+	}
+	var response struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	err = c.do(http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/"+versionPath(version), schemaRequest{string(schemaJSON)}, &response)
+	return response.IsCompatible, err
+}
+
+// DeleteSubject - deletes the versions of the subject and returns them
+func (c *Client) DeleteSubject(subject string) ([]int, error) {
+	var versions []int
+	err := c.do(http.MethodDelete, "/subjects/"+url.PathEscape(subject), nil, &versions)
+	if err != nil {
+		return nil, err
+	}
+	c.forget(subject)
+	return versions, nil
+}
+
+// DeleteVersion - deletes the version of the subject, or its latest version with LatestVersion, and returns it
+func (c *Client) DeleteVersion(subject string, version int) (int, error) {
+	var deleted int
+	err := c.do(http.MethodDelete, "/subjects/"+url.PathEscape(subject)+"/versions/"+versionPath(version), nil, &deleted)
+	if err != nil {
+		return 0, err
+	}
+	c.forget(subject)
+	return deleted, nil
+}
+
+// Compatibility - returns the compatibility level of the subject, or the default one if subject is empty
+func (c *Client) Compatibility(subject string) (avro.CompatibilityLevel, error) {
+	var response configResponse
+	err := c.do(http.MethodGet, configPath(subject), nil, &response)
+	return response.CompatibilityLevel, err
+}
+
+// SetCompatibility - sets the compatibility level of the subject, or the default one if subject is empty
+func (c *Client) SetCompatibility(subject string, level avro.CompatibilityLevel) error {
+	var response configRequest
+	return c.do(http.MethodPut, configPath(subject), configRequest{level}, &response)
+}
+
+func (c *Client) cache(id int, schema avro.Schema, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schemas[id] = schema
+	if key != "" {
+		c.ids[key] = id
+	}
+}
+
+// forget removes the registrations of the subject from the cache, so they are registered again once deleted
+func (c *Client) forget(subject string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.ids {
+		if strings.HasPrefix(key, subject+"\x00") {
+			delete(c.ids, key)
+		}
+	}
+}
+
+// do sends the request to the registry and decodes its response, errors being returned as *Error
+func (c *Client) do(method, path string, request, response interface{}) error {
+	var body bytes.Buffer
+	if request != nil {
+		err := json.NewEncoder(&body).Encode(request)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, c.url+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", contentType)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		registryErr := &Error{StatusCode: resp.StatusCode}
+		err = json.NewDecoder(resp.Body).Decode(registryErr)
+		if err != nil {
+			registryErr.Message = resp.Status
+		}
+		return registryErr
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+func cacheKey(subject string, schemaJSON []byte) string {
+	return subject + "\x00" + string(schemaJSON)
+}
+
+func configPath(subject string) string {
+	if subject == "" {
+		return "/config"
+	}
+	return "/config/" + url.PathEscape(subject)
+}
+
+func versionPath(version int) string {
+	if version == LatestVersion {
+		return "latest"
+	}
+	return strconv.Itoa(version)
+}
+
+func unmarshalSchema(schemaJSON string) (avro.Schema, error) {
+	var anySchema avro.AnySchema
+	err := json.Unmarshal([]byte(schemaJSON), &anySchema)
+	if err != nil {
+		return nil, err
+	}
+	return anySchema.Schema(), nil
+}
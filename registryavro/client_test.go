@@ -0,0 +1,303 @@
+package registryavro
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/khezen/avro"
+)
+
+// testRegistry - in-memory stand-in of a registry, checking the compatibility with the latest version only
+type testRegistry struct {
+	schemas  []string
+	subjects map[string][]int
+	requests int32
+}
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{subjects: make(map[string][]int)}
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	atomic.AddInt32(&r.requests, 1)
+	w.Header().Set("Content-Type", contentType)
+	path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	var body schemaRequest
+	if req.Method == http.MethodPost {
+		json.NewDecoder(req.Body).Decode(&body)
+	}
+	switch {
+	case len(path) == 1 && path[0] == "subjects":
+		subjects := []string{}
+		for subject := range r.subjects {
+			subjects = append(subjects, subject)
+		}
+		json.NewEncoder(w).Encode(subjects)
+	case len(path) == 3 && path[0] == "schemas":
+		id, _ := strconv.Atoi(path[2])
+		if id < 1 || id > len(r.schemas) {
+			r.fail(w, http.StatusNotFound, codeSchemaNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(schemaRequest{r.schemas[id-1]})
+	case len(path) == 2 && req.Method == http.MethodPost:
+		for version, id := range r.subjects[path[1]] {
+			if r.schemas[id-1] == body.Schema {
+				json.NewEncoder(w).Encode(subjectSchemaResponse{path[1], id, version + 1, body.Schema})
+				return
+			}
+		}
+		r.fail(w, http.StatusNotFound, codeSchemaNotFound)
+	case len(path) == 3 && req.Method == http.MethodPost:
+		schema, err := unmarshalSchema(body.Schema)
+		if err != nil {
+			r.fail(w, http.StatusUnprocessableEntity, codeInvalidSchema)
+			return
+		}
+		if !r.compatible(path[1], schema) {
+			r.fail(w, http.StatusConflict, codeIncompatibleSchema)
+			return
+		}
+		id := 0
+		for i, registered := range r.schemas {
+			if registered == body.Schema {
+				id = i + 1
+			}
+		}
+		if id == 0 {
+			r.schemas = append(r.schemas, body.Schema)
+			id = len(r.schemas)
+		}
+		r.subjects[path[1]] = append(r.subjects[path[1]], id)
+		json.NewEncoder(w).Encode(map[string]int{"id": id})
+	case len(path) == 3:
+		ids, ok := r.subjects[path[1]]
+		if !ok {
+			r.fail(w, http.StatusNotFound, codeSubjectNotFound)
+			return
+		}
+		versions := []int{}
+		for i := range ids {
+			versions = append(versions, i+1)
+		}
+		json.NewEncoder(w).Encode(versions)
+	case len(path) == 4 && path[3] == "latest":
+		ids, ok := r.subjects[path[1]]
+		if !ok {
+			r.fail(w, http.StatusNotFound, codeSubjectNotFound)
+			return
+		}
+		id := ids[len(ids)-1]
+		json.NewEncoder(w).Encode(subjectSchemaResponse{path[1], id, len(ids), r.schemas[id-1]})
+	case len(path) == 4:
+		version, err := strconv.Atoi(path[3])
+		if err != nil {
+			r.fail(w, http.StatusUnprocessableEntity, codeInvalidVersion)
+			return
+		}
+		ids := r.subjects[path[1]]
+		if version < 1 || version > len(ids) {
+			r.fail(w, http.StatusNotFound, codeVersionNotFound)
+			return
+		}
+		id := ids[version-1]
+		json.NewEncoder(w).Encode(subjectSchemaResponse{path[1], id, version, r.schemas[id-1]})
+	case len(path) == 5 && path[0] == "compatibility":
+		schema, err := unmarshalSchema(body.Schema)
+		if err != nil {
+			r.fail(w, http.StatusUnprocessableEntity, codeInvalidSchema)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"is_compatible": r.compatible(path[2], schema)})
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (r *testRegistry) compatible(subject string, schema avro.Schema) bool {
+	ids := r.subjects[subject]
+	if len(ids) == 0 {
+		return true
+	}
+	latest, _ := unmarshalSchema(r.schemas[ids[len(ids)-1]-1])
+	incompatibilities, err := avro.CheckCompatibilityLevel(avro.CompatibilityBackward, schema, []avro.Schema{latest})
+	return err == nil && len(incompatibilities) == 0
+}
+
+func (r *testRegistry) fail(w http.ResponseWriter, status, code int) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Error{Code: code, Message: http.StatusText(status)})
+}
+
+func mustUnmarshalSchema(t *testing.T, schemaJSON string) avro.Schema {
+	schema, err := unmarshalSchema(schemaJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+const (
+	userSchemaV1 = `{"type":"record","name":"User","namespace":"test","fields":[{"name":"name","type":"string"}]}`
+	userSchemaV2 = `{"type":"record","name":"User","namespace":"test","fields":[{"name":"name","type":"string"},{"name":"age","type":"int","default":0}]}`
+	userSchemaV3 = `{"type":"record","name":"User","namespace":"test","fields":[{"name":"name","type":"string"},{"name":"email","type":"string"}]}`
+)
+
+func TestClient(t *testing.T) {
+	registry := newTestRegistry()
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	client := NewClient(server.URL+"/", nil)
+	v1, v2, v3 := mustUnmarshalSchema(t, userSchemaV1), mustUnmarshalSchema(t, userSchemaV2), mustUnmarshalSchema(t, userSchemaV3)
+	_, err := client.Lookup("users-value", v1)
+	if !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("expected %v, got %v", ErrSchemaNotFound, err)
+	}
+	cases := []struct {
+		schema      avro.Schema
+		expectedID  int
+		expectedErr error
+	}{
+		{v1, 1, nil},
+		{v1, 1, nil},
+		{v2, 2, nil},
+		{v3, 0, avro.ErrIncompatibleSchema},
+	}
+	for i, c := range cases {
+		id, err := client.Register("users-value", c.schema)
+		if !errors.Is(err, c.expectedErr) || id != c.expectedID {
+			t.Errorf("case %d - expected %d, %v, got %d, %v", i, c.expectedID, c.expectedErr, id, err)
+		}
+	}
+	// registrations are cached
+	requests := atomic.LoadInt32(&registry.requests)
+	if requests != 4 {
+		t.Errorf("expected 4 requests, got %d", requests)
+	}
+	id, err := client.Register("users-key", v1)
+	if err != nil || id != 1 {
+		t.Errorf("expected 1, got %d, %v", id, err)
+	}
+	schema, err := client.SchemaByID(2)
+	if err != nil || schema != v2 {
+		t.Errorf("expected %v, got %v, %v", v2, schema, err)
+	}
+	if requests = atomic.LoadInt32(&registry.requests); requests != 5 {
+		t.Errorf("expected 5 requests, got %d", requests)
+	}
+	found, err := client.Lookup("users-value", v2)
+	if err != nil || !reflect.DeepEqual(found, &SubjectSchema{"users-value", 2, 2, v2}) {
+		t.Errorf("expected version 2, got %v, %v", found, err)
+	}
+	versionCases := []struct {
+		subject         string
+		version         int
+		expectedID      int
+		expectedVersion int
+		expectedErr     error
+	}{
+		{"users-value", 1, 1, 1, nil},
+		{"users-value", LatestVersion, 2, 2, nil},
+		{"users-value", 3, 0, 0, ErrVersionNotFound},
+		{"orders-value", LatestVersion, 0, 0, ErrSubjectNotFound},
+	}
+	for i, c := range versionCases {
+		found, err := client.SchemaBySubject(c.subject, c.version)
+		if !errors.Is(err, c.expectedErr) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if err == nil && (found.ID != c.expectedID || found.Version != c.expectedVersion || found.Subject != c.subject) {
+			t.Errorf("case %d - expected %d/%d, got %v", i, c.expectedID, c.expectedVersion, found)
+		}
+	}
+	subjects, err := client.Subjects()
+	if err != nil || len(subjects) != 2 {
+		t.Errorf("expected 2 subjects, got %v, %v", subjects, err)
+	}
+	versions, err := client.Versions("users-value")
+	if err != nil || !reflect.DeepEqual(versions, []int{1, 2}) {
+		t.Errorf("expected [1 2], got %v, %v", versions, err)
+	}
+	_, err = client.Versions("orders-value")
+	var registryErr *Error
+	if !errors.As(err, &registryErr) || registryErr.StatusCode != http.StatusNotFound || registryErr.Code != codeSubjectNotFound {
+		t.Errorf("expected %v, got %v", ErrSubjectNotFound, err)
+	}
+	compatible, err := client.CheckCompatibility("users-value", LatestVersion, v1)
+	if err != nil || !compatible {
+		t.Errorf("expected compatible, got %v, %v", compatible, err)
+	}
+	compatible, err = client.CheckCompatibility("users-value", LatestVersion, v3)
+	if err != nil || compatible {
+		t.Errorf("expected incompatible, got %v, %v", compatible, err)
+	}
+	_, err = client.SchemaByID(42)
+	if !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("expected %v, got %v", ErrSchemaNotFound, err)
+	}
+}
+
+func TestSerializer(t *testing.T) {
+	registry := newTestRegistry()
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	v1, v2 := mustUnmarshalSchema(t, userSchemaV1), mustUnmarshalSchema(t, userSchemaV2)
+	serializer := NewSerializer(NewClient(server.URL, nil), "users-value", v1)
+	cases := []struct {
+		reader   avro.Schema
+		expected interface{}
+	}{
+		{nil, map[string]interface{}{"name": "gopher"}},
+		{v2, map[string]interface{}{"name": "gopher", "age": int32(0)}},
+	}
+	for i, c := range cases {
+		buf, err := serializer.Serialize(map[string]interface{}{"name": "gopher"})
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		expectedBuf := append([]byte{0, 0, 0, 0, 1}, 12, 'g', 'o', 'p', 'h', 'e', 'r')
+		if !reflect.DeepEqual(buf, expectedBuf) {
+			t.Errorf("case %d - expected %v, got %v", i, expectedBuf, buf)
+		}
+		deserializer := NewDeserializer(NewClient(server.URL, nil), c.reader)
+		datum, err := deserializer.Deserialize(buf)
+		if err != nil || !reflect.DeepEqual(datum, c.expected) {
+			t.Errorf("case %d - expected %v, got %v, %v", i, c.expected, datum, err)
+		}
+	}
+	// the schema is registered once
+	if requests := atomic.LoadInt32(&registry.requests); requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+	deserializer := NewDeserializer(NewClient(server.URL, nil), nil)
+	errorCases := []struct {
+		buf         []byte
+		expectedErr error
+	}{
+		{[]byte{0, 0, 0}, ErrInvalidWireFormat},
+		{[]byte{1, 0, 0, 0, 1, 0}, ErrInvalidWireFormat},
+		{[]byte{0, 0, 0, 0, 2, 0}, ErrSchemaNotFound},
+		{[]byte{0, 0, 0, 0, 1, 2}, io.ErrUnexpectedEOF},
+		{[]byte{0, 0, 0, 0, 1, 0, 0}, avro.ErrInvalidEncoding},
+	}
+	for i, c := range errorCases {
+		_, err := deserializer.Deserialize(c.buf)
+		if !errors.Is(err, c.expectedErr) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+		}
+	}
+	_, err := NewSerializer(NewClient(server.URL, nil), "users-value", mustUnmarshalSchema(t, userSchemaV3)).Serialize(map[string]interface{}{})
+	if !errors.Is(err, avro.ErrIncompatibleSchema) {
+		t.Errorf("expected %v, got %v", avro.ErrIncompatibleSchema, err)
+	}
+}
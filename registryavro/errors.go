@@ -0,0 +1,68 @@
+package registryavro
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/khezen/avro"
+)
+
+var (
+	// ErrSubjectNotFound - the subject is not registered
+	ErrSubjectNotFound = errors.New("ErrSubjectNotFound")
+	// ErrVersionNotFound - the version of the subject is not registered
+	ErrVersionNotFound = errors.New("ErrVersionNotFound")
+	// ErrSchemaNotFound - no schema is registered with this ID, or under this subject
+	ErrSchemaNotFound = errors.New("ErrSchemaNotFound")
+	// ErrInvalidVersion - versions are positive integers or "latest"
+	ErrInvalidVersion = errors.New("ErrInvalidVersion")
+	// ErrInvalidCompatibilityLevel - the compatibility level is unknown
+	ErrInvalidCompatibilityLevel = errors.New("ErrInvalidCompatibilityLevel")
+	// ErrInvalidWireFormat - the data doesn't start with the magic byte and the ID of its schema
+	ErrInvalidWireFormat = errors.New("ErrInvalidWireFormat")
+)
+
+// Error codes of the registry API
+const (
+	codeSubjectNotFound           = 40401
+	codeVersionNotFound           = 40402
+	codeSchemaNotFound            = 40403
+	codeIncompatibleSchema        = 409
+	codeInvalidSchema             = 42201
+	codeInvalidVersion            = 42202
+	codeInvalidCompatibilityLevel = 42203
+	codeNotFound                  = 404
+	codeMethodNotAllowed          = 405
+	codeStorage                   = 50001
+)
+
+// Error - error responded by the registry, matching the sentinel error of its code with errors.Is:
+// ErrSubjectNotFound, ErrVersionNotFound, ErrSchemaNotFound, avro.ErrIncompatibleSchema, avro.ErrInvalidSchema,
+// ErrInvalidVersion or ErrInvalidCompatibilityLevel.
+type Error struct {
+	// StatusCode - HTTP status of the response
+	StatusCode int `json:"-"`
+	// Code - error code of the registry API
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return "registry error " + strconv.Itoa(e.Code) + ": " + e.Message
+}
+
+// Is - reports whether target is the sentinel error of the code
+func (e *Error) Is(target error) bool {
+	sentinel, ok := errorCodes[e.Code]
+	return ok && sentinel == target
+}
+
+var errorCodes = map[int]error{
+	codeSubjectNotFound:           ErrSubjectNotFound,
+	codeVersionNotFound:           ErrVersionNotFound,
+	codeSchemaNotFound:            ErrSchemaNotFound,
+	codeIncompatibleSchema:        avro.ErrIncompatibleSchema,
+	codeInvalidSchema:             avro.ErrInvalidSchema,
+	codeInvalidVersion:            ErrInvalidVersion,
+	codeInvalidCompatibilityLevel: ErrInvalidCompatibilityLevel,
+}
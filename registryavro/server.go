@@ -0,0 +1,546 @@
+package registryavro
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/khezen/avro"
+)
+
+// DefaultCompatibility - compatibility level of the subjects when none is configured
+const DefaultCompatibility = avro.CompatibilityBackward
+
+// Server - schema registry serving the core endpoints of the Confluent API over HTTP:
+// subjects, their versions, schemas by ID, compatibility levels and compatibility checks.
+// Schemas are parsed, deduplicated by their normalized JSON and checked for compatibility by this package.
+type Server struct {
+	storage Storage
+	mu      sync.RWMutex
+	state   *State
+	// schemas - parsed schemas, by ID
+	schemas map[int]avro.Schema
+	// ids - IDs of the schemas, by normalized JSON
+	ids map[string]int
+	// normalized - normalized JSON of the schemas, by ID
+	normalized map[int]string
+	nextID     int
+}
+
+// NewServer - registry loading its state from the storage and saving each change to it.
+// The registry is kept in memory only if storage is nil.
+func NewServer(storage Storage) (*Server, error) {
+	s := &Server{
+		storage:    storage,
+		state:      newState(),
+		schemas:    make(map[int]avro.Schema),
+		ids:        make(map[string]int),
+		normalized: make(map[int]string),
+		nextID:     1,
+	}
+	if storage == nil {
+		return s, nil
+	}
+	state, err := storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return s, nil
+	}
+	s.state = state
+	for id, schemaJSON := range state.Schemas {
+		schema, err := unmarshalSchema(schemaJSON)
+		if err != nil {
+			return nil, err
+		}
+		err = s.index(id, schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// index caches the parsed schema and its normalized JSON
+func (s *Server) index(id int, schema avro.Schema) error {
+	normalized, err := avro.MarshalSchema(schema)
+	if err != nil {
+		return err
+	}
+	s.schemas[id] = schema
+	s.normalized[id] = string(normalized)
+	if _, ok := s.ids[string(normalized)]; !ok {
+		s.ids[string(normalized)] = id
+	}
+	if id >= s.nextID {
+		s.nextID = id + 1
+	}
+	return nil
+}
+
+// ServeHTTP - answers the request to the registry API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var path []string
+	for _, segment := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		segment, err := url.PathUnescape(segment)
+		if err != nil {
+			s.writeError(w, newError(http.StatusNotFound, codeNotFound, err.Error()))
+			return
+		}
+		path = append(path, segment)
+	}
+	var (
+		response interface{}
+		err      error
+	)
+	route := r.Method + " " + routeOf(path)
+	switch route {
+	case "GET /subjects":
+		response = s.subjects()
+	case "GET /schemas/types":
+		response = []string{"AVRO"}
+	case "GET /schemas/ids/{}":
+		response, err = s.schemaByID(path[2])
+	case "POST /subjects/{}":
+		response, err = s.lookup(path[1], r)
+	case "DELETE /subjects/{}":
+		response, err = s.deleteSubject(path[1])
+	case "GET /subjects/{}/versions":
+		response, err = s.versions(path[1])
+	case "POST /subjects/{}/versions":
+		response, err = s.register(path[1], r)
+	case "GET /subjects/{}/versions/{}":
+		response, err = s.version(path[1], path[3])
+	case "GET /subjects/{}/versions/{}/schema":
+		var version *subjectSchemaResponse
+		version, err = s.version(path[1], path[3])
+		if err == nil {
+			response = json.RawMessage(version.Schema)
+		}
+	case "DELETE /subjects/{}/versions/{}":
+		response, err = s.deleteVersion(path[1], path[3])
+	case "GET /config":
+		response = s.config("")
+	case "GET /config/{}":
+		response = s.config(path[1])
+	case "PUT /config":
+		response, err = s.setConfig("", r)
+	case "PUT /config/{}":
+		response, err = s.setConfig(path[1], r)
+	case "DELETE /config/{}":
+		response, err = s.deleteConfig(path[1])
+	case "POST /compatibility/subjects/{}/versions/{}":
+		response, err = s.checkCompatibility(path[2], path[4], r)
+	default:
+		if routeExists(routeOf(path)) {
+			err = newError(http.StatusMethodNotAllowed, codeMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		} else {
+			err = newError(http.StatusNotFound, codeNotFound, http.StatusText(http.StatusNotFound))
+		}
+	}
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	json.NewEncoder(w).Encode(response)
+}
+
+// routes - paths of the API, parameters being replaced with {}
+var routes = []string{
+	"/subjects",
+	"/schemas/types",
+	"/schemas/ids/{}",
+	"/subjects/{}",
+	"/subjects/{}/versions",
+	"/subjects/{}/versions/{}",
+	"/subjects/{}/versions/{}/schema",
+	"/config",
+	"/config/{}",
+	"/compatibility/subjects/{}/versions/{}",
+}
+
+// routeOf returns the route matching the path, the path itself if none
+func routeOf(path []string) string {
+	for _, route := range routes {
+		segments := strings.Split(strings.TrimPrefix(route, "/"), "/")
+		if len(segments) != len(path) {
+			continue
+		}
+		matches := true
+		for i, segment := range segments {
+			if segment != "{}" && segment != path[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return route
+		}
+	}
+	return "/" + strings.Join(path, "/")
+}
+
+func routeExists(route string) bool {
+	for _, existing := range routes {
+		if existing == route {
+			return true
+		}
+	}
+	return false
+}
+
+func newError(statusCode, code int, message string) *Error {
+	return &Error{StatusCode: statusCode, Code: code, Message: message}
+}
+
+// writeError responds with the error, unexpected errors being storage errors
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	registryErr, ok := err.(*Error)
+	if !ok {
+		registryErr = newError(http.StatusInternalServerError, codeStorage, err.Error())
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(registryErr.StatusCode)
+	json.NewEncoder(w).Encode(registryErr)
+}
+
+// update applies the change to a copy of the state, which replaces the current one once saved
+func (s *Server) update(change func(state *State)) error {
+	state := s.state.clone()
+	change(state)
+	if s.storage != nil {
+		err := s.storage.Save(state)
+		if err != nil {
+			return err
+		}
+	}
+	s.state = state
+	return nil
+}
+
+func (s *Server) subjects() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subjects := make([]string, 0, len(s.state.Subjects))
+	for subject := range s.state.Subjects {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+func (s *Server) schemaByID(idParam string) (interface{}, error) {
+	id, err := strconv.Atoi(idParam)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schema, ok := s.state.Schemas[id]
+	if err != nil || !ok {
+		return nil, newError(http.StatusNotFound, codeSchemaNotFound, "schema "+idParam+" not found")
+	}
+	return schemaRequest{schema}, nil
+}
+
+func (s *Server) versions(subject string) ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subjectVersions, err := s.subjectVersions(subject)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int, 0, len(subjectVersions))
+	for _, version := range subjectVersions {
+		versions = append(versions, version.Version)
+	}
+	return versions, nil
+}
+
+func (s *Server) subjectVersions(subject string) ([]SubjectVersion, error) {
+	versions, ok := s.state.Subjects[subject]
+	if !ok {
+		return nil, newError(http.StatusNotFound, codeSubjectNotFound, "subject "+subject+" not found")
+	}
+	return versions, nil
+}
+
+// versionIndex returns the index of the version, "latest" or a positive integer, in the versions of the subject
+func (s *Server) versionIndex(subject, versionParam string) (int, error) {
+	versions, err := s.subjectVersions(subject)
+	if err != nil {
+		return 0, err
+	}
+	version, err := strconv.Atoi(versionParam)
+	if versionParam == "latest" || (err == nil && version == LatestVersion) {
+		return len(versions) - 1, nil
+	}
+	if err != nil || version < 1 {
+		return 0, newError(http.StatusUnprocessableEntity, codeInvalidVersion, "invalid version "+versionParam)
+	}
+	for i := range versions {
+		if versions[i].Version == version {
+			return i, nil
+		}
+	}
+	return 0, newError(http.StatusNotFound, codeVersionNotFound, "version "+versionParam+" of subject "+subject+" not found")
+}
+
+func (s *Server) version(subject, versionParam string) (*subjectSchemaResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, err := s.versionIndex(subject, versionParam)
+	if err != nil {
+		return nil, err
+	}
+	version := s.state.Subjects[subject][i]
+	return &subjectSchemaResponse{subject, version.ID, version.Version, s.state.Schemas[version.ID]}, nil
+}
+
+// readSchema decodes and parses the schema posted in the body of the request, returning its normalized JSON.
+// Unlike the canonical form, the normalized JSON keeps defaults, docs, aliases and properties,
+// so that schemas differing by them are registered apart.
+func readSchema(r *http.Request) (schemaJSON string, schema avro.Schema, normalized string, err error) {
+	var request schemaRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		return "", nil, "", newError(http.StatusUnprocessableEntity, codeInvalidSchema, err.Error())
+	}
+	schema, err = unmarshalSchema(request.Schema)
+	if err != nil {
+		return "", nil, "", newError(http.StatusUnprocessableEntity, codeInvalidSchema, err.Error())
+	}
+	normalizedBytes, err := avro.MarshalSchema(schema)
+	if err != nil {
+		return "", nil, "", newError(http.StatusUnprocessableEntity, codeInvalidSchema, err.Error())
+	}
+	return request.Schema, schema, string(normalizedBytes), nil
+}
+
+// find returns the version of the subject whose schema has the normalized JSON, nil if none
+func (s *Server) find(subject, normalized string) *SubjectVersion {
+	for _, version := range s.state.Subjects[subject] {
+		if s.normalized[version.ID] == normalized {
+			return &version
+		}
+	}
+	return nil
+}
+
+func (s *Server) lookup(subject string, r *http.Request) (interface{}, error) {
+	_, _, normalized, err := readSchema(r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, err = s.subjectVersions(subject)
+	if err != nil {
+		return nil, err
+	}
+	version := s.find(subject, normalized)
+	if version == nil {
+		return nil, newError(http.StatusNotFound, codeSchemaNotFound, "schema not found under subject "+subject)
+	}
+	return &subjectSchemaResponse{subject, version.ID, version.Version, s.state.Schemas[version.ID]}, nil
+}
+
+// register registers the schema as the next version of the subject, unless it is already registered.
+// Schemas with the same normalized JSON share their ID across subjects.
+func (s *Server) register(subject string, r *http.Request) (interface{}, error) {
+	schemaJSON, schema, normalized, err := readSchema(r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if version := s.find(subject, normalized); version != nil {
+		return map[string]int{"id": version.ID}, nil
+	}
+	versions := s.state.Subjects[subject]
+	incompatibilities, err := s.check(subject, schema, versions)
+	if err != nil {
+		return nil, err
+	}
+	if len(incompatibilities) > 0 {
+		return nil, newError(http.StatusConflict, codeIncompatibleSchema, "schema being registered is incompatible with an earlier schema: "+strings.Join(incompatibilities, "; "))
+	}
+	id, known := s.ids[normalized]
+	if !known {
+		id = s.nextID
+	}
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1].Version + 1
+	}
+	err = s.update(func(state *State) {
+		if !known {
+			state.Schemas[id] = schemaJSON
+		}
+		state.Subjects[subject] = append(state.Subjects[subject], SubjectVersion{next, id})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		s.index(id, schema)
+	}
+	return map[string]int{"id": id}, nil
+}
+
+// check returns the incompatibilities of the schema with the versions, according to the compatibility level of the subject
+func (s *Server) check(subject string, schema avro.Schema, versions []SubjectVersion) ([]string, error) {
+	history := make([]avro.Schema, 0, len(versions))
+	for _, version := range versions {
+		history = append(history, s.schemas[version.ID])
+	}
+	incompatibilities, err := avro.CheckCompatibilityLevel(s.level(subject), schema, history)
+	if err != nil {
+		return nil, newError(http.StatusUnprocessableEntity, codeInvalidCompatibilityLevel, err.Error())
+	}
+	messages := make([]string, 0, len(incompatibilities))
+	for _, incompatibility := range incompatibilities {
+		messages = append(messages, incompatibility.String())
+	}
+	return messages, nil
+}
+
+func (s *Server) checkCompatibility(subject, versionParam string, r *http.Request) (interface{}, error) {
+	_, schema, _, err := readSchema(r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, err := s.versionIndex(subject, versionParam)
+	if err != nil {
+		return nil, err
+	}
+	incompatibilities, err := s.check(subject, schema, s.state.Subjects[subject][:i+1])
+	if err != nil {
+		return nil, err
+	}
+	response := struct {
+		IsCompatible bool     `json:"is_compatible"`
+		Messages     []string `json:"messages,omitempty"`
+	}{IsCompatible: len(incompatibilities) == 0}
+	if verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose")); verbose {
+		response.Messages = incompatibilities
+	}
+	return response, nil
+}
+
+func (s *Server) deleteSubject(subject string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subjectVersions, err := s.subjectVersions(subject)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int, 0, len(subjectVersions))
+	for _, version := range subjectVersions {
+		versions = append(versions, version.Version)
+	}
+	err = s.update(func(state *State) {
+		delete(state.Subjects, subject)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (s *Server) deleteVersion(subject, versionParam string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, err := s.versionIndex(subject, versionParam)
+	if err != nil {
+		return nil, err
+	}
+	version := s.state.Subjects[subject][i].Version
+	err = s.update(func(state *State) {
+		versions := append(state.Subjects[subject][:i], state.Subjects[subject][i+1:]...)
+		if len(versions) == 0 {
+			delete(state.Subjects, subject)
+		} else {
+			state.Subjects[subject] = versions
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// level returns the compatibility level of the subject, the default one if the subject has none or is empty
+func (s *Server) level(subject string) avro.CompatibilityLevel {
+	if level, ok := s.state.Configs[subject]; ok && subject != "" {
+		return level
+	}
+	if s.state.Compatibility != "" {
+		return s.state.Compatibility
+	}
+	return DefaultCompatibility
+}
+
+// configResponse - body of the responses describing a compatibility level
+type configResponse struct {
+	CompatibilityLevel avro.CompatibilityLevel `json:"compatibilityLevel"`
+}
+
+// configRequest - body of the requests setting a compatibility level
+type configRequest struct {
+	Compatibility avro.CompatibilityLevel `json:"compatibility"`
+}
+
+func (s *Server) config(subject string) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return configResponse{s.level(subject)}
+}
+
+func (s *Server) setConfig(subject string, r *http.Request) (interface{}, error) {
+	var request configRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err == nil && request.Compatibility == "" {
+		err = ErrInvalidCompatibilityLevel
+	}
+	if err == nil {
+		_, err = avro.CheckCompatibilityLevel(request.Compatibility, nil, nil)
+	}
+	if err != nil {
+		return nil, newError(http.StatusUnprocessableEntity, codeInvalidCompatibilityLevel, err.Error())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = s.update(func(state *State) {
+		if subject == "" {
+			state.Compatibility = request.Compatibility
+		} else {
+			state.Configs[subject] = request.Compatibility
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (s *Server) deleteConfig(subject string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	level, ok := s.state.Configs[subject]
+	if !ok {
+		return nil, newError(http.StatusNotFound, codeSubjectNotFound, "subject "+subject+" has no compatibility level")
+	}
+	err := s.update(func(state *State) {
+		delete(state.Configs, subject)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return configResponse{level}, nil
+}
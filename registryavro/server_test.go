@@ -0,0 +1,376 @@
+package registryavro
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/khezen/avro"
+)
+
+func testServerClient(t *testing.T, server *Server) (*Client, func()) {
+	httpServer := httptest.NewServer(server)
+	return NewClient(httpServer.URL, nil), httpServer.Close
+}
+
+func TestServer(t *testing.T) {
+	server, err := NewServer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, closeServer := testServerClient(t, server)
+	defer closeServer()
+	v1, v2, v3 := mustUnmarshalSchema(t, userSchemaV1), mustUnmarshalSchema(t, userSchemaV2), mustUnmarshalSchema(t, userSchemaV3)
+	// same normalized JSON as v1
+	v1Reordered := mustUnmarshalSchema(t, `{"namespace":"test", "name":"User", "type":"record", "fields":[{"type":"string", "name":"name"}]}`)
+	cases := []struct {
+		subject     string
+		schema      avro.Schema
+		expectedID  int
+		expectedErr error
+	}{
+		{"users-value", v1, 1, nil},
+		{"users-value", v1Reordered, 1, nil},
+		{"users-value", v2, 2, nil},
+		{"users-value", v3, 0, avro.ErrIncompatibleSchema},
+		{"users-key", v1, 1, nil},
+		{"users-key", v2, 2, nil},
+	}
+	for i, c := range cases {
+		id, err := client.Register(c.subject, c.schema)
+		if !errors.Is(err, c.expectedErr) || id != c.expectedID {
+			t.Errorf("case %d - expected %d, %v, got %d, %v", i, c.expectedID, c.expectedErr, id, err)
+		}
+	}
+	subjects, err := client.Subjects()
+	if err != nil || !reflect.DeepEqual(subjects, []string{"users-key", "users-value"}) {
+		t.Errorf("expected [users-key users-value], got %v, %v", subjects, err)
+	}
+	versionCases := []struct {
+		subject         string
+		version         int
+		expectedID      int
+		expectedVersion int
+		expectedErr     error
+	}{
+		{"users-value", 1, 1, 1, nil},
+		{"users-value", LatestVersion, 2, 2, nil},
+		{"users-value", 3, 0, 0, ErrVersionNotFound},
+		{"users-value", 0, 0, 0, ErrInvalidVersion},
+		{"orders-value", 1, 0, 0, ErrSubjectNotFound},
+	}
+	for i, c := range versionCases {
+		found, err := client.SchemaBySubject(c.subject, c.version)
+		if !errors.Is(err, c.expectedErr) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if err == nil && (found.ID != c.expectedID || found.Version != c.expectedVersion) {
+			t.Errorf("case %d - expected %d/%d, got %v", i, c.expectedID, c.expectedVersion, found)
+		}
+	}
+	found, err := client.Lookup("users-value", v1Reordered)
+	if err != nil || found.ID != 1 || found.Version != 1 {
+		t.Errorf("expected version 1, got %v, %v", found, err)
+	}
+	_, err = client.Lookup("users-value", v3)
+	if !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("expected %v, got %v", ErrSchemaNotFound, err)
+	}
+	schema, err := NewClient(client.url, nil).SchemaByID(2)
+	if err != nil || !reflect.DeepEqual(schema, v2) {
+		t.Errorf("expected %v, got %v, %v", v2, schema, err)
+	}
+	_, err = client.SchemaByID(3)
+	if !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("expected %v, got %v", ErrSchemaNotFound, err)
+	}
+	compatibilityCases := []struct {
+		subject     string
+		version     int
+		schema      avro.Schema
+		level       avro.CompatibilityLevel
+		expected    bool
+		expectedErr error
+	}{
+		{"users-value", LatestVersion, v3, "", false, nil},
+		{"users-value", LatestVersion, v3, avro.CompatibilityForward, true, nil},
+		{"users-value", 1, v3, avro.CompatibilityForwardTransitive, true, nil},
+		{"users-value", LatestVersion, v3, avro.CompatibilityFull, false, nil},
+		{"users-value", LatestVersion, v3, avro.CompatibilityNone, true, nil},
+		{"users-value", 3, v3, "", false, ErrVersionNotFound},
+		{"orders-value", LatestVersion, v3, "", false, ErrSubjectNotFound},
+	}
+	for i, c := range compatibilityCases {
+		if c.level != "" {
+			err = client.SetCompatibility(c.subject, c.level)
+			if err != nil {
+				t.Errorf("case %d - %v", i, err)
+				continue
+			}
+		}
+		compatible, err := client.CheckCompatibility(c.subject, c.version, c.schema)
+		if !errors.Is(err, c.expectedErr) || compatible != c.expected {
+			t.Errorf("case %d - expected %v, %v, got %v, %v", i, c.expected, c.expectedErr, compatible, err)
+		}
+	}
+	levelCases := []struct {
+		subject  string
+		expected avro.CompatibilityLevel
+	}{
+		{"", DefaultCompatibility},
+		{"users-value", avro.CompatibilityNone},
+		{"users-key", DefaultCompatibility},
+	}
+	for i, c := range levelCases {
+		level, err := client.Compatibility(c.subject)
+		if err != nil || level != c.expected {
+			t.Errorf("case %d - expected %v, got %v, %v", i, c.expected, level, err)
+		}
+	}
+	err = client.SetCompatibility("", "SOMETIMES")
+	if !errors.Is(err, ErrInvalidCompatibilityLevel) {
+		t.Errorf("expected %v, got %v", ErrInvalidCompatibilityLevel, err)
+	}
+	err = client.SetCompatibility("", avro.CompatibilityFull)
+	if level, _ := client.Compatibility("users-key"); err != nil || level != avro.CompatibilityFull {
+		t.Errorf("expected %v, got %v, %v", avro.CompatibilityFull, level, err)
+	}
+	// NONE is set on users-value
+	id, err := client.Register("users-value", v3)
+	if err != nil || id != 3 {
+		t.Errorf("expected 3, got %d, %v", id, err)
+	}
+	version, err := client.DeleteVersion("users-value", LatestVersion)
+	if err != nil || version != 3 {
+		t.Errorf("expected 3, got %d, %v", version, err)
+	}
+	// the deleted version is registered again, with the next version
+	id, err = client.Register("users-value", v3)
+	if found, _ := client.SchemaBySubject("users-value", LatestVersion); err != nil || id != 3 || found.Version != 3 {
+		t.Errorf("expected 3, got %d, %v, %v", id, found, err)
+	}
+	versions, err := client.DeleteSubject("users-key")
+	if err != nil || !reflect.DeepEqual(versions, []int{1, 2}) {
+		t.Errorf("expected [1 2], got %v, %v", versions, err)
+	}
+	_, err = client.Versions("users-key")
+	if !errors.Is(err, ErrSubjectNotFound) {
+		t.Errorf("expected %v, got %v", ErrSubjectNotFound, err)
+	}
+	_, err = client.DeleteSubject("users-key")
+	if !errors.Is(err, ErrSubjectNotFound) {
+		t.Errorf("expected %v, got %v", ErrSubjectNotFound, err)
+	}
+	_, err = client.DeleteVersion("users-value", 2)
+	if err == nil {
+		_, err = client.DeleteVersion("users-value", 1)
+	}
+	if err == nil {
+		_, err = client.DeleteVersion("users-value", LatestVersion)
+	}
+	if subjects, _ := client.Subjects(); err != nil || len(subjects) != 0 {
+		t.Errorf("expected no subject, got %v, %v", subjects, err)
+	}
+}
+
+func TestServerDeduplication(t *testing.T) {
+	server, err := NewServer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, closeServer := testServerClient(t, server)
+	defer closeServer()
+	// same canonical form, the default, doc and aliases being stripped from it
+	withoutDefault := `{"type":"record","name":"R","fields":[{"name":"x","type":"int"}]}`
+	withDefault := `{"type":"record","name":"R","fields":[{"name":"x","type":"int","default":7}]}`
+	withDoc := `{"type":"record","name":"R","doc":"r","fields":[{"name":"x","type":"int","default":7}]}`
+	cases := []struct {
+		subject    string
+		schema     string
+		expectedID int
+	}{
+		{"s1", withoutDefault, 1},
+		{"s2", withDefault, 2},
+		{"s1", withDefault, 2},
+		{"s3", withDoc, 3},
+		{"s3", withoutDefault, 1},
+	}
+	for i, c := range cases {
+		id, err := client.Register(c.subject, mustUnmarshalSchema(t, c.schema))
+		if err != nil || id != c.expectedID {
+			t.Errorf("case %d - expected %d, got %d, %v", i, c.expectedID, id, err)
+			continue
+		}
+		found, err := NewClient(client.url, nil).SchemaBySubject(c.subject, LatestVersion)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(found.Schema, mustUnmarshalSchema(t, c.schema)) {
+			t.Errorf("case %d - expected %s, got %v", i, c.schema, found.Schema)
+		}
+	}
+}
+
+func TestServerHTTP(t *testing.T) {
+	server, err := NewServer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	cases := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{http.MethodGet, "/subjects", "", http.StatusOK, `[]`},
+		{http.MethodGet, "/schemas/types", "", http.StatusOK, `["AVRO"]`},
+		{http.MethodPost, "/subjects/a%2Fb/versions", `{"schema":"\"string\""}`, http.StatusOK, `{"id":1}`},
+		{http.MethodGet, "/subjects", "", http.StatusOK, `["a/b"]`},
+		{http.MethodGet, "/subjects/a%2Fb/versions/1/schema", "", http.StatusOK, `"string"`},
+		{http.MethodGet, "/subjects/a%2Fb/versions/latest", "", http.StatusOK, `{"subject":"a/b","id":1,"version":1,"schema":"\"string\""}`},
+		{http.MethodGet, "/subjects/a%2Fb/versions/one", "", http.StatusUnprocessableEntity, `{"error_code":42202,"message":"invalid version one"}`},
+		{http.MethodPost, "/subjects/a%2Fb/versions", `{"schema":"\"strin\""}`, http.StatusUnprocessableEntity, `{"error_code":42201,"message":"ErrUnsupportedType - AVRO doesn't support the given type: unknown type, got \"strin\""}`},
+		{http.MethodPost, "/subjects/a%2Fb/versions", `{`, http.StatusUnprocessableEntity, `{"error_code":42201,"message":"unexpected EOF"}`},
+		{http.MethodPost, "/compatibility/subjects/a%2Fb/versions/latest?verbose=true", `{"schema":"\"int\""}`, http.StatusOK, `{"is_compatible":false,"messages":["TYPE_MISMATCH: reader type int is not compatible with writer type string"]}`},
+		{http.MethodPut, "/config/a%2Fb", `{"compatibility":"FORWARD"}`, http.StatusOK, `{"compatibility":"FORWARD"}`},
+		{http.MethodPut, "/config", `{}`, http.StatusUnprocessableEntity, `{"error_code":42203,"message":"ErrInvalidCompatibilityLevel"}`},
+		{http.MethodDelete, "/config/a%2Fb", "", http.StatusOK, `{"compatibilityLevel":"FORWARD"}`},
+		{http.MethodDelete, "/config/a%2Fb", "", http.StatusNotFound, `{"error_code":40401,"message":"subject a/b has no compatibility level"}`},
+		{http.MethodPut, "/subjects", "", http.StatusMethodNotAllowed, `{"error_code":405,"message":"Method Not Allowed"}`},
+		{http.MethodGet, "/subjects/a/b/c/d/e", "", http.StatusNotFound, `{"error_code":404,"message":"Not Found"}`},
+	}
+	for i, c := range cases {
+		req, err := http.NewRequest(c.method, httpServer.URL+c.path, strings.NewReader(c.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != c.expectedStatus || strings.TrimSpace(string(body)) != c.expectedBody {
+			t.Errorf("case %d - expected %d %s, got %d %s, %v", i, c.expectedStatus, c.expectedBody, resp.StatusCode, body, err)
+		}
+	}
+}
+
+func TestServerStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registryavro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cases := []func() Storage{
+		func() Storage { return NewFileStorage(filepath.Join(dir, "registry.json")) },
+		func() Storage { return NewDirStorage(filepath.Join(dir, "registry")) },
+	}
+	v1, v2 := mustUnmarshalSchema(t, userSchemaV1), mustUnmarshalSchema(t, userSchemaV2)
+	for i, storage := range cases {
+		server, err := NewServer(storage())
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		client, closeServer := testServerClient(t, server)
+		_, err = client.Register("users-value", v1)
+		if err == nil {
+			_, err = client.Register("users-value", v2)
+		}
+		if err == nil {
+			_, err = client.Register("users-key", v1)
+		}
+		if err == nil {
+			err = client.SetCompatibility("users-value", avro.CompatibilityFull)
+		}
+		if err == nil {
+			_, err = client.DeleteSubject("users-key")
+		}
+		closeServer()
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		// a new server is loaded from the storage
+		server, err = NewServer(storage())
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		client, closeServer = testServerClient(t, server)
+		subjects, err := client.Subjects()
+		if err != nil || !reflect.DeepEqual(subjects, []string{"users-value"}) {
+			t.Errorf("case %d - expected [users-value], got %v, %v", i, subjects, err)
+		}
+		found, err := client.SchemaBySubject("users-value", LatestVersion)
+		if err != nil || found.ID != 2 || found.Version != 2 || !reflect.DeepEqual(found.Schema, v2) {
+			t.Errorf("case %d - expected version 2, got %v, %v", i, found, err)
+		}
+		level, err := client.Compatibility("users-value")
+		if err != nil || level != avro.CompatibilityFull {
+			t.Errorf("case %d - expected %v, got %v, %v", i, avro.CompatibilityFull, level, err)
+		}
+		id, err := client.Register("orders-value", v1)
+		if err != nil || id != 1 {
+			t.Errorf("case %d - expected 1, got %d, %v", i, id, err)
+		}
+		closeServer()
+	}
+}
+
+// failingStorage - storage failing to save
+type failingStorage struct{}
+
+var errStorage = errors.New("errStorage")
+
+func (failingStorage) Load() (*State, error) {
+	return nil, nil
+}
+
+func (failingStorage) Save(*State) error {
+	return errStorage
+}
+
+func TestServerStorageErrors(t *testing.T) {
+	server, err := NewServer(failingStorage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, closeServer := testServerClient(t, server)
+	defer closeServer()
+	_, err = client.Register("users-value", mustUnmarshalSchema(t, userSchemaV1))
+	var registryErr *Error
+	if !errors.As(err, &registryErr) || registryErr.StatusCode != http.StatusInternalServerError || registryErr.Message != errStorage.Error() {
+		t.Errorf("expected %v, got %v", errStorage, err)
+	}
+	if subjects, _ := client.Subjects(); len(subjects) != 0 {
+		t.Errorf("expected no subject, got %v", subjects)
+	}
+	dir, err := ioutil.TempDir("", "registryavro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "registry.json")
+	err = ioutil.WriteFile(path, []byte(`{"schemas":{"1":"\"strin\""}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewServer(NewFileStorage(path))
+	if !errors.Is(err, avro.ErrUnsupportedType) {
+		t.Errorf("expected %v, got %v", avro.ErrUnsupportedType, err)
+	}
+}
@@ -0,0 +1,253 @@
+package registryavro
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/khezen/avro"
+)
+
+// State - content of a registry, as persisted by its storage
+type State struct {
+	// Schemas - registered schemas by ID, as they were first submitted
+	Schemas map[int]string `json:"schemas"`
+	// Subjects - registered versions of the subjects, in ascending order
+	Subjects map[string][]SubjectVersion `json:"subjects"`
+	// Compatibility - default compatibility level, BACKWARD if empty
+	Compatibility avro.CompatibilityLevel `json:"compatibility,omitempty"`
+	// Configs - compatibility levels of the subjects overriding the default one
+	Configs map[string]avro.CompatibilityLevel `json:"configs,omitempty"`
+}
+
+// SubjectVersion - version of a subject and the ID of its schema
+type SubjectVersion struct {
+	Version int `json:"version"`
+	ID      int `json:"id"`
+}
+
+// newState - empty state
+func newState() *State {
+	return &State{
+		Schemas:  make(map[int]string),
+		Subjects: make(map[string][]SubjectVersion),
+		Configs:  make(map[string]avro.CompatibilityLevel),
+	}
+}
+
+// clone copies the state so it can be changed, schemas being immutable
+func (s *State) clone() *State {
+	state := &State{
+		Schemas:       make(map[int]string, len(s.Schemas)),
+		Subjects:      make(map[string][]SubjectVersion, len(s.Subjects)),
+		Compatibility: s.Compatibility,
+		Configs:       make(map[string]avro.CompatibilityLevel, len(s.Configs)),
+	}
+	for id, schema := range s.Schemas {
+		state.Schemas[id] = schema
+	}
+	for subject, versions := range s.Subjects {
+		state.Subjects[subject] = append([]SubjectVersion(nil), versions...)
+	}
+	for subject, level := range s.Configs {
+		state.Configs[subject] = level
+	}
+	return state
+}
+
+// Storage - persists the state of a registry
+type Storage interface {
+	// Load - returns the persisted state, nil if there is none yet
+	Load() (*State, error)
+	// Save - persists the state, after each change
+	Save(state *State) error
+}
+
+// fileStorage - persists the state in a single JSON file
+type fileStorage struct {
+	path string
+}
+
+// NewFileStorage - storage keeping the state in a single JSON file, replaced atomically on each change
+func NewFileStorage(path string) Storage {
+	return &fileStorage{path}
+}
+
+func (fs *fileStorage) Load() (*State, error) {
+	stateBytes, err := ioutil.ReadFile(fs.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := newState()
+	err = json.Unmarshal(stateBytes, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (fs *fileStorage) Save(state *State) error {
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(fs.path, stateBytes)
+}
+
+// dirStorage - persists the state in a directory:
+// schemas/<id>.avsc, subjects/<escaped subject>.json listing their versions and config.json for compatibility levels
+type dirStorage struct {
+	dir string
+}
+
+// dirConfig - content of config.json
+type dirConfig struct {
+	Compatibility avro.CompatibilityLevel            `json:"compatibility,omitempty"`
+	Configs       map[string]avro.CompatibilityLevel `json:"configs,omitempty"`
+}
+
+const (
+	dirSchemas    = "schemas"
+	dirSubjects   = "subjects"
+	dirConfigFile = "config.json"
+	schemaExt     = ".avsc"
+	subjectExt    = ".json"
+)
+
+// NewDirStorage - storage keeping the state in a directory, one file per schema and per subject,
+// so that it can be inspected and versioned.
+func NewDirStorage(dir string) Storage {
+	return &dirStorage{dir}
+}
+
+func (ds *dirStorage) Load() (*State, error) {
+	configBytes, err := ioutil.ReadFile(filepath.Join(ds.dir, dirConfigFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := newState()
+	config := dirConfig{Configs: state.Configs}
+	err = json.Unmarshal(configBytes, &config)
+	if err != nil {
+		return nil, err
+	}
+	state.Compatibility, state.Configs = config.Compatibility, config.Configs
+	schemaFiles, err := ioutil.ReadDir(filepath.Join(ds.dir, dirSchemas))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, file := range schemaFiles {
+		id, err := strconv.Atoi(strings.TrimSuffix(file.Name(), schemaExt))
+		if err != nil || !strings.HasSuffix(file.Name(), schemaExt) {
+			continue
+		}
+		schemaBytes, err := ioutil.ReadFile(filepath.Join(ds.dir, dirSchemas, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		state.Schemas[id] = string(schemaBytes)
+	}
+	subjectFiles, err := ioutil.ReadDir(filepath.Join(ds.dir, dirSubjects))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, file := range subjectFiles {
+		subject, err := url.PathUnescape(strings.TrimSuffix(file.Name(), subjectExt))
+		if err != nil || !strings.HasSuffix(file.Name(), subjectExt) {
+			continue
+		}
+		versionsBytes, err := ioutil.ReadFile(filepath.Join(ds.dir, dirSubjects, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var versions []SubjectVersion
+		err = json.Unmarshal(versionsBytes, &versions)
+		if err != nil {
+			return nil, err
+		}
+		state.Subjects[subject] = versions
+	}
+	return state, nil
+}
+
+// Save writes the new schemas, rewrites the subjects and removes the deleted ones, then writes the configuration.
+// Schemas are immutable, so existing schema files are left untouched.
+func (ds *dirStorage) Save(state *State) error {
+	for _, dir := range []string{dirSchemas, dirSubjects} {
+		err := os.MkdirAll(filepath.Join(ds.dir, dir), 0755)
+		if err != nil {
+			return err
+		}
+	}
+	for id, schema := range state.Schemas {
+		path := filepath.Join(ds.dir, dirSchemas, strconv.Itoa(id)+schemaExt)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		err := writeFileAtomic(path, []byte(schema))
+		if err != nil {
+			return err
+		}
+	}
+	for subject, versions := range state.Subjects {
+		versionsBytes, err := json.Marshal(versions)
+		if err != nil {
+			return err
+		}
+		err = writeFileAtomic(filepath.Join(ds.dir, dirSubjects, url.PathEscape(subject)+subjectExt), versionsBytes)
+		if err != nil {
+			return err
+		}
+	}
+	subjectFiles, err := ioutil.ReadDir(filepath.Join(ds.dir, dirSubjects))
+	if err != nil {
+		return err
+	}
+	for _, file := range subjectFiles {
+		subject, err := url.PathUnescape(strings.TrimSuffix(file.Name(), subjectExt))
+		if err != nil || !strings.HasSuffix(file.Name(), subjectExt) {
+			continue
+		}
+		if _, ok := state.Subjects[subject]; !ok {
+			err = os.Remove(filepath.Join(ds.dir, dirSubjects, file.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	configBytes, err := json.Marshal(dirConfig{state.Compatibility, state.Configs})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(ds.dir, dirConfigFile), configBytes)
+}
+
+// writeFileAtomic writes the file through a temporary file renamed once written,
+// so that readers never see it partially written
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
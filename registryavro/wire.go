@@ -0,0 +1,104 @@
+package registryavro
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/khezen/avro"
+)
+
+// magicByte - first byte of the wire format, followed by the big-endian 4 bytes ID of the schema and the binary data
+const magicByte = 0
+
+const headerSize = 5
+
+// AppendHeader - appends the header of the wire format, designating the schema with the ID, to buf
+func AppendHeader(buf []byte, id int) []byte {
+	header := [headerSize]byte{magicByte}
+	binary.BigEndian.PutUint32(header[1:], uint32(id))
+	return append(buf, header[:]...)
+}
+
+// SchemaID - returns the ID of the schema of the data encoded in the wire format, and the binary data
+func SchemaID(buf []byte) (id int, payload []byte, err error) {
+	if len(buf) < headerSize || buf[0] != magicByte {
+		return 0, nil, ErrInvalidWireFormat
+	}
+	return int(binary.BigEndian.Uint32(buf[1:headerSize])), buf[headerSize:], nil
+}
+
+// Serializer - encodes data in the wire format, the schema being registered under the subject on first use
+type Serializer struct {
+	client  *Client
+	subject string
+	schema  avro.Schema
+	mu      sync.Mutex
+	id      int
+}
+
+// NewSerializer - serializer of the data of the schema, registered under the subject
+func NewSerializer(client *Client, subject string, schema avro.Schema) *Serializer {
+	return &Serializer{
+		client:  client,
+		subject: subject,
+		schema:  schema,
+		id:      -1,
+	}
+}
+
+// Serialize - encodes the datum in the wire format
+func (s *Serializer) Serialize(datum interface{}) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.id < 0 {
+		id, err := s.client.Register(s.subject, s.schema)
+		if err != nil {
+			return nil, err
+		}
+		s.id = id
+	}
+	return avro.EncodeBinary(AppendHeader(nil, s.id), s.schema, datum)
+}
+
+// Deserializer - decodes data in the wire format, fetching their schema from the registry
+type Deserializer struct {
+	client *Client
+	reader avro.Schema
+}
+
+// NewDeserializer - deserializer of data in the wire format.
+// If the reader schema is not nil, data are resolved to it, see avro.ResolveDatum.
+func NewDeserializer(client *Client, reader avro.Schema) *Deserializer {
+	return &Deserializer{
+		client: client,
+		reader: reader,
+	}
+}
+
+// Deserialize - decodes the datum encoded in the wire format
+func (d *Deserializer) Deserialize(buf []byte) (interface{}, error) {
+	id, payload, err := SchemaID(buf)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := d.client.SchemaByID(id)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		datum interface{}
+		rest  []byte
+	)
+	if d.reader == nil {
+		datum, rest, err = avro.DecodeBinary(payload, writer)
+	} else {
+		datum, rest, err = avro.DecodeBinaryResolved(payload, writer, d.reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, avro.ErrInvalidEncoding
+	}
+	return datum, nil
+}
@@ -0,0 +1,263 @@
+package avro
+
+// DecodeBinaryResolved - decodes a datum written with the writer schema from buf, returns it shaped like the reader schema
+// and the remaining bytes. See ResolveDatum for the resolution rules.
+func DecodeBinaryResolved(buf []byte, writer, reader Schema) (datum interface{}, rest []byte, err error) {
+	datum, rest, err = DecodeBinary(buf, writer)
+	if err != nil {
+		return nil, nil, err
+	}
+	datum, err = ResolveDatum(writer, reader, datum)
+	if err != nil {
+		return nil, nil, err
+	}
+	return datum, rest, nil
+}
+
+// ResolveDatum - translates a datum of the writer schema into a datum of the reader schema, following the spec:
+//   - record fields are matched by name, then by the aliases of the reader fields;
+//     writer fields unknown to the reader are skipped and reader fields unknown to the writer take their default value
+//   - int, long and float are promoted to long, float or double, and string and bytes to each other
+//   - enum symbols unknown to the reader are replaced by the reader default
+//   - the reader union branch is the first one of the same type as the writer datum, or the first one it can be promoted to
+//   - logical types which don't match are ignored, the datum being translated through the underlying type
+//   - decimals match if their precisions and scales match, the unscaled value being meaningless otherwise
+//
+// ErrIncompatibleSchema is returned if the datum cannot be read with the reader schema.
+func ResolveDatum(writer, reader Schema, datum interface{}) (interface{}, error) {
+	return resolveDatum(writer, reader, datum, "", "")
+}
+
+// resolveDatum - writerNamespace and readerNamespace are the enclosing namespaces of the writer and reader schemas
+func resolveDatum(writer, reader Schema, datum interface{}, writerNamespace, readerNamespace string) (interface{}, error) {
+	if reference, ok := writer.(*ReferenceSchema); ok {
+		return resolveDatum(reference.Schema, reader, datum, namespaceOf(fullName(reference.Name, "", writerNamespace)), readerNamespace)
+	}
+	if reference, ok := reader.(*ReferenceSchema); ok {
+		return resolveDatum(writer, reference.Schema, datum, writerNamespace, namespaceOf(fullName(reference.Name, "", readerNamespace)))
+	}
+	if writerUnion, ok := writer.(UnionSchema); ok {
+		return resolveWriterUnion(writerUnion, reader, datum, writerNamespace, readerNamespace)
+	}
+	if readerUnion, ok := reader.(UnionSchema); ok {
+		i := resolveUnionBranch(readerUnion, writer, readerNamespace)
+		if i < 0 {
+			return nil, ErrIncompatibleSchema
+		}
+		value, err := resolveDatum(writer, readerUnion[i], datum, writerNamespace, readerNamespace)
+		if err != nil || value == nil && underlyingSchema(readerUnion[i]) == TypeNull {
+			return nil, err
+		}
+		return map[string]interface{}{branchName(readerUnion[i], readerNamespace): value}, nil
+	}
+	if !matchSchemas(underlyingSchema(reader), underlyingSchema(writer)) || !matchDecimals(reader, writer) {
+		return nil, ErrIncompatibleSchema
+	}
+	switch r := reader.(type) {
+	case *ArraySchema:
+		return resolveArray(writer.(*ArraySchema), r, datum, writerNamespace, readerNamespace)
+	case *MapSchema:
+		return resolveMap(writer.(*MapSchema), r, datum, writerNamespace, readerNamespace)
+	case *RecordSchema:
+		return resolveRecord(writer.(*RecordSchema), r, datum, writerNamespace, readerNamespace)
+	case *EnumSchema:
+		symbol, ok := datum.(string)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		if contains(r.Symbols, symbol) {
+			return symbol, nil
+		}
+		if r.Default == "" {
+			return nil, ErrIncompatibleSchema
+		}
+		return r.Default, nil
+	case *FixedSchema:
+		b, ok := fixedDatum(writer.(*FixedSchema), datum)
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		return nativeFixedDatum(r, b), nil
+	default:
+		return resolvePrimitive(writer, reader, datum)
+	}
+}
+
+func resolveWriterUnion(union UnionSchema, reader Schema, datum interface{}, writerNamespace, readerNamespace string) (interface{}, error) {
+	if datum == nil {
+		for _, branch := range union {
+			if underlyingSchema(branch) == TypeNull {
+				return resolveDatum(branch, reader, nil, writerNamespace, readerNamespace)
+			}
+		}
+		return nil, ErrInvalidDatum
+	}
+	i, value, ok := unionBranch(union, datum, writerNamespace)
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	return resolveDatum(union[i], reader, value, writerNamespace, readerNamespace)
+}
+
+// resolveUnionBranch returns the index of the reader branch the writer schema resolves to, -1 if none.
+// Branches with the same name as the writer, logical type included, are preferred over the ones of the same kind,
+// which are preferred over promotions.
+func resolveUnionBranch(union UnionSchema, writer Schema, readerNamespace string) int {
+	if _, ok := writer.(NamedSchema); !ok {
+		for i, branch := range union {
+			if branchName(referencedSchema(branch), "") == branchName(writer, "") {
+				return i
+			}
+		}
+	}
+	for i, branch := range union {
+		branch = underlyingSchema(branch)
+		if sameKind(branch, writer) && matchSchemas(branch, underlyingSchema(writer)) {
+			return i
+		}
+	}
+	for i, branch := range union {
+		if matchSchemas(underlyingSchema(branch), underlyingSchema(writer)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func resolveArray(writer, reader *ArraySchema, datum interface{}, writerNamespace, readerNamespace string) (interface{}, error) {
+	items, ok := arrayDatum(datum)
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	resolved := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		value, err := resolveDatum(writer.Items, reader.Items, item, writerNamespace, readerNamespace)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, value)
+	}
+	return resolved, nil
+}
+
+func resolveMap(writer, reader *MapSchema, datum interface{}, writerNamespace, readerNamespace string) (interface{}, error) {
+	values, ok := mapDatum(datum)
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	resolved := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		resolvedValue, err := resolveDatum(writer.Value, reader.Value, value, writerNamespace, readerNamespace)
+		if err != nil {
+			return nil, err
+		}
+		resolved[key] = resolvedValue
+	}
+	return resolved, nil
+}
+
+func resolveRecord(writer, reader *RecordSchema, datum interface{}, writerNamespace, readerNamespace string) (interface{}, error) {
+	values, ok := mapDatum(datum)
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	writerNamespace = namespaceOf(writer.FullName(writerNamespace))
+	readerNamespace = namespaceOf(reader.FullName(readerNamespace))
+	record := make(map[string]interface{}, len(reader.Fields))
+	for i := range reader.Fields {
+		readerField := &reader.Fields[i]
+		writerField := lookupWriterField(readerField, writer)
+		if writerField == nil {
+			if readerField.Default == nil {
+				return nil, ErrIncompatibleSchema
+			}
+			value, err := defaultDatum(readerField.Type, *readerField.Default, readerNamespace)
+			if err != nil {
+				return nil, err
+			}
+			record[readerField.Name] = value
+			continue
+		}
+		value, ok := values[writerField.Name]
+		if !ok {
+			return nil, ErrInvalidDatum
+		}
+		value, err := resolveDatum(writerField.Type, readerField.Type, value, writerNamespace, readerNamespace)
+		if err != nil {
+			return nil, err
+		}
+		record[readerField.Name] = value
+	}
+	return record, nil
+}
+
+// resolvePrimitive translates the datum to the underlying type of the writer,
+// promotes it to the underlying type of the reader and translates it to the logical type of the reader.
+// The datum is returned as is if both schemas are the same type.
+func resolvePrimitive(writer, reader Schema, datum interface{}) (interface{}, error) {
+	if branchName(writer, "") == branchName(reader, "") {
+		return datum, nil
+	}
+	if derived, ok := writer.(*DerivedPrimitiveSchema); ok {
+		datum = primitiveDatum(derived, datum)
+	}
+	datum, ok := promoteDatum(datum, underlyingSchema(reader).(Type))
+	if !ok {
+		return nil, ErrInvalidDatum
+	}
+	if derived, ok := reader.(*DerivedPrimitiveSchema); ok {
+		return nativeDatum(derived, datum), nil
+	}
+	return datum, nil
+}
+
+// matchDecimals - decimals match if their precisions and scales match.
+// A decimal paired with a schema which isn't a decimal is resolved through its underlying type.
+func matchDecimals(reader, writer Schema) bool {
+	readerPrecision, readerScale, readerIsDecimal := decimalParameters(reader)
+	writerPrecision, writerScale, writerIsDecimal := decimalParameters(writer)
+	if !readerIsDecimal || !writerIsDecimal {
+		return true
+	}
+	return readerPrecision == writerPrecision && readerScale == writerScale
+}
+
+// decimalParameters returns the precision and scale of decimals, written as bytes or fixed
+func decimalParameters(schema Schema) (precision, scale int, ok bool) {
+	switch t := referencedSchema(schema).(type) {
+	case *DerivedPrimitiveSchema:
+		return intOrZero(t.Precision), intOrZero(t.Scale), t.LogicalType == LogicalTypeDecimal
+	case *FixedSchema:
+		return intOrZero(t.Precision), intOrZero(t.Scale), t.LogicalType == LogicalTypeDecimal
+	default:
+		return 0, 0, false
+	}
+}
+
+// promoteDatum translates the datum to the native representation of the given primitive type
+func promoteDatum(datum interface{}, typeName Type) (interface{}, bool) {
+	switch typeName {
+	case TypeInt32:
+		n, ok := int64Datum(datum)
+		return int32(n), ok
+	case TypeInt64:
+		return int64Datum(datum)
+	case TypeFloat32:
+		f, ok := float64Datum(datum)
+		return float32(f), ok
+	case TypeFloat64:
+		return float64Datum(datum)
+	case TypeString:
+		if b, ok := datum.([]byte); ok {
+			return string(b), true
+		}
+		return stringDatum(datum)
+	case TypeBytes:
+		if s, ok := datum.(string); ok {
+			return []byte(s), true
+		}
+		return bytesDatum(datum)
+	default:
+		return datum, true
+	}
+}
@@ -0,0 +1,250 @@
+package avro
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeBinaryResolved(t *testing.T) {
+	cases := []struct {
+		writer      string
+		reader      string
+		datum       interface{}
+		expected    interface{}
+		expectedErr error
+	}{
+		{`"int"`, `"long"`, int32(42), int64(42), nil},
+		{`"int"`, `"double"`, int32(42), float64(42), nil},
+		{`"long"`, `"float"`, int64(-3), float32(-3), nil},
+		{`"float"`, `"double"`, float32(1.5), float64(1.5), nil},
+		{`"string"`, `"bytes"`, "avro", []byte("avro"), nil},
+		{`"bytes"`, `"string"`, []byte("avro"), "avro", nil},
+		{`"long"`, `"int"`, int64(42), nil, ErrIncompatibleSchema},
+		{`"int"`, `["null","string","long"]`, int32(42), map[string]interface{}{"long": int64(42)}, nil},
+		{`"int"`, `["null","long","int"]`, int32(42), map[string]interface{}{"int": int32(42)}, nil},
+		{`["null","string"]`, `"string"`, map[string]interface{}{"string": "avro"}, "avro", nil},
+		{`["null","string"]`, `"string"`, nil, nil, ErrIncompatibleSchema},
+		{`["null","int"]`, `["long","null"]`, nil, nil, nil},
+		{`"boolean"`, `["null","string"]`, true, nil, ErrIncompatibleSchema},
+		{
+			`{"type":"long","logicalType":"timestamp-millis"}`,
+			`["long",{"type":"long","logicalType":"timestamp-millis"}]`,
+			time.Unix(1, 0).UTC(),
+			map[string]interface{}{"long.timestamp-millis": time.Unix(1, 0).UTC()},
+			nil,
+		},
+		{
+			`{"type":"long","logicalType":"timestamp-millis"}`,
+			`{"type":"long","logicalType":"timestamp-micros"}`,
+			time.Unix(1, 0).UTC(),
+			time.Unix(0, 1000000).UTC(),
+			nil,
+		},
+		{
+			`{"type":"int","logicalType":"date"}`,
+			`"long"`,
+			time.Unix(2*24*3600, 0).UTC(),
+			int64(2),
+			nil,
+		},
+		{
+			`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`,
+			`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`,
+			big.NewRat(314, 100),
+			big.NewRat(314, 100),
+			nil,
+		},
+		{
+			`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`,
+			`{"type":"bytes","logicalType":"decimal","precision":5,"scale":3}`,
+			big.NewRat(3, 2),
+			nil,
+			ErrIncompatibleSchema,
+		},
+		{
+			`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`,
+			`{"type":"bytes","logicalType":"decimal","precision":5,"scale":2}`,
+			big.NewRat(3, 2),
+			nil,
+			ErrIncompatibleSchema,
+		},
+		{
+			`{"type":"fixed","name":"amount","size":4,"logicalType":"decimal","precision":4,"scale":2}`,
+			`{"type":"fixed","name":"amount","size":4,"logicalType":"decimal","precision":5,"scale":3}`,
+			big.NewRat(3, 2),
+			nil,
+			ErrIncompatibleSchema,
+		},
+		{
+			`{"type":"fixed","name":"amount","size":4,"logicalType":"decimal","precision":4,"scale":2}`,
+			`{"type":"fixed","name":"amount","size":4,"logicalType":"decimal","precision":4,"scale":2}`,
+			big.NewRat(3, 2),
+			big.NewRat(3, 2),
+			nil,
+		},
+		{
+			`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`,
+			`["null",{"type":"bytes","logicalType":"decimal","precision":5,"scale":3}]`,
+			big.NewRat(3, 2),
+			nil,
+			ErrIncompatibleSchema,
+		},
+		{
+			`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`,
+			`"bytes"`,
+			big.NewRat(3, 2),
+			[]byte{0, 150},
+			nil,
+		},
+		{
+			`{"type":"enum","name":"color","symbols":["RED","GREEN","BLUE"]}`,
+			`{"type":"enum","name":"color","symbols":["RED","UNKNOWN"],"default":"UNKNOWN"}`,
+			"BLUE",
+			"UNKNOWN",
+			nil,
+		},
+		{
+			`{"type":"enum","name":"color","symbols":["RED","GREEN","BLUE"]}`,
+			`{"type":"enum","name":"color","symbols":["RED"]}`,
+			"BLUE",
+			nil,
+			ErrIncompatibleSchema,
+		},
+		{
+			`{"type":"enum","name":"color","symbols":["RED"]}`,
+			`{"type":"enum","name":"colour","symbols":["RED"]}`,
+			"RED",
+			nil,
+			ErrIncompatibleSchema,
+		},
+		{
+			`{"type":"fixed","name":"md5","size":2}`,
+			`{"type":"fixed","name":"hash","aliases":["md5"],"size":2}`,
+			[]byte{1, 2},
+			[]byte{1, 2},
+			nil,
+		},
+		{
+			`{"type":"array","items":"int"}`,
+			`{"type":"array","items":"long"}`,
+			[]interface{}{int32(1), int32(2)},
+			[]interface{}{int64(1), int64(2)},
+			nil,
+		},
+		{
+			`{"type":"map","values":"float"}`,
+			`{"type":"map","values":"double"}`,
+			map[string]interface{}{"pi": float32(3)},
+			map[string]interface{}{"pi": float64(3)},
+			nil,
+		},
+		{
+			`{"type":"record","name":"post","namespace":"v1","fields":[
+				{"name":"id","type":"int"},
+				{"name":"body","type":"string"},
+				{"name":"views","type":"long"},
+				{"name":"tag","type":{"type":"enum","name":"tag","symbols":["NEWS","OPINION"]}},
+				{"name":"tags","type":{"type":"array","items":"tag"}}
+			]}`,
+			`{"type":"record","name":"post","namespace":"v2","fields":[
+				{"name":"id","type":"long"},
+				{"name":"content","aliases":["body"],"type":["null","string"]},
+				{"name":"author","type":"string","default":"anonymous"},
+				{"name":"score","type":["null","double"],"default":null},
+				{"name":"tag","type":{"type":"enum","name":"tag","symbols":["NEWS","OTHER"],"default":"OTHER"}},
+				{"name":"tags","type":{"type":"array","items":"tag"}}
+			]}`,
+			map[string]interface{}{
+				"id":    int32(1),
+				"body":  "hello",
+				"views": int64(10),
+				"tag":   "OPINION",
+				"tags":  []interface{}{"NEWS", "OPINION"},
+			},
+			map[string]interface{}{
+				"id":      int64(1),
+				"content": map[string]interface{}{"string": "hello"},
+				"author":  "anonymous",
+				"score":   nil,
+				"tag":     "OTHER",
+				"tags":    []interface{}{"NEWS", "OTHER"},
+			},
+			nil,
+		},
+		{
+			`{"type":"record","name":"post","fields":[{"name":"id","type":"int"}]}`,
+			`{"type":"record","name":"post","fields":[{"name":"id","type":"int"},{"name":"title","type":"string"}]}`,
+			map[string]interface{}{"id": int32(1)},
+			nil,
+			ErrIncompatibleSchema,
+		},
+		{
+			`{"type":"record","name":"node","fields":[{"name":"value","type":"int"},{"name":"next","type":["null","node"]}]}`,
+			`{"type":"record","name":"node","fields":[{"name":"next","type":["null","node"]},{"name":"value","type":"long"}]}`,
+			map[string]interface{}{"value": int32(1), "next": map[string]interface{}{"node": map[string]interface{}{"value": int32(2), "next": nil}}},
+			map[string]interface{}{"value": int64(1), "next": map[string]interface{}{"node": map[string]interface{}{"value": int64(2), "next": nil}}},
+			nil,
+		},
+	}
+	for i, c := range cases {
+		writer, reader := mustParseSchema(t, c.writer), mustParseSchema(t, c.reader)
+		buf, err := EncodeBinary(nil, writer, c.datum)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		datum, rest, err := DecodeBinaryResolved(append(buf, 0xff), writer, reader)
+		if err != c.expectedErr {
+			t.Errorf("case %d - expected error %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !bytes.Equal(rest, []byte{0xff}) {
+			t.Errorf("case %d - expected the remaining bytes, got %v", i, rest)
+		}
+		if r, ok := c.expected.(*big.Rat); ok {
+			if datum.(*big.Rat).Cmp(r) != 0 {
+				t.Errorf("case %d - expected %v, got %v", i, r, datum)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(datum, c.expected) {
+			t.Errorf("case %d - expected %#v, got %#v", i, c.expected, datum)
+		}
+	}
+}
+
+func TestOCFReaderWithSchema(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ocfw, err := NewOCFWriter(buf, OCFWriterConfig{Schema: mustParseSchema(t, ocfTestSchema)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ocfw.Append(ocfTestRecords(2)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ocfw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := mustParseSchema(t, `{"type":"record","name":"post","fields":[{"name":"title","type":["null","string"]},{"name":"draft","type":"boolean","default":false}]}`)
+	ocfr, err := NewOCFReaderWithSchema(buf, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ocfr.NextBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := map[string]interface{}{"title": map[string]interface{}{"string": "title"}, "draft": false}
+	expected := []interface{}{record, record}
+	if !reflect.DeepEqual(block, expected) {
+		t.Errorf("expected %v, got %v", expected, block)
+	}
+}
@@ -0,0 +1,234 @@
+package avro
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// rpcTransport - sends framed requests to a server
+type rpcTransport interface {
+	// roundTrip sends the request and, if wanted, returns the response
+	roundTrip(request []byte, wantResponse bool) ([]byte, error)
+	// stateful reports whether the handshake is done once for all the requests rather than for each one
+	stateful() bool
+}
+
+// connTransport - stateful transport over a connection
+type connTransport struct {
+	conn io.ReadWriter
+}
+
+func (t *connTransport) roundTrip(request []byte, wantResponse bool) ([]byte, error) {
+	err := writeFrames(t.conn, request)
+	if err != nil || !wantResponse {
+		return nil, err
+	}
+	return readFrames(t.conn)
+}
+
+func (t *connTransport) stateful() bool {
+	return true
+}
+
+// httpTransport - stateless transport posting each request to an URL
+type httpTransport struct {
+	url    string
+	client *http.Client
+}
+
+func (t *httpTransport) roundTrip(request []byte, wantResponse bool) ([]byte, error) {
+	var body bytes.Buffer
+	err := writeFrames(&body, request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Post(t.url, "avro/binary", &body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &RPCError{Type: string(TypeString), Value: resp.Status}
+	}
+	return readFrames(resp.Body)
+}
+
+func (t *httpTransport) stateful() bool {
+	return false
+}
+
+// Client - AVRO RPC client, calling the messages of a protocol. Calls are serialized.
+type Client struct {
+	client    *protocolIdentity
+	transport rpcTransport
+	mu        sync.Mutex
+	// server - protocol of the server, assumed to be the one of the client until the first handshake
+	server     *Protocol
+	serverHash [md5.Size]byte
+	handshaken bool
+}
+
+// NewClient - client of the protocol sending calls over the connection, such as a net.Conn.
+// The handshake is done with the first call only.
+func NewClient(protocol *Protocol, conn io.ReadWriter) (*Client, error) {
+	return newClient(protocol, &connTransport{conn})
+}
+
+// NewHTTPClient - client of the protocol posting calls to the URL, with a handshake for each call.
+// http.DefaultClient is used if httpClient is nil.
+func NewHTTPClient(protocol *Protocol, url string, httpClient *http.Client) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return newClient(protocol, &httpTransport{url, httpClient})
+}
+
+func newClient(protocol *Protocol, transport rpcTransport) (*Client, error) {
+	client, err := newProtocolIdentity(protocol)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		client:     client,
+		transport:  transport,
+		server:     protocol,
+		serverHash: client.hash,
+	}, nil
+}
+
+// Call - calls the message with its parameters by name and returns the response, nil for one-way messages.
+// Errors responded by the server are returned as *RPCError.
+// The response of a server using another version of the protocol is resolved to the protocol of the client.
+func (c *Client) Call(name string, request map[string]interface{}) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	message, ok := c.client.protocol.Messages[name]
+	if !ok {
+		return nil, ErrUnknownMessage
+	}
+	call, err := EncodeBinary(nil, callMetadataSchema, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	call, err = EncodeBinary(call, TypeString, name)
+	if err != nil {
+		return nil, err
+	}
+	call, err = encodeBinary(call, requestSchema(name, message), request, c.client.protocol.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	sendProtocol := false
+	for {
+		var buf []byte
+		handshake := !c.transport.stateful() || !c.handshaken
+		if handshake {
+			buf, err = c.handshakeRequest(sendProtocol)
+			if err != nil {
+				return nil, err
+			}
+		}
+		response, err := c.transport.roundTrip(append(buf, call...), handshake || !message.OneWay)
+		if err != nil {
+			return nil, err
+		}
+		if handshake {
+			var match string
+			match, response, err = c.readHandshake(response)
+			if err != nil {
+				return nil, err
+			}
+			if match == handshakeNone {
+				if sendProtocol {
+					return nil, ErrHandshakeFailed
+				}
+				sendProtocol = true
+				continue
+			}
+		}
+		// once the handshake is done, one-way messages have no response
+		if message.OneWay {
+			return nil, nil
+		}
+		return c.readResponse(name, message, response)
+	}
+}
+
+// handshakeRequest encodes the handshake request, with the protocol of the client if the server doesn't know it
+func (c *Client) handshakeRequest(sendProtocol bool) ([]byte, error) {
+	request := map[string]interface{}{
+		"clientHash":     c.client.hash[:],
+		"clientProtocol": nil,
+		"serverHash":     c.serverHash[:],
+		"meta":           nil,
+	}
+	if sendProtocol {
+		request["clientProtocol"] = map[string]interface{}{string(TypeString): c.client.json}
+	}
+	return EncodeBinary(nil, handshakeRequestSchema, request)
+}
+
+// readHandshake decodes the handshake response, learning the protocol of the server if it differs from the one of the client
+func (c *Client) readHandshake(buf []byte) (match string, rest []byte, err error) {
+	datum, rest, err := DecodeBinary(buf, handshakeResponseSchema)
+	if err != nil {
+		return "", nil, err
+	}
+	response := datum.(map[string]interface{})
+	if serverProtocol, ok := unionValue(response["serverProtocol"]).(string); ok {
+		server := &Protocol{}
+		err = json.Unmarshal([]byte(serverProtocol), server)
+		if err != nil {
+			return "", nil, err
+		}
+		serverHash, ok := unionValue(response["serverHash"]).([]byte)
+		if !ok {
+			return "", nil, ErrInvalidEncoding
+		}
+		c.server = server
+		copy(c.serverHash[:], serverHash)
+	}
+	match = response["match"].(string)
+	c.handshaken = match != handshakeNone
+	return match, rest, nil
+}
+
+// readResponse decodes the response of the server to the message, or the error it responded with
+func (c *Client) readResponse(name string, message *Message, buf []byte) (interface{}, error) {
+	_, buf, err := DecodeBinary(buf, callMetadataSchema)
+	if err != nil {
+		return nil, err
+	}
+	failed, buf, err := DecodeBinary(buf, TypeBoolean)
+	if err != nil {
+		return nil, err
+	}
+	serverMessage := c.server.Messages[name]
+	var writer, reader Schema = errorUnion(serverMessage), message.ErrorUnion()
+	if !failed.(bool) {
+		if serverMessage == nil {
+			return nil, ErrInvalidEncoding
+		}
+		writer, reader = serverMessage.Response, message.Response
+	}
+	d := binaryDecoder{buf: buf}
+	datum, err := d.decode(writer, c.server.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	datum, err = resolveDatum(writer, reader, datum, c.server.Namespace, c.client.protocol.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !failed.(bool) {
+		return datum, nil
+	}
+	for branch, value := range datum.(map[string]interface{}) {
+		return nil, &RPCError{Type: branch, Value: value}
+	}
+	return nil, ErrInvalidEncoding
+}
@@ -0,0 +1,153 @@
+package avro
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Handshake match, telling whether the server knows the protocol of the client and the client the one of the server
+const (
+	handshakeBoth   = "BOTH"
+	handshakeClient = "CLIENT"
+	handshakeNone   = "NONE"
+)
+
+// rpcFrameSize - size of the buffers messages are split into
+const rpcFrameSize = 8192
+
+// MaxRPCMessageSize - maximum size of the RPC messages read by clients and servers, frames excluded.
+// ErrMessageTooLarge is returned for larger messages, so that peers cannot exhaust the memory.
+var MaxRPCMessageSize int64 = 16 << 20
+
+// maxClientProtocols - maximum number of client protocols a server remembers, the others are sent at each handshake
+const maxClientProtocols = 256
+
+var (
+	handshakeRequestSchema = mustUnmarshalSchema(`{"type":"record","name":"HandshakeRequest","namespace":"org.apache.avro.ipc","fields":[
+		{"name":"clientHash","type":{"type":"fixed","name":"MD5","size":16}},
+		{"name":"clientProtocol","type":["null","string"]},
+		{"name":"serverHash","type":"MD5"},
+		{"name":"meta","type":["null",{"type":"map","values":"bytes"}]}]}`)
+	handshakeResponseSchema = mustUnmarshalSchema(`{"type":"record","name":"HandshakeResponse","namespace":"org.apache.avro.ipc","fields":[
+		{"name":"match","type":{"type":"enum","name":"HandshakeMatch","symbols":["BOTH","CLIENT","NONE"]}},
+		{"name":"serverProtocol","type":["null","string"]},
+		{"name":"serverHash","type":["null",{"type":"fixed","name":"MD5","size":16}]},
+		{"name":"meta","type":["null",{"type":"map","values":"bytes"}]}]}`)
+	// handshakeHashBranch - branch of the union holding the hash in handshake responses
+	handshakeHashBranch = "org.apache.avro.ipc.MD5"
+	// callMetadataSchema - metadata prefixing call requests and responses
+	callMetadataSchema = &MapSchema{Type: TypeMap, Value: TypeBytes}
+)
+
+func mustUnmarshalSchema(schemaJSON string) Schema {
+	var anySchema AnySchema
+	err := json.Unmarshal([]byte(schemaJSON), &anySchema)
+	if err != nil {
+		panic(err)
+	}
+	return anySchema.Schema()
+}
+
+// RPCError - error responded to a call: Type is the fullname of one of the error types declared by the message
+// and Value the datum of this error, or "string" and the message of the error for unexpected errors.
+type RPCError struct {
+	Type  string
+	Value interface{}
+}
+
+func (e *RPCError) Error() string {
+	if message, ok := e.Value.(string); ok && e.Type == string(TypeString) {
+		return message
+	}
+	return fmt.Sprintf("%s: %v", e.Type, e.Value)
+}
+
+// protocolIdentity - JSON encoding of a protocol and its hash, as exchanged in handshakes
+type protocolIdentity struct {
+	protocol *Protocol
+	json     string
+	hash     [md5.Size]byte
+}
+
+func newProtocolIdentity(protocol *Protocol) (*protocolIdentity, error) {
+	protocolBytes, err := json.Marshal(protocol)
+	if err != nil {
+		return nil, err
+	}
+	return &protocolIdentity{protocol, string(protocolBytes), md5.Sum(protocolBytes)}, nil
+}
+
+// requestSchema - the parameters of a message are encoded as the fields of a record
+func requestSchema(name string, message *Message) *RecordSchema {
+	return &RecordSchema{Type: TypeRecord, Name: name, Fields: message.Request}
+}
+
+// errorUnion - a server unaware of the message only responds with unexpected errors
+func errorUnion(message *Message) UnionSchema {
+	if message == nil {
+		return UnionSchema{TypeString}
+	}
+	return message.ErrorUnion()
+}
+
+// unionValue returns the value of the union datum, nil for null
+func unionValue(datum interface{}) interface{} {
+	values, _ := datum.(map[string]interface{})
+	for _, value := range values {
+		return value
+	}
+	return nil
+}
+
+// writeFrames writes the message as a list of length-prefixed buffers, terminated by an empty buffer
+func writeFrames(w io.Writer, message []byte) error {
+	framed := make([]byte, 0, len(message)+4*(len(message)/rpcFrameSize+2))
+	length := make([]byte, 4)
+	for len(message) > 0 {
+		size := len(message)
+		if size > rpcFrameSize {
+			size = rpcFrameSize
+		}
+		binary.BigEndian.PutUint32(length, uint32(size))
+		framed = append(framed, length...)
+		framed = append(framed, message[:size]...)
+		message = message[size:]
+	}
+	framed = append(framed, 0, 0, 0, 0)
+	_, err := w.Write(framed)
+	return err
+}
+
+// readFrames reads a message written as a list of length-prefixed buffers.
+// io.EOF is returned if the reader ends before the message begins, ErrMessageTooLarge if it exceeds MaxRPCMessageSize.
+func readFrames(r io.Reader) ([]byte, error) {
+	var message bytes.Buffer
+	length := make([]byte, 4)
+	for first := true; ; first = false {
+		_, err := io.ReadFull(r, length)
+		if err == io.EOF && !first {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		size := int64(binary.BigEndian.Uint32(length))
+		if size == 0 {
+			return message.Bytes(), nil
+		}
+		if int64(message.Len())+size > MaxRPCMessageSize {
+			return nil, ErrMessageTooLarge
+		}
+		n, err := io.CopyN(&message, r, size)
+		if n < size {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
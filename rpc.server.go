@@ -0,0 +1,236 @@
+package avro
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Responder - answers the calls to the messages of a protocol
+type Responder interface {
+	// Respond - request holds the parameters of the message by name.
+	// A returned *RPCError is responded as the declared error it holds, other errors as unexpected errors.
+	Respond(message string, request map[string]interface{}) (response interface{}, err error)
+}
+
+// ResponderFunc - adapts a function to the Responder interface
+type ResponderFunc func(message string, request map[string]interface{}) (interface{}, error)
+
+// Respond - calls f
+func (f ResponderFunc) Respond(message string, request map[string]interface{}) (interface{}, error) {
+	return f(message, request)
+}
+
+// Server - AVRO RPC server, answering calls over connections (stateful, with a single handshake per connection)
+// or over HTTP (stateless, with a handshake per request).
+// The parameters of clients using another version of the protocol are resolved to the protocol of the server.
+type Server struct {
+	server    *protocolIdentity
+	responder Responder
+	mu        sync.Mutex
+	// clients - protocols of the clients met so far, by hash
+	clients map[[md5.Size]byte]*Protocol
+}
+
+// NewServer - server of the protocol, answering calls with the responder
+func NewServer(protocol *Protocol, responder Responder) (*Server, error) {
+	server, err := newProtocolIdentity(protocol)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		server:    server,
+		responder: responder,
+		clients:   map[[md5.Size]byte]*Protocol{server.hash: protocol},
+	}, nil
+}
+
+// Serve - serves the connections accepted by the listener, each one in its own goroutine
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			s.ServeConn(conn)
+		}()
+	}
+}
+
+// ServeConn - answers the calls sent over the connection, such as a net.Conn, until it is closed by the client
+func (s *Server) ServeConn(conn io.ReadWriter) error {
+	var client *Protocol
+	for {
+		request, err := readFrames(conn)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var response []byte
+		response, client, err = s.respond(request, client)
+		if err != nil {
+			return err
+		}
+		// one-way calls are not answered once the handshake is done
+		if len(response) == 0 {
+			continue
+		}
+		err = writeFrames(conn, response)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ServeHTTP - answers the call posted in the body of the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	request, err := readFrames(r.Body)
+	if err == ErrMessageTooLarge {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response, _, err := s.respond(request, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "avro/binary")
+	writeFrames(w, response)
+}
+
+// respond answers the request, which starts with a handshake unless the protocol of the client is already known.
+// The protocol of the client is returned, nil if the handshake failed.
+func (s *Server) respond(request []byte, client *Protocol) (response []byte, _ *Protocol, err error) {
+	if client == nil {
+		var handshake interface{}
+		handshake, request, err = DecodeBinary(request, handshakeRequestSchema)
+		if err != nil {
+			return nil, nil, err
+		}
+		client, response, err = s.handshake(handshake.(map[string]interface{}))
+		if err != nil || client == nil {
+			return response, nil, err
+		}
+	}
+	_, request, err = DecodeBinary(request, callMetadataSchema)
+	if err != nil {
+		return nil, nil, err
+	}
+	name, request, err := DecodeBinary(request, TypeString)
+	if err != nil {
+		return nil, nil, err
+	}
+	clientMessage, ok := client.Messages[name.(string)]
+	if !ok {
+		return nil, nil, ErrUnknownMessage
+	}
+	d := binaryDecoder{buf: request}
+	params, err := d.decode(requestSchema(name.(string), clientMessage), client.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	message := s.server.protocol.Messages[name.(string)]
+	var (
+		result  interface{}
+		callErr error
+	)
+	if message == nil {
+		callErr = ErrUnknownMessage
+	} else {
+		params, err = resolveDatum(requestSchema(name.(string), clientMessage), requestSchema(name.(string), message), params, client.Namespace, s.server.protocol.Namespace)
+		if err != nil {
+			callErr = err
+		} else {
+			result, callErr = s.responder.Respond(name.(string), params.(map[string]interface{}))
+		}
+	}
+	// the client doesn't wait for the response of one-way messages
+	if clientMessage.OneWay {
+		return response, client, nil
+	}
+	response, err = EncodeBinary(response, callMetadataSchema, map[string]interface{}{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.appendResult(response, message, result, callErr), client, nil
+}
+
+// appendResult appends the response or the error of a call, errors which cannot be encoded being responded as unexpected errors
+func (s *Server) appendResult(buf []byte, message *Message, result interface{}, callErr error) []byte {
+	namespace := s.server.protocol.Namespace
+	if callErr == nil {
+		encoded, err := encodeBinary(append(buf, 0), message.Response, result, namespace)
+		if err == nil {
+			return encoded
+		}
+		callErr = err
+	}
+	var rpcErr *RPCError
+	if errors.As(callErr, &rpcErr) {
+		encoded, err := encodeBinary(append(buf, 1), errorUnion(message), map[string]interface{}{rpcErr.Type: rpcErr.Value}, namespace)
+		if err == nil {
+			return encoded
+		}
+		callErr = err
+	}
+	// a string is always encoded successfully
+	encoded, _ := encodeBinary(append(buf, 1), errorUnion(message), map[string]interface{}{string(TypeString): callErr.Error()}, namespace)
+	return encoded
+}
+
+// handshake looks up the protocol of the client, nil if unknown, and returns the encoded handshake response.
+// A protocol sent by the client is only trusted if it matches the client hash.
+func (s *Server) handshake(request map[string]interface{}) (*Protocol, []byte, error) {
+	var clientHash [md5.Size]byte
+	copy(clientHash[:], request["clientHash"].([]byte))
+	s.mu.Lock()
+	client := s.clients[clientHash]
+	s.mu.Unlock()
+	if client == nil {
+		if clientProtocol, ok := unionValue(request["clientProtocol"]).(string); ok && md5.Sum([]byte(clientProtocol)) == clientHash {
+			client = &Protocol{}
+			err := json.Unmarshal([]byte(clientProtocol), client)
+			if err != nil {
+				return nil, nil, err
+			}
+			s.mu.Lock()
+			if len(s.clients) < maxClientProtocols {
+				s.clients[clientHash] = client
+			}
+			s.mu.Unlock()
+		}
+	}
+	response := map[string]interface{}{
+		"match":          handshakeBoth,
+		"serverProtocol": nil,
+		"serverHash":     nil,
+		"meta":           nil,
+	}
+	if !bytes.Equal(request["serverHash"].([]byte), s.server.hash[:]) {
+		response["match"] = handshakeClient
+		response["serverProtocol"] = map[string]interface{}{string(TypeString): s.server.json}
+		response["serverHash"] = map[string]interface{}{handshakeHashBranch: s.server.hash[:]}
+	}
+	if client == nil {
+		response["match"] = handshakeNone
+	}
+	responseBytes, err := EncodeBinary(nil, handshakeResponseSchema, response)
+	return client, responseBytes, err
+}
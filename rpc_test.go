@@ -0,0 +1,316 @@
+package avro
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+const calculatorProtocol = `{"protocol":"Calculator","namespace":"test","types":[
+	{"type":"error","name":"Overflow","fields":[{"name":"message","type":"string"}]}],
+	"messages":{
+		"add":{"request":[{"name":"a","type":"int"},{"name":"b","type":"int"}],"response":"int","errors":["Overflow"]},
+		"divide":{"request":[{"name":"a","type":"int"},{"name":"b","type":"int"}],"response":"int"},
+		"reset":{"request":[],"response":"null","one-way":true}}}`
+
+func calculatorResponder(resets *int) Responder {
+	return ResponderFunc(func(message string, request map[string]interface{}) (interface{}, error) {
+		switch message {
+		case "add":
+			a, b := request["a"].(int32), request["b"].(int32)
+			if a > 0 && b > 0 && a+b < 0 {
+				return nil, &RPCError{Type: "test.Overflow", Value: map[string]interface{}{"message": "overflow"}}
+			}
+			return a + b, nil
+		case "divide":
+			if request["b"].(int32) == 0 {
+				return nil, errors.New("division by zero")
+			}
+			return request["a"].(int32) / request["b"].(int32), nil
+		default:
+			*resets++
+			return nil, nil
+		}
+	})
+}
+
+func mustUnmarshalProtocol(t *testing.T, protocolJSON string) *Protocol {
+	var protocol Protocol
+	err := json.Unmarshal([]byte(protocolJSON), &protocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &protocol
+}
+
+func testCalculatorCalls(t *testing.T, client *Client) {
+	cases := []struct {
+		message          string
+		request          map[string]interface{}
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{"reset", map[string]interface{}{}, nil, nil},
+		{"add", map[string]interface{}{"a": int32(1), "b": int32(2)}, int32(3), nil},
+		{"add", map[string]interface{}{"a": int32(2147483647), "b": int32(1)}, nil, &RPCError{"test.Overflow", map[string]interface{}{"message": "overflow"}}},
+		{"divide", map[string]interface{}{"a": int32(6), "b": int32(3)}, int32(2), nil},
+		{"divide", map[string]interface{}{"a": int32(6), "b": int32(0)}, nil, &RPCError{"string", "division by zero"}},
+		{"reset", map[string]interface{}{}, nil, nil},
+		{"multiply", map[string]interface{}{}, nil, ErrUnknownMessage},
+	}
+	for i, c := range cases {
+		response, err := client.Call(c.message, c.request)
+		if !reflect.DeepEqual(err, c.expectedErr) {
+			t.Errorf("case %d - expected error %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if !reflect.DeepEqual(response, c.expectedResponse) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedResponse, response)
+		}
+	}
+}
+
+func TestRPCConn(t *testing.T) {
+	protocol := mustUnmarshalProtocol(t, calculatorProtocol)
+	var resets int
+	server, err := NewServer(protocol, calculatorResponder(&resets))
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConn, clientConn := net.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- server.ServeConn(serverConn)
+	}()
+	client, err := NewClient(protocol, clientConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCalculatorCalls(t, client)
+	clientConn.Close()
+	if err = <-done; err != nil {
+		t.Errorf("expected the server to stop, got %v", err)
+	}
+	if resets != 2 {
+		t.Errorf("expected 2 resets, got %d", resets)
+	}
+}
+
+func TestRPCHTTP(t *testing.T) {
+	protocol := mustUnmarshalProtocol(t, calculatorProtocol)
+	var resets int
+	server, err := NewServer(protocol, calculatorResponder(&resets))
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client, err := NewHTTPClient(protocol, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCalculatorCalls(t, client)
+	if resets != 2 {
+		t.Errorf("expected 2 resets, got %d", resets)
+	}
+}
+
+func TestRPCProtocolResolution(t *testing.T) {
+	serverProtocol := mustUnmarshalProtocol(t, `{"protocol":"Calculator","namespace":"test","messages":{
+		"add":{"request":[{"name":"a","type":"int"},{"name":"b","type":"int"},{"name":"c","type":"int","default":10}],"response":"int"}}}`)
+	clientProtocol := mustUnmarshalProtocol(t, `{"protocol":"Calculator","namespace":"test","messages":{
+		"add":{"request":[{"name":"a","type":"int"},{"name":"b","type":"int"}],"response":"long"},
+		"subtract":{"request":[{"name":"a","type":"int"},{"name":"b","type":"int"}],"response":"int"}}}`)
+	server, err := NewServer(serverProtocol, ResponderFunc(func(message string, request map[string]interface{}) (interface{}, error) {
+		return request["a"].(int32) + request["b"].(int32) + request["c"].(int32), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, transport := range []string{"conn", "http"} {
+		var client *Client
+		if transport == "conn" {
+			serverConn, clientConn := net.Pipe()
+			defer clientConn.Close()
+			go server.ServeConn(serverConn)
+			client, err = NewClient(clientProtocol, clientConn)
+		} else {
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+			client, err = NewHTTPClient(clientProtocol, httpServer.URL, nil)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			response, err := client.Call("add", map[string]interface{}{"a": int32(1), "b": int32(2)})
+			if err != nil || response != int64(13) {
+				t.Errorf("%s - expected 13, got %v, %v", transport, response, err)
+			}
+		}
+		_, err = client.Call("subtract", map[string]interface{}{"a": int32(1), "b": int32(2)})
+		if !reflect.DeepEqual(err, &RPCError{"string", ErrUnknownMessage.Error()}) {
+			t.Errorf("%s - expected %v, got %v", transport, ErrUnknownMessage, err)
+		}
+	}
+}
+
+func TestRPCHandshake(t *testing.T) {
+	server, err := NewServer(mustUnmarshalProtocol(t, calculatorProtocol), calculatorResponder(new(int)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	protocolJSON := func(namespace string) string {
+		return `{"protocol":"Calculator","namespace":"` + namespace + `","messages":{}}`
+	}
+	hash := func(protocol string) []byte {
+		sum := md5.Sum([]byte(protocol))
+		return sum[:]
+	}
+	cases := []struct {
+		clientHash     []byte
+		clientProtocol interface{}
+		expectedMatch  string
+		expected       *Protocol
+	}{
+		{hash(protocolJSON("v1")), nil, handshakeNone, nil},
+		// a protocol not matching the hash is not trusted
+		{hash(protocolJSON("v1")), map[string]interface{}{"string": protocolJSON("v2")}, handshakeNone, nil},
+		{hash(protocolJSON("v1")), nil, handshakeNone, nil},
+		{hash(protocolJSON("v1")), map[string]interface{}{"string": protocolJSON("v1")}, handshakeBoth, mustUnmarshalProtocol(t, protocolJSON("v1"))},
+		{hash(protocolJSON("v1")), nil, handshakeBoth, mustUnmarshalProtocol(t, protocolJSON("v1"))},
+		{hash(protocolJSON("v1")), map[string]interface{}{"string": protocolJSON("v2")}, handshakeBoth, mustUnmarshalProtocol(t, protocolJSON("v1"))},
+	}
+	for i, c := range cases {
+		client, responseBytes, err := server.handshake(map[string]interface{}{
+			"clientHash":     c.clientHash,
+			"clientProtocol": c.clientProtocol,
+			"serverHash":     server.server.hash[:],
+			"meta":           nil,
+		})
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		response, _, err := DecodeBinary(responseBytes, handshakeResponseSchema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if match := response.(map[string]interface{})["match"]; match != c.expectedMatch {
+			t.Errorf("case %d - expected %s, got %s", i, c.expectedMatch, match)
+		}
+		if !reflect.DeepEqual(client, c.expected) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expected, client)
+		}
+	}
+	for i := 0; i < 2*maxClientProtocols; i++ {
+		protocol := protocolJSON("v" + strconv.Itoa(i))
+		client, _, err := server.handshake(map[string]interface{}{
+			"clientHash":     hash(protocol),
+			"clientProtocol": map[string]interface{}{"string": protocol},
+			"serverHash":     server.server.hash[:],
+			"meta":           nil,
+		})
+		if err != nil || client == nil {
+			t.Fatalf("%d - expected a protocol, got %v, %v", i, client, err)
+		}
+	}
+	if len(server.clients) > maxClientProtocols {
+		t.Errorf("expected at most %d protocols, got %d", maxClientProtocols, len(server.clients))
+	}
+}
+
+func TestRPCFrames(t *testing.T) {
+	cases := [][]byte{
+		{},
+		[]byte("message"),
+		bytes.Repeat([]byte{42}, 2*rpcFrameSize+1),
+	}
+	for i, message := range cases {
+		var buf bytes.Buffer
+		err := writeFrames(&buf, message)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if expectedLen := len(message) + 4*((len(message)+rpcFrameSize-1)/rpcFrameSize+1); buf.Len() != expectedLen {
+			t.Errorf("case %d - expected %d bytes, got %d", i, expectedLen, buf.Len())
+		}
+		framed := buf.Bytes()
+		read, err := readFrames(bytes.NewReader(framed))
+		if err != nil || !bytes.Equal(read, message) {
+			t.Errorf("case %d - expected %v, got %v, %v", i, message, read, err)
+		}
+		_, err = readFrames(bytes.NewReader(framed[:len(framed)-1]))
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("case %d - expected %v, got %v", i, io.ErrUnexpectedEOF, err)
+		}
+	}
+	_, err := readFrames(bytes.NewReader(nil))
+	if err != io.EOF {
+		t.Errorf("expected %v, got %v", io.EOF, err)
+	}
+}
+
+func TestRPCMessageTooLarge(t *testing.T) {
+	defer func(maxSize int64) { MaxRPCMessageSize = maxSize }(MaxRPCMessageSize)
+	MaxRPCMessageSize = 2 * rpcFrameSize
+	var buf bytes.Buffer
+	err := writeFrames(&buf, bytes.Repeat([]byte{42}, 2*rpcFrameSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = readFrames(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Errorf("expected a message of MaxRPCMessageSize to be read, got %v", err)
+	}
+	// the frames of the message, followed by frames of a byte which never end
+	unterminated := buf.Bytes()[:buf.Len()-4]
+	hostile := func() io.Reader {
+		return io.MultiReader(bytes.NewReader(unterminated), &endlessFrames{})
+	}
+	_, err = readFrames(hostile())
+	if err != ErrMessageTooLarge {
+		t.Errorf("expected %v, got %v", ErrMessageTooLarge, err)
+	}
+	server, err := NewServer(mustUnmarshalProtocol(t, calculatorProtocol), calculatorResponder(new(int)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.ServeConn(struct {
+		io.Reader
+		io.Writer
+	}{hostile(), ioutil.Discard})
+	if err != ErrMessageTooLarge {
+		t.Errorf("expected %v, got %v", ErrMessageTooLarge, err)
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", hostile()))
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected %d, got %d", http.StatusRequestEntityTooLarge, recorder.Code)
+	}
+}
+
+// endlessFrames - frames of a byte, sent forever
+type endlessFrames struct {
+	offset int
+}
+
+func (f *endlessFrames) Read(p []byte) (int, error) {
+	frame := []byte{0, 0, 0, 1, 42}
+	for i := range p {
+		p[i] = frame[f.offset]
+		f.offset = (f.offset + 1) % len(frame)
+	}
+	return len(p), nil
+}
@@ -16,13 +16,14 @@ type AnySchema struct {
 	schema Schema
 }
 
-// UnmarshalJSON -
+// UnmarshalJSON - parsing stops at the first problem met, returned as a *SchemaError
 func (as *AnySchema) UnmarshalJSON(bytes []byte) error {
 	value, err := unmarshaller.Parse(string(bytes))
 	if err != nil {
 		return err
 	}
-	schema, err := translateValue2AnySchema(value)
+	p := newSchemaParser(false)
+	schema, err := p.translateValue2AnySchema(value, "", "")
 	if err != nil {
 		return err
 	}
@@ -30,59 +31,127 @@ func (as *AnySchema) UnmarshalJSON(bytes []byte) error {
 	return nil
 }
 
-func translateValue2AnySchema(value *fastjson.Value, additionalTypes ...Type) (Schema, error) {
+// ValidateSchema - parses the JSON schema and returns every problem met, as SchemaErrors, nil if the schema is valid.
+// Nodes whose parsing fails are skipped, so that their siblings are checked as well.
+func ValidateSchema(schemaBytes []byte) error {
+	value, err := unmarshaller.Parse(string(schemaBytes))
+	if err != nil {
+		return err
+	}
+	p := newSchemaParser(true)
+	_, err = p.translateValue2AnySchema(value, "", "")
+	if err = p.report(err); err != nil {
+		return err
+	}
+	if len(p.errs) > 0 {
+		return p.errs
+	}
+	return nil
+}
+
+// schemaParser - state of the translation of a JSON schema:
+// the named types defined so far and, when collecting, the errors met so far.
+type schemaParser struct {
+	names      namedTypes
+	collecting bool
+	errs       SchemaErrors
+}
+
+func newSchemaParser(collecting bool) *schemaParser {
+	return &schemaParser{
+		names:      make(namedTypes),
+		collecting: collecting,
+	}
+}
+
+// report - when collecting, err is recorded and nil is returned so that the parsing goes on with the next node
+func (p *schemaParser) report(err error) error {
+	if err == nil || !p.collecting {
+		return err
+	}
+	switch e := err.(type) {
+	case *SchemaError:
+		p.errs = append(p.errs, e)
+	case SchemaErrors:
+		p.errs = append(p.errs, e...)
+	default:
+		return err
+	}
+	return nil
+}
+
+// schemaError - value is the offending JSON value, if any
+func schemaError(path string, value *fastjson.Value, err error, reason string) *SchemaError {
+	schemaErr := &SchemaError{
+		Path:   path,
+		Reason: reason,
+		Err:    err,
+	}
+	if value != nil {
+		schemaErr.Value = value.String()
+	}
+	return schemaErr
+}
+
+// translateValue2AnySchema - path locates the value in the schema
+// and namespace is the enclosing namespace used to resolve names.
+func (p *schemaParser) translateValue2AnySchema(value *fastjson.Value, path, namespace string) (Schema, error) {
 	union, err := value.Array()
 	isUnion := err == nil
 	if isUnion {
-		return translateValues2UnionSchema(union, additionalTypes...)
+		return p.translateValues2UnionSchema(union, path, namespace)
 	}
-	isComplex := value.Exists("type")
+	isComplex := value.Type() == fastjson.TypeObject
 	if isComplex {
-		stringBytes := value.GetStringBytes("type")
+		if !value.Exists("type") {
+			return nil, schemaError(path, nil, ErrInvalidSchema, "missing type")
+		}
+		stringBytes, err := value.Get("type").StringBytes()
+		if err != nil {
+			return nil, schemaError(joinPath(path, "type"), value.Get("type"), ErrInvalidSchema, "type must be a string")
+		}
 		typeName := Type(stringBytes)
 		switch typeName {
 		case TypeArray:
-			return translateValue2ArraySchema(value, additionalTypes...)
+			return p.translateValue2ArraySchema(value, path, namespace)
 		case TypeMap:
-			return translateValueToMapSchema(value, additionalTypes...)
+			return p.translateValueToMapSchema(value, path, namespace)
 		case TypeEnum:
-			return translateValueToEnumSchema(value)
+			return p.translateValueToEnumSchema(value, path, namespace)
 		case TypeFixed:
-			return translateValueToFixedSchema(value)
-		case TypeRecord:
-			return translateValueToRecordSchema(value, additionalTypes...)
-		case TypeBytes, TypeInt32, TypeInt64:
-			return translateValue2DerivedPrimitiveSchema(typeName, value)
+			return p.translateValueToFixedSchema(value, path, namespace)
+		case TypeRecord, TypeError:
+			return p.translateValueToRecordSchema(value, path, namespace)
 		default:
-			return nil, ErrUnsupportedType
+			if isPrimitive(typeName) {
+				return translateValue2DerivedPrimitiveSchema(typeName, value, path)
+			}
+			return p.translateName2ReferenceSchema(string(typeName), value.Get("type"), joinPath(path, "type"), namespace)
 		}
 	}
 	stringBytes, err := value.StringBytes()
 	if err != nil {
-		return nil, ErrInvalidSchema
+		return nil, schemaError(path, value, ErrInvalidSchema, "schema must be a type name, an object or an array")
 	}
 	typeName := Type(stringBytes)
-	switch typeName {
-	case TypeNull, TypeBoolean, TypeFloat32, TypeFloat64, TypeInt32, TypeInt64, TypeString, TypeBytes:
+	if isPrimitive(typeName) {
 		return typeName, nil
-	default:
-		if contains(additionalTypes, typeName) {
-			return typeName, nil
-		}
-		return nil, ErrUnsupportedType
 	}
+	return p.translateName2ReferenceSchema(string(typeName), value, path, namespace)
+}
+
+func (p *schemaParser) translateName2ReferenceSchema(name string, value *fastjson.Value, path, namespace string) (Schema, error) {
+	schema, ok := p.names.resolve(name, namespace)
+	if !ok {
+		return nil, schemaError(path, value, ErrUnsupportedType, "unknown type")
+	}
+	return &ReferenceSchema{
+		Name:   name,
+		Schema: schema,
+	}, nil
 }
 
 // Schema returns the unmarshalled schema
 func (as *AnySchema) Schema() Schema {
 	return as.schema
 }
-
-func contains(s []Type, e Type) bool {
-	for _, a := range s {
-		if a == e {
-			return true
-		}
-	}
-	return false
-}
@@ -3,6 +3,8 @@ package avro
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -42,6 +44,11 @@ func TestMarshaling(t *testing.T) {
 			[]byte(`{"type":"record","name":"LongList","aliases":["LinkedLongs"],"fields":[{"name":"value","type":"long","default":0},{"name":"next","type":["null","LongList"]}]}`),
 			nil,
 		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"error","namespace":"test","name":"NotFound","fields":[{"name":"message","type":"string"}]}`),
+			nil,
+		},
 		{
 			TypeRecord,
 			[]byte(`{"type":"record","namespace":"test","name":"LongList","aliases":["LinkedLongs"],"fields":[{"name":"value","type":"something"},{"name":"next","type":["null","LongList"]}]}`),
@@ -97,6 +104,36 @@ func TestMarshaling(t *testing.T) {
 			[]byte(`{"type":"record","name":"LongList","aliases":[0],"fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"]}]}`),
 			ErrInvalidSchema,
 		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"card","fields":[{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS","DIAMONDS","CLUBS"]}},{"name":"trump","type":["null","Suit"]},{"name":"hash","type":{"type":"fixed","name":"md5","size":16}},{"name":"previousHash","type":"md5"}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","namespace":"game","name":"card","fields":[{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES"]}},{"name":"trump","type":"game.Suit"},{"name":"deck","type":{"type":"record","namespace":"other","name":"deck","fields":[{"name":"suit","type":"game.Suit"},{"name":"self","type":["null","deck"]}]}}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","namespace":"game","name":"card","fields":[{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES"]}},{"name":"deck","type":{"type":"record","namespace":"other","name":"deck","fields":[{"name":"suit","type":"Suit"}]}}]}`),
+			ErrUnsupportedType,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"card","fields":[{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES"]}},{"name":"trump","type":{"type":"enum","name":"Suit","symbols":["HEARTS"]}}]}`),
+			ErrRedefinedType,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"card","fields":[{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES"]}},{"name":"trump","type":{"type":"fixed","name":"Suit","namespace":"other","size":2}}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"card","fields":[{"name":"suit","type":{"type":"enum","name":"long","symbols":["SPADES"]}}]}`),
+			ErrInvalidSchema,
+		},
 		{
 			TypeArray,
 			[]byte(`{"type":"array","items":"string"}`),
@@ -157,6 +194,46 @@ func TestMarshaling(t *testing.T) {
 			[]byte(`{"type":"enum","name":0,"symbols":["SPADES"]}`),
 			ErrInvalidSchema,
 		},
+		{
+			TypeEnum,
+			[]byte(`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"],"default":"HEARTS"}`),
+			nil,
+		},
+		{
+			TypeEnum,
+			[]byte(`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"],"default":"CLUBS"}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeEnum,
+			[]byte(`{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"],"default":0}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeEnum,
+			[]byte(`{"type":"enum","name":"Suit","symbols":["SPADES","SPADES"]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeEnum,
+			[]byte(`{"type":"enum","name":"Suit","symbols":["_spades1","Hearts_2"]}`),
+			nil,
+		},
+		{
+			TypeEnum,
+			[]byte(`{"type":"enum","name":"Suit","symbols":["1SPADES"]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeEnum,
+			[]byte(`{"type":"enum","name":"Suit","symbols":["SPADES-HEARTS"]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeEnum,
+			[]byte(`{"type":"enum","name":"Suit","symbols":[""]}`),
+			ErrInvalidSchema,
+		},
 		{
 			TypeEnum,
 			[]byte(`{"type":"enum","name":"Suit","symbols":["SPADES",11,"DIAMONDS","CLUBS"]}`),
@@ -329,8 +406,8 @@ func TestMarshaling(t *testing.T) {
 		},
 		{
 			TypeBytes,
-			[]byte(`{"type":"bytes"}`),
-			ErrInvalidSchema,
+			[]byte(`{"type":"bytes","connect.version":1}`),
+			nil,
 		},
 		{
 			Type(LogicalTypeTime),
@@ -372,6 +449,161 @@ func TestMarshaling(t *testing.T) {
 			[]byte(`{"type":"bytes","logicalType":"decimal","precision":5,"scale":"something"}`),
 			ErrInvalidSchema,
 		},
+		{
+			Type(LogicalTypeTimestampMillis),
+			[]byte(`{"type":"long","logicalType":"timestamp-millis"}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeTimestampMicros),
+			[]byte(`{"type":"long","logicalType":"timestamp-micros"}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeLocalTimestampMillis),
+			[]byte(`{"type":"long","logicalType":"local-timestamp-millis"}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeLocalTimestampMicros),
+			[]byte(`{"type":"long","logicalType":"local-timestamp-micros"}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeTimestampMillis),
+			[]byte(`{"type":"int","logicalType":"timestamp-millis"}`),
+			ErrInvalidSchema,
+		},
+		{
+			Type(LogicalTypeTimeMillis),
+			[]byte(`{"type":"int","logicalType":"time-millis"}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeTimeMillis),
+			[]byte(`{"type":"long","logicalType":"time-millis"}`),
+			ErrInvalidSchema,
+		},
+		{
+			Type(LogicalTypeTimeMicros),
+			[]byte(`{"type":"long","logicalType":"time-micros"}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeUUID),
+			[]byte(`{"type":"string","logicalType":"uuid"}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeUUID),
+			[]byte(`{"type":"bytes","logicalType":"uuid"}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeString,
+			[]byte(`{"type":"string","logicalType":"something"}`),
+			nil,
+		},
+		{
+			TypeFloat64,
+			[]byte(`{"type":"double","logicalType":"something"}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeDecimal),
+			[]byte(`{"type":"bytes","logicalType":"decimal","precision":5,"scale":6}`),
+			ErrInvalidSchema,
+		},
+		{
+			Type(LogicalTypeDecimal),
+			[]byte(`{"type":"fixed","logicalType":"decimal","name":"money","size":4,"precision":9,"scale":2}`),
+			nil,
+		},
+		{
+			Type(LogicalTypeDecimal),
+			[]byte(`{"type":"fixed","logicalType":"decimal","name":"money","size":4,"precision":10,"scale":2}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeFixed,
+			[]byte(`{"type":"fixed","logicalType":"something","name":"md5","size":16}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":"int","default":"0"}]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":"int","default":2147483648}]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":["null","string"],"default":null}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":["null","string"],"default":"a"}]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":["string","null"],"default":"a"}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":"bytes","default":"\u00ff"}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":"bytes","default":"\u0100"}]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"fixed","name":"f","size":2},"default":"ab"}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"fixed","name":"f","size":2},"default":"abc"}]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"enum","name":"e","symbols":["A","B"]},"default":"B"}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"enum","name":"e","symbols":["A","B"]},"default":"C"}]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"array","items":"long"},"default":[1,2]}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"map","values":"long"},"default":{"b":true}}]}`),
+			ErrInvalidSchema,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"record","name":"p","fields":[{"name":"x","type":"double"},{"name":"y","type":"double","default":0}]},"default":{"x":1}}]}`),
+			nil,
+		},
+		{
+			TypeRecord,
+			[]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"record","name":"p","fields":[{"name":"x","type":"double"}]},"default":{"y":1}}]}`),
+			ErrInvalidSchema,
+		},
 	}
 	var (
 		anySchema        AnySchema
@@ -380,7 +612,7 @@ func TestMarshaling(t *testing.T) {
 	)
 	for i, c := range cases {
 		err := json.Unmarshal(c.schemaBytes, &anySchema)
-		if err != nil && err != c.expectedErr {
+		if err != nil && !errors.Is(err, c.expectedErr) {
 			t.Errorf("case %d - error %v, got %v", i, c.expectedErr, err)
 		}
 		if err != nil {
@@ -400,3 +632,97 @@ func TestMarshaling(t *testing.T) {
 		}
 	}
 }
+
+func TestNamedTypeReferences(t *testing.T) {
+	schemaBytes := []byte(`{"type":"record","namespace":"game","name":"card","fields":[{"name":"suit","type":{"type":"enum","name":"Suit","symbols":["SPADES","HEARTS"]}},{"name":"trump","type":["null","game.Suit"]},{"name":"next","type":["null","card"]}]}`)
+	var anySchema AnySchema
+	err := json.Unmarshal(schemaBytes, &anySchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := anySchema.Schema().(*RecordSchema)
+	if record.FullName("") != "game.card" {
+		t.Errorf("expected game.card, got %s", record.FullName(""))
+	}
+	suit := record.Fields[0].Type.(*EnumSchema)
+	if suit.FullName("game") != "game.Suit" {
+		t.Errorf("expected game.Suit, got %s", suit.FullName("game"))
+	}
+	trump := record.Fields[1].Type.(UnionSchema)[1].(*ReferenceSchema)
+	if trump.Schema != suit || trump.TypeName() != TypeEnum {
+		t.Errorf("expected reference to the Suit enum, got %v", trump.Schema)
+	}
+	next := record.Fields[2].Type.(UnionSchema)[1].(*ReferenceSchema)
+	if next.Schema != record || next.TypeName() != TypeRecord {
+		t.Errorf("expected reference to the card record, got %v", next.Schema)
+	}
+}
+
+func TestSchemaError(t *testing.T) {
+	cases := []struct {
+		schemaBytes   string
+		expectedErr   error
+		expectedPath  string
+		expectedValue string
+	}{
+		{`{"type":"record","name":"r","fields":[{"name":"a","type":"int"},{"name":"b","type":["null",{"type":"array","items":"unknown"}]}]}`, ErrUnsupportedType, "fields[1].type[1].items", `"unknown"`},
+		{`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"fixed","name":"f","size":-1}}]}`, ErrInvalidSchema, "fields[0].type.size", "-1"},
+		{`{"type":"map","values":{"type":"enum","name":"e","symbols":["A","1B"]}}`, ErrInvalidSchema, "values.symbols[1]", `"1B"`},
+		{`["null",{"type":"fixed","name":"f","size":2},{"type":"fixed","name":"f","size":4}]`, ErrRedefinedType, "[2].name", `"f"`},
+		{`{"type":"record","name":"r","fields":[{"name":"a","type":"int","order":"random"}]}`, ErrInvalidSchema, "fields[0].order", `"random"`},
+		{`{"type":"record","name":"r"}`, ErrInvalidSchema, "", ""},
+		{`{"type":"enum","name":"1bad","symbols":["A"]}`, ErrInvalidSchema, "name", `"1bad"`},
+		{`{"type":"record","name":"a-b","fields":[]}`, ErrInvalidSchema, "name", `"a-b"`},
+		{`{"type":"record","name":"a..b","fields":[]}`, ErrInvalidSchema, "name", `"a..b"`},
+		{`{"type":"fixed","name":"f","namespace":"x..y","size":1}`, ErrInvalidSchema, "namespace", `"x..y"`},
+		{`{"type":"fixed","name":"f","namespace":".x","size":1}`, ErrInvalidSchema, "namespace", `".x"`},
+		{`{"type":"enum","name":"e","aliases":["ok.E","not ok"],"symbols":["A"]}`, ErrInvalidSchema, "aliases[1]", `"not ok"`},
+		{`{"type":"record","name":"r","fields":[{"name":"a.b","type":"int"}]}`, ErrInvalidSchema, "fields[0].name", `"a.b"`},
+		{`{"type":"record","name":"r","fields":[{"name":"a","aliases":["old-a"],"type":"int"}]}`, ErrInvalidSchema, "fields[0].aliases[0]", `"old-a"`},
+	}
+	for i, c := range cases {
+		var anySchema AnySchema
+		err := json.Unmarshal([]byte(c.schemaBytes), &anySchema)
+		if !errors.Is(err, c.expectedErr) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) {
+			t.Errorf("case %d - expected a *SchemaError, got %T", i, err)
+			continue
+		}
+		if schemaErr.Path != c.expectedPath {
+			t.Errorf("case %d - expected path %s, got %s", i, c.expectedPath, schemaErr.Path)
+		}
+		if schemaErr.Value != c.expectedValue {
+			t.Errorf("case %d - expected value %s, got %s", i, c.expectedValue, schemaErr.Value)
+		}
+		if schemaErr.Reason == "" {
+			t.Errorf("case %d - expected a reason", i)
+		}
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	err := ValidateSchema([]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":"int","default":"zero"},{"name":"b","type":"long"},{"name":"c","type":["null","unknown",{"type":"bytes","logicalType":"decimal"}]}]}`))
+	schemaErrs, ok := err.(SchemaErrors)
+	if !ok {
+		t.Fatalf("expected SchemaErrors, got %v", err)
+	}
+	paths := make([]string, 0, len(schemaErrs))
+	for _, schemaErr := range schemaErrs {
+		paths = append(paths, schemaErr.Path)
+	}
+	expectedPaths := []string{"fields[0].default", "fields[2].type[1]", "fields[2].type[2]"}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("expected %v, got %v", expectedPaths, paths)
+	}
+	if !errors.Is(err, ErrInvalidSchema) || !errors.Is(err, ErrUnsupportedType) || errors.Is(err, ErrRedefinedType) {
+		t.Errorf("expected errors to match their sentinels, got %v", err)
+	}
+	err = ValidateSchema([]byte(`{"type":"record","name":"r","fields":[{"name":"a","type":"int","default":0}]}`))
+	if err != nil {
+		t.Errorf("expected valid schema, got %v", err)
+	}
+}
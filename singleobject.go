@@ -0,0 +1,133 @@
+package avro
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// singleObjectMarker - first two bytes of single-object encoded data, followed by
+// the little-endian CRC-64-AVRO fingerprint of the writer schema and the binary data
+var singleObjectMarker = [2]byte{0xc3, 0x01}
+
+const singleObjectHeaderSize = 10
+
+// SingleObjectFingerprint - returns the CRC-64-AVRO fingerprint of the writer schema of single-object encoded data, and the binary data
+func SingleObjectFingerprint(buf []byte) (fingerprint uint64, payload []byte, err error) {
+	if len(buf) < singleObjectHeaderSize || buf[0] != singleObjectMarker[0] || buf[1] != singleObjectMarker[1] {
+		return 0, nil, ErrInvalidSingleObject
+	}
+	return binary.LittleEndian.Uint64(buf[2:singleObjectHeaderSize]), buf[singleObjectHeaderSize:], nil
+}
+
+// SingleObjectEncoder - encodes data of a schema in the single-object encoding, so they identify their schema
+type SingleObjectEncoder struct {
+	schema Schema
+	header [singleObjectHeaderSize]byte
+}
+
+// NewSingleObjectEncoder - encoder of the data of the schema, its fingerprint being computed once
+func NewSingleObjectEncoder(schema Schema) (*SingleObjectEncoder, error) {
+	fingerprint, err := FingerprintRabin(schema)
+	if err != nil {
+		return nil, err
+	}
+	e := &SingleObjectEncoder{schema: schema}
+	copy(e.header[:], singleObjectMarker[:])
+	binary.LittleEndian.PutUint64(e.header[2:], fingerprint)
+	return e, nil
+}
+
+// Encode - appends the single-object encoding of the datum to buf
+func (e *SingleObjectEncoder) Encode(buf []byte, datum interface{}) ([]byte, error) {
+	return EncodeBinary(append(buf, e.header[:]...), e.schema, datum)
+}
+
+// SchemaStore - looks writer schemas up by their CRC-64-AVRO fingerprint, see FingerprintRabin
+type SchemaStore interface {
+	// LookupSchema - returns the schema with the fingerprint, ErrUnknownFingerprint if there is none
+	LookupSchema(fingerprint uint64) (Schema, error)
+}
+
+// MemorySchemaStore - SchemaStore keeping the schemas added to it in memory
+type MemorySchemaStore struct {
+	mu      sync.RWMutex
+	schemas map[uint64]Schema
+}
+
+// NewMemorySchemaStore - store of the given schemas
+func NewMemorySchemaStore(schemas ...Schema) (*MemorySchemaStore, error) {
+	store := &MemorySchemaStore{schemas: make(map[uint64]Schema, len(schemas))}
+	for _, schema := range schemas {
+		_, err := store.Add(schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// Add - adds the schema to the store and returns its fingerprint
+func (s *MemorySchemaStore) Add(schema Schema) (uint64, error) {
+	fingerprint, err := FingerprintRabin(schema)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas[fingerprint] = schema
+	return fingerprint, nil
+}
+
+// LookupSchema - returns the schema with the fingerprint, ErrUnknownFingerprint if it was not added
+func (s *MemorySchemaStore) LookupSchema(fingerprint uint64) (Schema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schema, ok := s.schemas[fingerprint]
+	if !ok {
+		return nil, ErrUnknownFingerprint
+	}
+	return schema, nil
+}
+
+// SingleObjectDecoder - decodes single-object encoded data, looking their writer schema up in a store
+type SingleObjectDecoder struct {
+	store  SchemaStore
+	reader Schema
+}
+
+// NewSingleObjectDecoder - decoder of single-object encoded data whose writer schemas are in the store.
+// If the reader schema is not nil, data are resolved to it, see ResolveDatum.
+func NewSingleObjectDecoder(store SchemaStore, reader Schema) *SingleObjectDecoder {
+	return &SingleObjectDecoder{
+		store:  store,
+		reader: reader,
+	}
+}
+
+// Decode - decodes the single-object encoded datum
+func (d *SingleObjectDecoder) Decode(buf []byte) (interface{}, error) {
+	fingerprint, payload, err := SingleObjectFingerprint(buf)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := d.store.LookupSchema(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		datum interface{}
+		rest  []byte
+	)
+	if d.reader == nil {
+		datum, rest, err = DecodeBinary(payload, writer)
+	} else {
+		datum, rest, err = DecodeBinaryResolved(payload, writer, d.reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, ErrInvalidEncoding
+	}
+	return datum, nil
+}
@@ -0,0 +1,139 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func TestSingleObject(t *testing.T) {
+	cases := []struct {
+		schema string
+		datum  interface{}
+	}{
+		{`"int"`, int32(42)},
+		{`"string"`, "gopher"},
+		{`{"type":"record","name":"User","namespace":"test","doc":"ignored by the fingerprint","fields":[{"name":"name","type":"string"},{"name":"email","type":["null","string"]}]}`,
+			map[string]interface{}{"name": "gopher", "email": map[string]interface{}{"string": "gopher@golang.org"}}},
+	}
+	for i, c := range cases {
+		schema := mustUnmarshalSchema(c.schema)
+		encoder, err := NewSingleObjectEncoder(schema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		buf, err := encoder.Encode(nil, c.datum)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		codec, err := goavro.NewCodec(c.schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := codec.SingleFromNative(nil, c.datum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, expected) {
+			t.Errorf("case %d - expected %v, got %v", i, expected, buf)
+		}
+		expectedFingerprint, _ := FingerprintRabin(schema)
+		fingerprint, payload, err := SingleObjectFingerprint(buf)
+		if err != nil || fingerprint != expectedFingerprint || !bytes.Equal(payload, buf[10:]) {
+			t.Errorf("case %d - expected %x, got %x, %v", i, expectedFingerprint, fingerprint, err)
+		}
+		store, err := NewMemorySchemaStore(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		datum, err := NewSingleObjectDecoder(store, nil).Decode(buf)
+		if err != nil || !reflect.DeepEqual(datum, c.datum) {
+			t.Errorf("case %d - expected %v, got %v, %v", i, c.datum, datum, err)
+		}
+	}
+}
+
+func TestSingleObjectResolution(t *testing.T) {
+	v1 := mustUnmarshalSchema(`{"type":"record","name":"User","fields":[{"name":"name","type":"string"}]}`)
+	v2 := mustUnmarshalSchema(`{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"age","type":"int","default":0}]}`)
+	store, err := NewMemorySchemaStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, schema := range []Schema{v1, v2} {
+		_, err = store.Add(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	decoder := NewSingleObjectDecoder(store, v2)
+	cases := []struct {
+		writer   Schema
+		datum    interface{}
+		expected interface{}
+	}{
+		{v1, map[string]interface{}{"name": "gopher"}, map[string]interface{}{"name": "gopher", "age": int32(0)}},
+		{v2, map[string]interface{}{"name": "gopher", "age": int32(10)}, map[string]interface{}{"name": "gopher", "age": int32(10)}},
+	}
+	for i, c := range cases {
+		encoder, err := NewSingleObjectEncoder(c.writer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, err := encoder.Encode([]byte("prefix"), c.datum)
+		if err != nil || !bytes.HasPrefix(buf, []byte("prefix")) {
+			t.Errorf("case %d - expected the prefix to be kept, got %v, %v", i, buf, err)
+			continue
+		}
+		datum, err := decoder.Decode(buf[len("prefix"):])
+		if err != nil || !reflect.DeepEqual(datum, c.expected) {
+			t.Errorf("case %d - expected %v, got %v, %v", i, c.expected, datum, err)
+		}
+	}
+}
+
+func TestSingleObjectErrors(t *testing.T) {
+	schema := mustUnmarshalSchema(`"string"`)
+	store, err := NewMemorySchemaStore(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, _ := FingerprintRabin(schema)
+	header := []byte{0xc3, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint64(header[2:], fingerprint)
+	unknown := []byte{0xc3, 0x01, 1, 2, 3, 4, 5, 6, 7, 8}
+	cases := []struct {
+		buf         []byte
+		reader      Schema
+		expectedErr error
+	}{
+		{nil, nil, ErrInvalidSingleObject},
+		{header[:9], nil, ErrInvalidSingleObject},
+		{append([]byte{0xc3, 0x02}, header[2:]...), nil, ErrInvalidSingleObject},
+		{append(unknown, 0), nil, ErrUnknownFingerprint},
+		{append(header, 4, 'a'), nil, io.ErrUnexpectedEOF},
+		{append(header, 0, 0), nil, ErrInvalidEncoding},
+		{append(header, 0), mustUnmarshalSchema(`"int"`), ErrIncompatibleSchema},
+	}
+	for i, c := range cases {
+		_, err := NewSingleObjectDecoder(store, c.reader).Decode(c.buf)
+		if !errors.Is(err, c.expectedErr) {
+			t.Errorf("case %d - expected %v, got %v", i, c.expectedErr, err)
+		}
+	}
+	_, err = NewSingleObjectEncoder(nil)
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedType, err)
+	}
+	_, err = NewMemorySchemaStore(nil)
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedType, err)
+	}
+}
@@ -2,7 +2,7 @@ package sqlavro
 
 import (
 	"encoding/json"
-	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +16,7 @@ func sqlColumn2AVRO(columnName string, dataType SQLType, isNullable bool, defaul
 		return nil, err
 	}
 	if len(defaultValue) > 0 {
-		defaultValue = sqlDefault2AVRODefault(dataType, defaultValue)
+		defaultValue = sqlDefault2AVRODefault(dataType, fieldType, defaultValue)
 	}
 	if isNullable {
 		if defaultValue == nil || strings.EqualFold("null", strings.ToLower(string(defaultValue))) {
@@ -27,6 +27,10 @@ func sqlColumn2AVRO(columnName string, dataType SQLType, isNullable bool, defaul
 	}
 	var rawDefault *json.RawMessage
 	if len(defaultValue) > 0 {
+		err = avro.ValidateDefault(fieldType, defaultValue)
+		if err != nil {
+			return nil, err
+		}
 		rawDefault = new(json.RawMessage)
 		*rawDefault = defaultValue
 	}
@@ -100,12 +104,13 @@ func sqlColumn2AVROType(columnName string, dataType SQLType, isNullable bool, nu
 	}
 }
 
-func sqlDefault2AVRODefault(dataType SQLType, sqlDefaultValue []byte) (avroDefault []byte) {
+func sqlDefault2AVRODefault(dataType SQLType, fieldType avro.Schema, sqlDefaultValue []byte) (avroDefault []byte) {
 	switch dataType {
 	case Char, NChar, VarChar, NVarChar,
 		Text, TinyText, MediumText, LongText,
 		Enum, Set:
-		return []byte(fmt.Sprintf(`"%s"`, string(sqlDefaultValue)))
+		avroDefault, _ = json.Marshal(string(sqlDefaultValue))
+		return avroDefault
 	case Date, Time, DateTime, Timestamp:
 		var format string
 		switch dataType {
@@ -121,9 +126,26 @@ func sqlDefault2AVRODefault(dataType SQLType, sqlDefaultValue []byte) (avroDefau
 			return nil
 		}
 		if dataType == Time {
-			t = t.AddDate(1970, 0, 0)
+			// seconds from midnight
+			return []byte(strconv.Itoa(t.Hour()*3600 + t.Minute()*60 + t.Second()))
+		}
+		// days from the epoch for dates, seconds for timestamps, as their logical type defines
+		avroDefault, err := avro.EncodeJSON(nil, fieldType, t)
+		if err != nil {
+			return nil
+		}
+		return avroDefault
+	case Decimal:
+		// decimals are bytes, represented as strings whose code points are the byte values
+		r, ok := new(big.Rat).SetString(string(sqlDefaultValue))
+		if !ok {
+			return sqlDefaultValue
+		}
+		avroDefault, err := avro.EncodeJSON(nil, fieldType, r)
+		if err != nil {
+			return sqlDefaultValue
 		}
-		return []byte(strconv.Itoa(int(t.Unix())))
+		return avroDefault
 	default:
 		return sqlDefaultValue
 	}
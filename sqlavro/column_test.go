@@ -0,0 +1,48 @@
+package sqlavro
+
+import (
+	"testing"
+
+	"github.com/khezen/avro"
+)
+
+func TestColumnDefault(t *testing.T) {
+	cases := []struct {
+		dataType        SQLType
+		isNullable      bool
+		defaultValue    string
+		expectedDefault string
+		expectedErr     error
+	}{
+		{VarChar, false, `say "hello"`, `"say \"hello\""`, nil},
+		{VarChar, true, `back\slash`, `"back\\slash"`, nil},
+		{Int, true, "42", "42", nil},
+		{Int, false, "forty-two", "", avro.ErrInvalidDatum},
+		{BigInt, false, "9223372036854775807", "9223372036854775807", nil},
+		{Date, false, "1970-01-02", "1", nil},
+		{Date, true, "2020-02-29", "18321", nil},
+		{Time, false, "01:02:03", "3723", nil},
+		{DateTime, false, "1970-01-02 00:00:01", "86401", nil},
+		{Timestamp, true, "2020-02-29 01:02:03", "1582938123", nil},
+		{Decimal, false, "1.50", "\"\\u0000\u0096\"", nil},
+		{Decimal, false, "one", "", avro.ErrInvalidDatum},
+		{Double, false, "", "", nil},
+	}
+	for i, c := range cases {
+		field, err := sqlColumn2AVRO("column", c.dataType, c.isNullable, []byte(c.defaultValue), 4, 2, 0)
+		if err != c.expectedErr {
+			t.Errorf("case %d - expected error %v, got %v", i, c.expectedErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		var defaultValue string
+		if field.Default != nil {
+			defaultValue = string(*field.Default)
+		}
+		if defaultValue != c.expectedDefault {
+			t.Errorf("case %d - expected default %s, got %s", i, c.expectedDefault, defaultValue)
+		}
+	}
+}
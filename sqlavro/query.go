@@ -37,7 +37,7 @@ type QueryConfig struct {
 	// Criteria - Optional list of criterion to retreve data from.
 	Criteria []Criterion
 	// Compression -  Optional name of the compression codec used to compress blocks
-	// "null", "deflate" and snappy are accepted.
+	// "null", "deflate", "snappy", "zstandard", "bzip2", "xz" and codecs registered with avro.RegisterCodec are accepted.
 	// If the value is empty, it is assumed to be "null"
 	Compression string
 	// Output - define the desired format for the output
@@ -61,8 +61,8 @@ func (qc *QueryConfig) Verify() error {
 	if qc.Schema == nil {
 		return ErrQueryConfigMissingSchema
 	}
-	if qc.Compression != "" && qc.Compression != avro.CompressionNull && qc.Compression != avro.CompressionDeflate && qc.Compression != avro.CompressionSnappy {
-		return avro.ErrUnsupportedCompression
+	if _, err := avro.LookupCodec(qc.Compression); err != nil {
+		return err
 	}
 	if qc.Output == "" {
 		qc.Output = outputAVRO
@@ -2,10 +2,8 @@ package sqlavro
 
 import (
 	"bytes"
-	"encoding/json"
 
 	"github.com/khezen/avro"
-	"github.com/linkedin/goavro/v2"
 )
 
 func query2AVRO(cfg QueryConfig) (avroBytes []byte, newCriteria []Criterion, err error) {
@@ -46,20 +44,21 @@ func native2avro(cfg QueryConfig, records []map[string]interface{}) (avroBytes [
 	} else {
 		newCriteria = cfg.Criteria
 	}
-	SchemaBytes, err := json.Marshal(cfg.Schema)
-	if err != nil {
-		return nil, nil, err
-	}
 	avroBuf := new(bytes.Buffer)
-	fileWriter, err := goavro.NewOCFWriter(goavro.OCFConfig{
-		W:               avroBuf,
-		Schema:          string(SchemaBytes),
-		CompressionName: cfg.Compression,
+	fileWriter, err := avro.NewOCFWriter(avroBuf, avro.OCFWriterConfig{
+		Schema:      cfg.Schema,
+		Compression: cfg.Compression,
 	})
 	if err != nil {
 		return nil, nil, err
 	}
-	err = fileWriter.Append(records)
+	for _, record := range records {
+		err = fileWriter.Append(record)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	err = fileWriter.Close()
 	if err != nil {
 		return nil, nil, err
 	}
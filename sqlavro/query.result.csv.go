@@ -94,6 +94,15 @@ func strings2CSV(cfg QueryConfig, records []map[string]string) (csvBytes []byte,
 		csvBytes = buf.Bytes()
 	case avro.CompressionSnappy:
 		csvBytes = snappy.Encode(nil, csvBytes)
+	default:
+		codec, err := avro.LookupCodec(cfg.Compression)
+		if err != nil {
+			return nil, nil, err
+		}
+		csvBytes, err = codec.Compress(csvBytes)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 	return csvBytes, newCriteria, nil
 }
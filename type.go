@@ -37,24 +37,77 @@ const (
 	TypeEnum Type = "enum"
 	// TypeFixed -
 	TypeFixed Type = "fixed"
+	// TypeError - record variant declaring the errors thrown by protocol messages
+	TypeError Type = "error"
 )
 
 // LogicalType decorates primitive and complex types to represent a derived type
 type LogicalType Type
 
 const (
-	// LogicalTypeDecimal -
+	// LogicalTypeDecimal - arbitrary-precision signed decimal number on bytes or fixed
 	LogicalTypeDecimal LogicalType = "decimal"
-	// LogicalTypeDate -
+	// LogicalTypeUUID - universally unique identifier on string
+	LogicalTypeUUID LogicalType = "uuid"
+	// LogicalTypeDate - number of days from the unix epoch on int
 	LogicalTypeDate LogicalType = "date"
-	// LogicalTypeTime -
+	// LogicalTypeTimeMillis - number of milliseconds after midnight on int
+	LogicalTypeTimeMillis LogicalType = "time-millis"
+	// LogicalTypeTimeMicros - number of microseconds after midnight on long
+	LogicalTypeTimeMicros LogicalType = "time-micros"
+	// LogicalTypeTimestampMillis - number of milliseconds from the unix epoch, UTC, on long
+	LogicalTypeTimestampMillis LogicalType = "timestamp-millis"
+	// LogicalTypeTimestampMicros - number of microseconds from the unix epoch, UTC, on long
+	LogicalTypeTimestampMicros LogicalType = "timestamp-micros"
+	// LogicalTypeLocalTimestampMillis - number of milliseconds from the unix epoch, in the local timezone, on long
+	LogicalTypeLocalTimestampMillis LogicalType = "local-timestamp-millis"
+	// LogicalTypeLocalTimestampMicros - number of microseconds from the unix epoch, in the local timezone, on long
+	LogicalTypeLocalTimestampMicros LogicalType = "local-timestamp-micros"
+	// LogicalTypeDuration - months, days and milliseconds on fixed of size 12
+	LogicalTypeDuration LogicalType = "duration"
+
+	// LogicalTypeTime - legacy, not part of the spec: time on int or long
 	LogicalTypeTime LogicalType = "time"
-	// LogicalTypeTimestamp -
+	// LogicalTypeTimestamp - legacy, not part of the spec: number of seconds from the unix epoch on int or long
 	LogicalTypeTimestamp LogicalType = "timestamp"
-	// LogialTypeDuration -
-	LogialTypeDuration LogicalType = "duration"
+	// LogialTypeDuration - Deprecated: use LogicalTypeDuration
+	LogialTypeDuration = LogicalTypeDuration
 )
 
+// logicalTypeBases maps the logical types to the types they may annotate
+var logicalTypeBases = map[LogicalType][]Type{
+	LogicalTypeDecimal: {TypeBytes, TypeFixed},
+	LogicalTypeUUID:    {TypeString},
+	// date on long and legacy time types are kept for backward compatibility
+	LogicalTypeDate:                 {TypeInt32, TypeInt64},
+	LogicalTypeTimeMillis:           {TypeInt32},
+	LogicalTypeTimeMicros:           {TypeInt64},
+	LogicalTypeTimestampMillis:      {TypeInt64},
+	LogicalTypeTimestampMicros:      {TypeInt64},
+	LogicalTypeLocalTimestampMillis: {TypeInt64},
+	LogicalTypeLocalTimestampMicros: {TypeInt64},
+	LogicalTypeDuration:             {TypeFixed},
+	LogicalTypeTime:                 {TypeInt32, TypeInt64},
+	LogicalTypeTimestamp:            {TypeInt32, TypeInt64},
+}
+
+// isKnown reports whether the logical type is defined by this package.
+// As required by the spec, unknown logical types are mere annotations on their underlying type.
+func (l LogicalType) isKnown() bool {
+	_, ok := logicalTypeBases[l]
+	return ok
+}
+
+// annotates reports whether the logical type may annotate the given type
+func (l LogicalType) annotates(typeName Type) bool {
+	for _, base := range logicalTypeBases[l] {
+		if base == typeName {
+			return true
+		}
+	}
+	return false
+}
+
 // TypeName -
 func (t Type) TypeName() Type {
 	return t
@@ -12,14 +12,68 @@ func (t UnionSchema) TypeName() Type {
 	return TypeUnion
 }
 
-func translateValues2UnionSchema(values []*fastjson.Value, additionalTypes ...Type) (Schema, error) {
+func (p *schemaParser) translateValues2UnionSchema(values []*fastjson.Value, path, namespace string) (Schema, error) {
 	union := UnionSchema(make([]Schema, 0, len(values)))
-	for _, value := range values {
-		schema, err := translateValue2AnySchema(value, additionalTypes...)
+	for i, value := range values {
+		schema, err := p.translateValue2AnySchema(value, indexPath(path, i), namespace)
 		if err != nil {
-			return nil, err
+			if err = p.report(err); err != nil {
+				return nil, err
+			}
+			continue
 		}
 		union = append(union, schema)
 	}
 	return union, nil
 }
+
+// branchName returns the name identifying a union branch:
+// the type name for unnamed types, the fullname for named types
+// and "<type>.<logicalType>" for known logical types.
+// namespace is the enclosing namespace.
+func branchName(schema Schema, namespace string) string {
+	switch t := schema.(type) {
+	case *ReferenceSchema:
+		return fullName(t.Name, "", namespace)
+	case NamedSchema:
+		return t.FullName(namespace)
+	case *DerivedPrimitiveSchema:
+		if !t.LogicalType.isKnown() {
+			return string(t.Type)
+		}
+		return string(t.Type) + "." + string(t.LogicalType)
+	default:
+		return string(schema.TypeName())
+	}
+}
+
+// matchBranchName reports whether name designates the branch,
+// named types being designated by either their full or unqualified name
+func matchBranchName(name string, schema Schema, namespace string) bool {
+	fullname := branchName(schema, namespace)
+	if name == fullname {
+		return true
+	}
+	switch schema.(type) {
+	case *ReferenceSchema, NamedSchema:
+		return name == unqualifiedName(fullname)
+	default:
+		return false
+	}
+}
+
+// lookupBranch returns the index of the branch designated by name, -1 if none
+func lookupBranch(union UnionSchema, name, namespace string) int {
+	for i, branch := range union {
+		if matchBranchName(name, branch, namespace) {
+			return i
+		}
+	}
+	// logical types may be designated by their underlying type
+	for i, branch := range union {
+		if derived, ok := branch.(*DerivedPrimitiveSchema); ok && string(derived.Type) == name {
+			return i
+		}
+	}
+	return -1
+}
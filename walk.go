@@ -0,0 +1,130 @@
+package avro
+
+// Visitor - visits the nodes of a schema tree walked by Walk.
+// path locates the node from the root, such as "fields[3].type[1].items",
+// and namespace is the enclosing namespace of the node.
+type Visitor interface {
+	// VisitSchema - called on each schema before its children.
+	// It returns the schema replacing the visited one in the tree, the visited one to leave it untouched.
+	// Returning ErrSkipChildren prevents the children of the returned schema from being walked.
+	VisitSchema(path, namespace string, schema Schema) (Schema, error)
+	// VisitField - called on each record field before its type. The field can be modified in place.
+	// Returning ErrSkipChildren prevents the type of the field from being walked.
+	VisitField(path, namespace string, field *RecordFieldSchema) error
+}
+
+// SchemaVisitorFunc - visitor of the schemas only, record fields are left untouched
+type SchemaVisitorFunc func(path, namespace string, schema Schema) (Schema, error)
+
+// VisitSchema - calls f
+func (f SchemaVisitorFunc) VisitSchema(path, namespace string, schema Schema) (Schema, error) {
+	return f(path, namespace, schema)
+}
+
+// VisitField - does nothing
+func (f SchemaVisitorFunc) VisitField(path, namespace string, field *RecordFieldSchema) error {
+	return nil
+}
+
+// Walk - walks the schema tree depth first, calling the visitor on each node before its children,
+// and returns the tree rewritten by the visitor. Nodes are rewritten in place.
+// References to named types are visited as such, without walking the referenced schema.
+// A named schema met more than once is only walked the first time, so that the walk of recursive schemas ends.
+// Once the visitor replaces a named schema by another named schema, its references and its later occurrences
+// are re-pointed to the replacement. Replaced by an unnamed schema, they are left untouched.
+func Walk(schema Schema, visitor Visitor) (Schema, error) {
+	w := walker{
+		visitor:  visitor,
+		walked:   make(map[NamedSchema]bool),
+		replaced: make(map[NamedSchema]NamedSchema),
+	}
+	schema, err := w.walk("", "", schema)
+	if err != nil {
+		return nil, err
+	}
+	for _, reference := range w.references {
+		if replacement, ok := w.replaced[reference.Schema]; ok {
+			reference.Schema = replacement
+		}
+	}
+	return schema, nil
+}
+
+type walker struct {
+	visitor Visitor
+	walked  map[NamedSchema]bool
+	// replaced - replacements of the named schemas replaced by the visitor
+	replaced map[NamedSchema]NamedSchema
+	// references - references met during the walk, re-pointed to the replacements once it is done
+	references []*ReferenceSchema
+}
+
+func (w *walker) walk(path, namespace string, schema Schema) (Schema, error) {
+	named, isNamed := schema.(NamedSchema)
+	if replacement, ok := w.replaced[named]; isNamed && ok {
+		return replacement, nil
+	}
+	schema, err := w.visitor.VisitSchema(path, namespace, schema)
+	if err != nil && err != ErrSkipChildren {
+		return nil, err
+	}
+	if replacement, ok := schema.(NamedSchema); isNamed && ok && replacement != named {
+		w.replaced[named] = replacement
+	}
+	if reference, ok := schema.(*ReferenceSchema); ok {
+		w.references = append(w.references, reference)
+	}
+	if err == ErrSkipChildren {
+		return schema, nil
+	}
+	switch t := schema.(type) {
+	case UnionSchema:
+		for i, branch := range t {
+			t[i], err = w.walk(indexPath(path, i), namespace, branch)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case *ArraySchema:
+		t.Items, err = w.walk(joinPath(path, "items"), namespace, t.Items)
+		if err != nil {
+			return nil, err
+		}
+	case *MapSchema:
+		t.Value, err = w.walk(joinPath(path, "values"), namespace, t.Value)
+		if err != nil {
+			return nil, err
+		}
+	case *RecordSchema:
+		if w.walked[t] {
+			return t, nil
+		}
+		w.walked[t] = true
+		err = w.walkFields(path, namespaceOf(t.FullName(namespace)), t)
+		if err != nil {
+			return nil, err
+		}
+	case NamedSchema:
+		w.walked[t] = true
+	}
+	return schema, nil
+}
+
+func (w *walker) walkFields(path, namespace string, schema *RecordSchema) error {
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		fieldPath := indexPath(joinPath(path, "fields"), i)
+		err := w.visitor.VisitField(fieldPath, namespace, field)
+		if err == ErrSkipChildren {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		field.Type, err = w.walk(joinPath(fieldPath, "type"), namespace, field.Type)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const walkTestSchema = `{
+	"type": "record",
+	"name": "node",
+	"namespace": "tree",
+	"fields": [
+		{"name": "id", "type": "int"},
+		{"name": "children", "type": {"type": "array", "items": "node"}},
+		{"name": "labels", "type": ["null", {"type": "map", "values": {"type": "enum", "name": "color", "symbols": ["RED"]}}]},
+		{"name": "checksum", "type": {"type": "fixed", "name": "md5", "namespace": "hash", "size": 16}}
+	]
+}`
+
+type pathVisitor struct {
+	paths []string
+}
+
+func (v *pathVisitor) VisitSchema(path, namespace string, schema Schema) (Schema, error) {
+	v.paths = append(v.paths, path+" "+namespace+" "+string(schema.TypeName()))
+	return schema, nil
+}
+
+func (v *pathVisitor) VisitField(path, namespace string, field *RecordFieldSchema) error {
+	v.paths = append(v.paths, path+" "+namespace+" "+field.Name)
+	return nil
+}
+
+func TestWalk(t *testing.T) {
+	visitor := &pathVisitor{}
+	schema := mustParseSchema(t, walkTestSchema)
+	walked, err := Walk(schema, visitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if walked != schema {
+		t.Errorf("expected the schema to be left untouched")
+	}
+	expected := []string{
+		"  record",
+		"fields[0] tree id",
+		"fields[0].type tree int",
+		"fields[1] tree children",
+		"fields[1].type tree array",
+		"fields[1].type.items tree record",
+		"fields[2] tree labels",
+		"fields[2].type tree union",
+		"fields[2].type[0] tree null",
+		"fields[2].type[1] tree map",
+		"fields[2].type[1].values tree enum",
+		"fields[3] tree checksum",
+		"fields[3].type tree fixed",
+	}
+	if !reflect.DeepEqual(visitor.paths, expected) {
+		t.Errorf("expected %v, got %v", expected, visitor.paths)
+	}
+}
+
+func TestWalkRecursive(t *testing.T) {
+	node := &RecordSchema{Type: TypeRecord, Name: "node"}
+	node.Fields = []RecordFieldSchema{
+		{Name: "next", Type: UnionSchema{TypeNull, node}},
+	}
+	visitor := &pathVisitor{}
+	_, err := Walk(node, visitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"  record",
+		"fields[0]  next",
+		"fields[0].type  union",
+		"fields[0].type[0]  null",
+		"fields[0].type[1]  record",
+	}
+	if !reflect.DeepEqual(visitor.paths, expected) {
+		t.Errorf("expected %v, got %v", expected, visitor.paths)
+	}
+}
+
+func TestWalkRewrite(t *testing.T) {
+	cases := []struct {
+		schema   string
+		visitor  SchemaVisitorFunc
+		expected string
+	}{
+		{
+			`{"type":"record","name":"r","fields":[{"name":"a","type":"int"},{"name":"b","type":{"type":"array","items":["null","int"]}}]}`,
+			func(path, namespace string, schema Schema) (Schema, error) {
+				if schema == TypeInt32 {
+					return TypeInt64, nil
+				}
+				return schema, nil
+			},
+			`{"type":"record","name":"r","fields":[{"name":"a","type":"long"},{"name":"b","type":{"type":"array","items":["null","long"]}}]}`,
+		},
+		{
+			`{"type":"map","values":{"type":"array","items":"int"}}`,
+			func(path, namespace string, schema Schema) (Schema, error) {
+				if array, ok := schema.(*ArraySchema); ok {
+					return UnionSchema{TypeNull, array.Items}, nil
+				}
+				return schema, nil
+			},
+			`{"type":"map","values":["null","int"]}`,
+		},
+		{
+			`{"type":"array","items":{"type":"array","items":"int"}}`,
+			func(path, namespace string, schema Schema) (Schema, error) {
+				if path == "items" {
+					return schema, ErrSkipChildren
+				}
+				if schema == TypeInt32 {
+					return TypeString, nil
+				}
+				return schema, nil
+			},
+			`{"type":"array","items":{"type":"array","items":"int"}}`,
+		},
+	}
+	for i, c := range cases {
+		schema, err := Walk(mustParseSchema(t, c.schema), c.visitor)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		schemaBytes, err := json.Marshal(schema)
+		if err != nil {
+			t.Errorf("case %d - %v", i, err)
+			continue
+		}
+		if string(schemaBytes) != c.expected {
+			t.Errorf("case %d - expected %s, got %s", i, c.expected, schemaBytes)
+		}
+	}
+}
+
+func TestWalkReplaceNamed(t *testing.T) {
+	schema := mustParseSchema(t, `{"type":"record","name":"r","fields":[
+		{"name":"a","type":{"type":"fixed","name":"f","size":2}},
+		{"name":"b","type":"f"},
+		{"name":"c","type":{"type":"array","items":"r"}}]}`)
+	replacements := make(map[string]NamedSchema)
+	visitor := SchemaVisitorFunc(func(path, namespace string, schema Schema) (Schema, error) {
+		switch t := schema.(type) {
+		case *FixedSchema:
+			replacements[t.Name] = &FixedSchema{Type: TypeFixed, Name: t.Name, Size: 4}
+			return replacements[t.Name], nil
+		case *RecordSchema:
+			replacements[t.Name] = &RecordSchema{Type: TypeRecord, Name: t.Name, Fields: t.Fields}
+			return replacements[t.Name], nil
+		}
+		return schema, nil
+	})
+	walked, err := Walk(schema, visitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := walked.(*RecordSchema)
+	if record != replacements["r"] {
+		t.Errorf("expected the record to be replaced")
+	}
+	if record.Fields[0].Type != replacements["f"] {
+		t.Errorf("expected the fixed to be replaced")
+	}
+	if reference := record.Fields[1].Type.(*ReferenceSchema); reference.Schema != replacements["f"] {
+		t.Errorf("expected the reference to the fixed to be re-pointed to its replacement")
+	}
+	if reference := record.Fields[2].Type.(*ArraySchema).Items.(*ReferenceSchema); reference.Schema != replacements["r"] {
+		t.Errorf("expected the reference to the record to be re-pointed to its replacement")
+	}
+	schemaBytes, err := json.Marshal(walked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"fixed","name":"f","size":4}},{"name":"b","type":"f"},{"name":"c","type":{"type":"array","items":"r"}}]}`
+	if string(schemaBytes) != expected {
+		t.Errorf("expected %s, got %s", expected, schemaBytes)
+	}
+}
+
+type fieldSkipper struct{}
+
+func (fieldSkipper) VisitSchema(path, namespace string, schema Schema) (Schema, error) {
+	if schema == TypeInt32 {
+		return nil, errors.New("int must be skipped")
+	}
+	return schema, nil
+}
+
+func (fieldSkipper) VisitField(path, namespace string, field *RecordFieldSchema) error {
+	field.Documentation = path
+	return ErrSkipChildren
+}
+
+func TestWalkErrors(t *testing.T) {
+	schema := mustParseSchema(t, walkTestSchema)
+	_, err := Walk(schema, fieldSkipper{})
+	if err != nil {
+		t.Errorf("expected fields to be skipped, got %v", err)
+	}
+	if doc := schema.(*RecordSchema).Fields[3].Documentation; doc != "fields[3]" {
+		t.Errorf("expected field to be modified in place, got %s", doc)
+	}
+	_, err = Walk(mustParseSchema(t, `["null",{"type":"map","values":"int"}]`), fieldSkipper{})
+	if err == nil || err.Error() != "int must be skipped" {
+		t.Errorf("expected visitor error, got %v", err)
+	}
+}